@@ -1,6 +1,8 @@
 package compilerErrors
 
-const (
-	ErrBinaryInvalidBinaryExpr = "ERROR: Invalid binary operation"
-	ErrBinaryDivisionByZero    = "ERROR: Division by zero"
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrBinaryInvalidBinaryExpr = diagnostic.New("P0501", diagnostic.Type, "Invalid binary operation")
+	ErrBinaryDivisionByZero    = diagnostic.New("P0502", diagnostic.Runtime, "Division by zero")
 )
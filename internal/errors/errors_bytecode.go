@@ -0,0 +1,11 @@
+package compilerErrors
+
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrBytecodeTooManyConstants = diagnostic.New("P0701", diagnostic.Syntax, "Too many constants in one function")
+	ErrBytecodeTooManyLocals    = diagnostic.New("P0702", diagnostic.Syntax, "Too many local variables in one function")
+	ErrBytecodeJumpTooLarge     = diagnostic.New("P0703", diagnostic.Syntax, "Too much code to jump over")
+	ErrBytecodeTooManyArguments = diagnostic.New("P0704", diagnostic.Syntax, "Too many arguments in a call")
+	ErrBytecodeUnsupportedNode  = diagnostic.New("P0705", diagnostic.Syntax, "The bytecode compiler doesn't support %s")
+)
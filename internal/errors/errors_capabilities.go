@@ -0,0 +1,10 @@
+package compilerErrors
+
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrPermissionDenied  = diagnostic.New("P1001", diagnostic.Runtime, "Permission denied: %s needs the %s capability")
+	ErrPathNotAllowed    = diagnostic.New("P1002", diagnostic.Runtime, "Permission denied: %s can't use %s, it is outside of the allowed directories")
+	ErrUnknownCapability = diagnostic.New("P1003", diagnostic.Runtime, "Unknown capability: %s")
+	ErrNoFSDirectories   = diagnostic.New("P1004", diagnostic.Runtime, "The fs capability needs at least one allowed directory, allow fs to use every directory")
+)
@@ -0,0 +1,9 @@
+package compilerErrors
+
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrUncaughtException            = diagnostic.New("P1101", diagnostic.Runtime, "Uncaught exception: %s")
+	ErrSyntaxExpectedCatchOrFinally = diagnostic.New("P1102", diagnostic.Syntax, "Expected 'catch' or 'finally' after the try block")
+	ErrSyntaxExpectedCatchParameter = diagnostic.New("P1103", diagnostic.Syntax, "Expected an identifier for the caught error")
+)
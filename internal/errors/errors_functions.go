@@ -1,9 +1,12 @@
 package compilerErrors
 
-const (
-	ErrFuncExpectedIdentifer        = "ERROR: Expected identifier for function name"
-	ErrReturn                       = "ERROR: Return statement outside of function"
-	ErrNotEnoughArguments           = "ERROR: Not enough arguments for function: "
-	ErrTooManyArguments             = "ERROR: Too many arguments for function: "
-	ErrComputedPropertyMustBeString = "ERROR: Computed property must be a string"
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrFuncExpectedIdentifer        = diagnostic.New("P0301", diagnostic.Syntax, "Expected identifier for function name")
+	ErrReturn                       = diagnostic.New("P0302", diagnostic.Syntax, "Return statement outside of function")
+	ErrNotEnoughArguments           = diagnostic.New("P0303", diagnostic.Runtime, "Not enough arguments for function: %s")
+	ErrTooManyArguments             = diagnostic.New("P0304", diagnostic.Runtime, "Too many arguments for function: %s")
+	ErrComputedPropertyMustBeString = diagnostic.New("P0305", diagnostic.Type, "Computed property must be a string")
+	ErrNotAFunction                 = diagnostic.New("P0306", diagnostic.Type, "Value is not a function: %s")
 )
@@ -0,0 +1,11 @@
+package compilerErrors
+
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrCanceled          = diagnostic.New("P0901", diagnostic.Runtime, "The program was stopped by its context: %s")
+	ErrTimeout           = diagnostic.New("P0902", diagnostic.Runtime, "The program ran for longer than %s")
+	ErrStepLimitExceeded = diagnostic.New("P0903", diagnostic.Runtime, "The program ran for more than %d steps")
+	ErrStackOverflow     = diagnostic.New("P0904", diagnostic.Runtime, "Stack overflow: more than %d nested calls")
+	ErrMemoryLimit       = diagnostic.New("P0905", diagnostic.Runtime, "Memory limit exceeded: the program created more than %d bytes of values")
+)
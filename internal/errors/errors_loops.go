@@ -1,6 +1,10 @@
 package compilerErrors
 
-const (
-	ErrLoopsBreakNotInLoop    = "ERROR: Break statement not in loop"
-	ErrLoopsContinueNotInLoop = "ERROR: Continue statement not in loop"
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrLoopsBreakNotInLoop    = diagnostic.New("P0601", diagnostic.Syntax, "Break statement not in loop")
+	ErrLoopsContinueNotInLoop = diagnostic.New("P0602", diagnostic.Syntax, "Continue statement not in loop")
+	ErrLoopsUndefinedLabel    = diagnostic.New("P0603", diagnostic.Syntax, "Undefined loop label: %s")
+	ErrLoopsDuplicatedLabel   = diagnostic.New("P0604", diagnostic.Syntax, "Loop label already in use: %s")
 )
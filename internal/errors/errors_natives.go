@@ -0,0 +1,13 @@
+package compilerErrors
+
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrNativeInvalidName       = diagnostic.New("P0801", diagnostic.Runtime, "Invalid native function name: %s")
+	ErrNativeAlreadyRegistered = diagnostic.New("P0802", diagnostic.Runtime, "Native function already exists: %s")
+	ErrNativeArgumentType      = diagnostic.New("P0803", diagnostic.Type, "Argument %d of %s must be %s, got %s")
+	ErrNativeFunctionFailed    = diagnostic.New("P0804", diagnostic.Runtime, "%s failed: %s")
+	ErrConvertToValue          = diagnostic.New("P0805", diagnostic.Type, "Cannot convert the Go type %s to a Pika value%s")
+	ErrConvertFromValue        = diagnostic.New("P0806", diagnostic.Type, "Cannot convert %s to the Go type %s%s")
+	ErrComparatorResult        = diagnostic.New("P0807", diagnostic.Type, "The comparator of sort must return a number, got %s")
+)
@@ -1,7 +1,9 @@
 package compilerErrors
 
-const (
-	ErrPropertyNotFound = "ERROR: Property not found"
-	ErrIndexNotFound    = "ERROR: Index not found"
-	ErrInvalidIndex     = "ERROR: Invalid index"
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrPropertyNotFound = diagnostic.New("P0401", diagnostic.Runtime, "Property not found")
+	ErrIndexNotFound    = diagnostic.New("P0402", diagnostic.Runtime, "Index not found")
+	ErrInvalidIndex     = diagnostic.New("P0403", diagnostic.Type, "Invalid index")
 )
@@ -1,24 +1,34 @@
 package compilerErrors
 
-const (
-	ErrSyntaxExpectedLeftParen            = "ERROR: Expected '('"
-	ErrSyntaxExpectedRightParen           = "ERROR: Expected ')'"
-	ErrSyntaxExpectedRightBracket         = "ERROR: Expected ']'"
-	ErrSyntaxExpectedLeftBrace            = "ERROR: Expected '{'"
-	ErrSyntaxExpectedRightBrace           = "ERROR: Expected '}'"
-	ErrSyntaxExpectedColon                = "ERROR: Expected ':'"
-	ErrSyntaxExpectedComma                = "ERROR: Expected ','"
-	ErrSyntaxExpectedSemicolon            = "ERROR: Expected ';'"
-	ErrSyntaxExpectedDoubleQuote          = "ERROR: Expected '\"'"
-	ErrSyntaxExpectedIdentifier           = "ERROR: Expected identifier"
-	ErrSyntaxInvalidAssignment            = "ERROR: Invalid assignment"
-	ErrSyntaxExpectedKey                  = "ERROR: Expected a key"
-	ErrSyntaxExpectedAssignation          = "ERROR: Expected '='"
-	ErrSyntaxUnterminatedMultilineComment = "ERROR: Unterminated multiline comment"
-	ErrConditionCannotBeEmpty             = "ERROR: Condition cannot be empty"
-	ErrSyntaxCaseCannotBeEmpty            = "ERROR: Case cannot be empty"
-	ErrSyntaxUnaryInvalidUnaryExpr        = "ERROR: Invalid unary expression"
-	ErrSyntaxInvalidUpdateExpr            = "ERROR: Invalid update expression"
-	ErrSyntaxConditionCantBeEmpty         = "ERROR: Condition cannot be empty"
-	ErrParsingError                       = "ERROR: Parsing error"
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrSyntaxExpectedLeftParen            = diagnostic.New("P0101", diagnostic.Syntax, "Expected '('")
+	ErrSyntaxExpectedRightParen           = diagnostic.New("P0102", diagnostic.Syntax, "Expected ')'")
+	ErrSyntaxExpectedRightBracket         = diagnostic.New("P0103", diagnostic.Syntax, "Expected ']'")
+	ErrSyntaxExpectedLeftBrace            = diagnostic.New("P0104", diagnostic.Syntax, "Expected '{'")
+	ErrSyntaxExpectedRightBrace           = diagnostic.New("P0105", diagnostic.Syntax, "Expected '}'")
+	ErrSyntaxExpectedColon                = diagnostic.New("P0106", diagnostic.Syntax, "Expected ':'")
+	ErrSyntaxExpectedComma                = diagnostic.New("P0107", diagnostic.Syntax, "Expected ','")
+	ErrSyntaxExpectedSemicolon            = diagnostic.New("P0108", diagnostic.Syntax, "Expected ';'")
+	ErrSyntaxExpectedIdentifier           = diagnostic.New("P0110", diagnostic.Syntax, "Expected identifier")
+	ErrSyntaxInvalidAssignment            = diagnostic.New("P0111", diagnostic.Syntax, "Invalid assignment")
+	ErrSyntaxExpectedKey                  = diagnostic.New("P0112", diagnostic.Syntax, "Expected a key")
+	ErrSyntaxExpectedAssignation          = diagnostic.New("P0113", diagnostic.Syntax, "Expected '='")
+	ErrSyntaxUnterminatedMultilineComment = diagnostic.New("P0114", diagnostic.Syntax, "Unterminated multiline comment")
+	ErrConditionCannotBeEmpty             = diagnostic.New("P0115", diagnostic.Syntax, "Condition cannot be empty")
+	ErrSyntaxCaseCannotBeEmpty            = diagnostic.New("P0116", diagnostic.Syntax, "Case cannot be empty")
+	ErrSyntaxUnaryInvalidUnaryExpr        = diagnostic.New("P0117", diagnostic.Type, "Invalid unary expression")
+	ErrSyntaxInvalidUpdateExpr            = diagnostic.New("P0118", diagnostic.Type, "Invalid update expression")
+	ErrSyntaxConditionCantBeEmpty         = ErrConditionCannotBeEmpty
+	ErrParsingError                       = diagnostic.New("P0119", diagnostic.Syntax, "Parsing error")
+	ErrUnknownNodeType                    = diagnostic.New("P0120", diagnostic.Runtime, "Unknown node type")
+	ErrSyntaxUnexpectedToken              = diagnostic.New("P0121", diagnostic.Syntax, "Unexpected '%s'")
+	ErrSyntaxUnexpectedEOF                = diagnostic.New("P0122", diagnostic.Syntax, "Unexpected end of file")
+	ErrSyntaxUnterminatedString           = diagnostic.New("P0123", diagnostic.Syntax, "Unterminated string")
+	ErrSyntaxInvalidEscape                = diagnostic.New("P0124", diagnostic.Syntax, "Invalid escape sequence: %s")
+	ErrSyntaxUnterminatedTemplate         = diagnostic.New("P0125", diagnostic.Syntax, "Unterminated template literal")
+	ErrSyntaxEmptyTemplateExpression      = diagnostic.New("P0126", diagnostic.Syntax, "Expected an expression inside '${}'")
+	ErrSyntaxInvalidNumber                = diagnostic.New("P0127", diagnostic.Syntax, "Invalid numeric literal: %s")
+	ErrSyntaxIncompleteTemplateExpression = diagnostic.New("P0128", diagnostic.Syntax, "Expected an expression before the '}' of '${}'")
 )
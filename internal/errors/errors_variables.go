@@ -1,8 +1,11 @@
 package compilerErrors
 
-const (
-	ErrVariableDoesNotExist                              = "ERROR: Variable does not exist in this scope: "
-	ErrVariableAlreadyExists                             = "ERROR: Variable already exists: "
-	ErrVariableIsConstant                                = "ERROR: Constant cant be re-assigned: "
-	ErrVariableExpectedIdentifierNameFollowingConstOrVar = "ERROR: Expected identifier name following 'const' or 'var'"
+import "github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+var (
+	ErrVariableDoesNotExist                              = diagnostic.New("P0201", diagnostic.Runtime, "Variable does not exist in this scope: %s")
+	ErrVariableAlreadyExists                             = diagnostic.New("P0202", diagnostic.Runtime, "Variable already exists: %s")
+	ErrVariableIsConstant                                = diagnostic.New("P0203", diagnostic.Runtime, "Constant cant be re-assigned: %s")
+	ErrVariableExpectedIdentifierNameFollowingConstOrVar = diagnostic.New("P0204", diagnostic.Syntax, "Expected identifier name following 'const' or 'var'")
+	ErrVariableConstantMustBeInitialized                 = diagnostic.New("P0205", diagnostic.Syntax, "Constant must be initialized: %s")
 )
@@ -0,0 +1,98 @@
+package nativeFns
+
+import (
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+var ArrayFns = map[string]NativeFunction{
+	"includes": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("includes", args, interpreter_env.Array); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkBoolean(false), nil
+		}
+
+		return interpreter_makers.MkBoolean(indexOf(args[0].(*interpreter_env.ArrayVal), args[1]) != -1), nil
+	},
+	// Adds the elements at the end of the array and returns the same array
+	"push": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("push", args, interpreter_env.Array); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		arr := args[0].(*interpreter_env.ArrayVal)
+		arr.Elements = append(arr.Elements, args[1:]...)
+
+		return arr, nil
+	},
+	// Removes the last element of the array and returns it
+	"pop": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("pop", args, interpreter_env.Array); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		arr := args[0].(*interpreter_env.ArrayVal)
+
+		if len(arr.Elements) == 0 {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		last := arr.Elements[len(arr.Elements)-1]
+		arr.Elements = arr.Elements[:len(arr.Elements)-1]
+
+		return last, nil
+	},
+	// Removes the first element of the array and returns it
+	"shift": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("shift", args, interpreter_env.Array); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		arr := args[0].(*interpreter_env.ArrayVal)
+
+		if len(arr.Elements) == 0 {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		first := arr.Elements[0]
+		arr.Elements = arr.Elements[1:]
+
+		return first, nil
+	},
+	"indexOf": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("indexOf", args, interpreter_env.Array); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		return interpreter_makers.MkNumber(float64(indexOf(args[0].(*interpreter_env.ArrayVal), args[1]))), nil
+	},
+}
+
+func indexOf(arr *interpreter_env.ArrayVal, searchElement interpreter_env.RuntimeValue) int {
+	for index, element := range arr.Elements {
+		if interpreter_env.Equals(element, searchElement) {
+			return index
+		}
+	}
+
+	return -1
+}
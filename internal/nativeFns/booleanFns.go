@@ -6,18 +6,18 @@ import (
 )
 
 var BooleanFns = map[string]NativeFunction{
-	"isNaN": func(args []interpreter_env.RuntimeValue, env interpreter_env.Environment) interpreter_env.RuntimeValue {
+	"isNaN": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
 		if len(args) < 1 {
-			return interpreter_makers.MkBoolean(true)
+			return interpreter_makers.MkBoolean(true), nil
 		}
 
-		return interpreter_makers.MkBoolean(args[0].GetValue() == "NaN" && args[0].GetType() == interpreter_env.Number)
+		return interpreter_makers.MkBoolean(args[0].GetValue() == "NaN" && args[0].GetType() == interpreter_env.Number), nil
 	},
-	"isNull": func(args []interpreter_env.RuntimeValue, env interpreter_env.Environment) interpreter_env.RuntimeValue {
+	"isNull": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
 		if len(args) < 1 {
-			return interpreter_makers.MkBoolean(false)
+			return interpreter_makers.MkBoolean(false), nil
 		}
 
-		return interpreter_makers.MkBoolean(args[0].GetValue() == nil)
+		return interpreter_makers.MkBoolean(args[0].GetValue() == nil), nil
 	},
 }
@@ -0,0 +1,145 @@
+package nativeFns
+
+import (
+	"sort"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+// Natives that call a function of the program for the elements of an array
+var CallbackFns = map[string]NativeFunction{
+	// Returns a new array with the results of calling the function with every element
+	"map": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("map", args, interpreter_env.Array, interpreter_env.Function); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		arr := args[0].(*interpreter_env.ArrayVal)
+		elements := make([]interpreter_env.RuntimeValue, 0, len(arr.Elements))
+
+		for idx := 0; idx < len(arr.Elements); idx++ {
+			result, err := rt.Call(args[1], []interpreter_env.RuntimeValue{arr.Elements[idx]})
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, result)
+		}
+
+		return interpreter_makers.MkArray(elements), nil
+	},
+	// Returns a new array with the elements for which the function returns a truthy value
+	"filter": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("filter", args, interpreter_env.Array, interpreter_env.Function); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		arr := args[0].(*interpreter_env.ArrayVal)
+		elements := []interpreter_env.RuntimeValue{}
+
+		for idx := 0; idx < len(arr.Elements); idx++ {
+			element := arr.Elements[idx]
+			result, err := rt.Call(args[1], []interpreter_env.RuntimeValue{element})
+			if err != nil {
+				return nil, err
+			}
+
+			if EvaluateTruthyFalsyValues(result) {
+				elements = append(elements, element)
+			}
+		}
+
+		return interpreter_makers.MkArray(elements), nil
+	},
+	/*
+	 * Sorts the array in place and returns it. The comparator gets two
+	 * elements and returns a negative number if the first one goes first,
+	 * a positive number if it goes after and 0 to keep their order. Without
+	 * comparator numbers go first in ascending order and strings after them
+	 * in alphabetical order, other values keep their order at the end.
+	 */
+	"sort": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("sort", args, interpreter_env.Array, interpreter_env.Function); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.Array {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		arr := args[0].(*interpreter_env.ArrayVal)
+
+		if len(args) < 2 {
+			sort.SliceStable(arr.Elements, func(i, j int) bool {
+				return defaultLess(arr.Elements[i], arr.Elements[j])
+			})
+			return arr, nil
+		}
+
+		// The comparator works on a copy, so it sees the array as it was while sorting
+		elements := append([]interpreter_env.RuntimeValue(nil), arr.Elements...)
+		var err error
+
+		sort.SliceStable(elements, func(i, j int) bool {
+			if err != nil {
+				return false
+			}
+
+			var result interpreter_env.RuntimeValue
+			result, err = rt.Call(args[1], []interpreter_env.RuntimeValue{elements[i], elements[j]})
+			if err != nil {
+				return false
+			}
+
+			number, ok := result.(interpreter_env.NumberVal)
+			if !ok {
+				err = compilerErrors.ErrComparatorResult.WithArgs(result.GetType())
+				return false
+			}
+
+			return number.Value < 0
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		arr.Elements = elements
+
+		return arr, nil
+	},
+}
+
+func defaultLess(a interpreter_env.RuntimeValue, b interpreter_env.RuntimeValue) bool {
+	rank := func(value interpreter_env.RuntimeValue) int {
+		switch value.(type) {
+		case interpreter_env.NumberVal:
+			return 0
+		case interpreter_env.StringVal:
+			return 1
+		}
+		return 2
+	}
+
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+
+	switch a := a.(type) {
+	case interpreter_env.NumberVal:
+		return a.Value < b.(interpreter_env.NumberVal).Value
+	case interpreter_env.StringVal:
+		return a.Value < b.(interpreter_env.StringVal).Value
+	}
+
+	return false
+}
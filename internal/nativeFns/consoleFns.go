@@ -0,0 +1,93 @@
+package nativeFns
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+var ConsoleFns = map[string]NativeFunction{
+	"print": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		return printLines(rt.Stdio.Out, args)
+	},
+	"printErr": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		return printLines(rt.Stdio.Err, args)
+	},
+	"prompt": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if len(args) < 1 {
+			return interpreter_makers.MkString(""), nil
+		}
+
+		fmt.Fprint(rt.Stdio.Out, args[0].GetValue())
+		input, err := rt.Stdio.ReadLine()
+		if err != nil {
+			return nil, nil
+		}
+		return interpreter_makers.MkString(input), nil
+	},
+}
+
+func printLines(w io.Writer, args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	for _, arg := range args {
+		printPrimitive(w, arg)
+		fmt.Fprintln(w, "")
+	}
+
+	return interpreter_makers.MkNull(), nil
+}
+
+func printPrimitive(w io.Writer, val interpreter_env.RuntimeValue) {
+	printValue(w, val, map[interpreter_env.RuntimeValue]bool{})
+}
+
+// Arrays and objects can contain themselves, the ones being printed are
+// tracked in seen so a cycle is printed as [Circular]
+func printValue(w io.Writer, val interpreter_env.RuntimeValue, seen map[interpreter_env.RuntimeValue]bool) {
+	switch val.(type) {
+	case *interpreter_env.ArrayVal, *interpreter_env.ObjectVal:
+		if seen[val] {
+			fmt.Fprint(w, "[Circular]")
+			return
+		}
+		seen[val] = true
+		defer delete(seen, val)
+	}
+
+	switch val.GetType() {
+	case interpreter_env.Array:
+		arr, _ := val.GetValue().([]interpreter_env.RuntimeValue)
+		fmt.Fprint(w, "[ ")
+		for idx, el := range arr {
+			printValue(w, el, seen)
+			if idx != len(arr)-1 {
+				fmt.Fprint(w, ", ")
+			}
+		}
+		fmt.Fprint(w, " ]")
+	case interpreter_env.Object:
+		obj, _ := val.GetValue().(map[string]interpreter_env.RuntimeValue)
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		// Sorted, so the same object is always printed the same way
+		sort.Strings(keys)
+
+		fmt.Fprint(w, "{ ")
+		for _, key := range keys {
+			fmt.Fprint(w, key+": ")
+			printValue(w, obj[key], seen)
+			fmt.Fprint(w, ", ")
+		}
+		fmt.Fprint(w, "}")
+	case interpreter_env.String:
+		fmt.Fprint(w, "\""+val.GetValue().(string)+"\"")
+	case interpreter_env.Function, interpreter_env.ArrowFunction:
+		fmt.Fprint(w, "Function")
+	default:
+		fmt.Fprint(w, val.GetValue())
+	}
+}
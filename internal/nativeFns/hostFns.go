@@ -0,0 +1,97 @@
+package nativeFns
+
+import (
+	"os"
+	"time"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+// Natives that use the host running the program, every one needs a capability
+var HostFns = map[string]NativeFunction{
+	// Returns the milliseconds since the Unix epoch
+	"now": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		return interpreter_makers.MkNumber(float64(time.Now().UnixMilli())), nil
+	},
+	// Returns the value of an environment variable, null if it isn't set
+	"getEnv": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("getEnv", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.String {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		value, ok := os.LookupEnv(args[0].GetValue().(string))
+
+		if !ok {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		return interpreter_makers.MkString(value), nil
+	},
+	"readFile": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("readFile", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.String {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		path, err := rt.Capabilities.CheckPath("readFile", args[0].GetValue().(string))
+
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, compilerErrors.ErrNativeFunctionFailed.WithArgs("readFile", err).WithCause(err)
+		}
+
+		return interpreter_makers.MkString(string(content)), nil
+	},
+	// Creates or replaces a file with the given content
+	"writeFile": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("writeFile", args, interpreter_env.String, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.String || args[1].GetType() != interpreter_env.String {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		path, err := rt.Capabilities.CheckPath("writeFile", args[0].GetValue().(string))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(path, []byte(args[1].GetValue().(string)), 0o644); err != nil {
+			return nil, compilerErrors.ErrNativeFunctionFailed.WithArgs("writeFile", err).WithCause(err)
+		}
+
+		return interpreter_makers.MkNull(), nil
+	},
+	// Stops the program with the given exit code, 0 if there is none
+	"exit": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("exit", args, interpreter_env.Number); err != nil {
+			return nil, err
+		}
+
+		code := 0
+
+		if len(args) > 0 && args[0].GetType() == interpreter_env.Number {
+			if number, ok := args[0].GetValue().(float64); ok {
+				code = int(number)
+			}
+		}
+
+		return nil, &interpreter_env.ExitError{Code: code}
+	},
+}
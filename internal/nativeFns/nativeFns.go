@@ -0,0 +1,70 @@
+package nativeFns
+
+import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/utils"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+)
+
+// Calls a function of the program, natives use it to run the callbacks they are given
+type Caller func(fn interpreter_env.RuntimeValue, args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error)
+
+// What a native function can use of the runtime running it
+type Runtime struct {
+	Call         Caller
+	Capabilities *interpreter_env.Capabilities
+	Stdio        *interpreter_env.Stdio
+}
+
+type NativeFunction func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error)
+
+var NativeFunctions = utils.MergeMaps(BooleanFns, ConsoleFns, NumberFns, ParseFns, StringFns, VarietyFns, ArrayFns, CallbackFns, HostFns)
+
+// Capability needed to call the natives that reach outside of the program
+var NativeCapabilities = map[string]interpreter_env.Capability{
+	"print":     interpreter_env.Console,
+	"prompt":    interpreter_env.Console,
+	"printErr":  interpreter_env.Console,
+	"randNum":   interpreter_env.Random,
+	"now":       interpreter_env.Time,
+	"getEnv":    interpreter_env.Env,
+	"readFile":  interpreter_env.FS,
+	"writeFile": interpreter_env.FS,
+	"exit":      interpreter_env.Process,
+}
+
+/*
+ * Returns a type error for the first argument that doesn't have the type the
+ * native needs, types has the type of every parameter in order. The missing
+ * arguments aren't checked, every native has a result for them.
+ */
+func checkArgs(name string, args []interpreter_env.RuntimeValue, types ...interpreter_env.ValueType) error {
+	for idx, arg := range args {
+		if idx >= len(types) {
+			break
+		}
+
+		if !hasType(arg, types[idx]) {
+			return compilerErrors.ErrNativeArgumentType.WithArgs(idx+1, name, types[idx], arg.GetType())
+		}
+	}
+
+	return nil
+}
+
+func hasType(value interpreter_env.RuntimeValue, valueType interpreter_env.ValueType) bool {
+	if valueType == interpreter_env.Function {
+		return value.GetType() == interpreter_env.Function || value.GetType() == interpreter_env.ArrowFunction
+	}
+
+	return value.GetType() == valueType
+}
+
+// Returns an error if the runtime doesn't allow the capability the native needs
+func CheckCapability(name string, capabilities *interpreter_env.Capabilities) error {
+	if capability, ok := NativeCapabilities[name]; ok && !capabilities.Allows(capability) {
+		return compilerErrors.ErrPermissionDenied.WithArgs(name, capability)
+	}
+
+	return nil
+}
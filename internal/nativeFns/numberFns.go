@@ -0,0 +1,62 @@
+package nativeFns
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+var NumberFns = map[string]NativeFunction{
+	"randNum": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("randNum", args, interpreter_env.Number, interpreter_env.Number); err != nil {
+			return nil, err
+		}
+
+		low, okLow := numberArg(args, 0)
+		high, okHigh := numberArg(args, 1)
+
+		if !okLow || !okHigh {
+			return interpreter_makers.MkNan(), nil
+		}
+
+		min := int(low)
+		max := int(high)
+
+		// The size of the range overflows for numbers too far apart
+		if min > max || max-min+1 <= 0 {
+			return interpreter_makers.MkNan(), nil
+		}
+		source := rand.NewSource(time.Now().UnixNano())
+		r := rand.New(source)
+		num := r.Intn(max-min+1) + min
+		return interpreter_makers.MkNumber(float64(num)), nil
+	},
+	"pow": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("pow", args, interpreter_env.Number, interpreter_env.Number); err != nil {
+			return nil, err
+		}
+
+		base, okBase := numberArg(args, 0)
+		exponent, okExponent := numberArg(args, 1)
+
+		if !okBase || !okExponent {
+			return interpreter_makers.MkNan(), nil
+		}
+
+		result := math.Pow(base, exponent)
+		return interpreter_makers.MkNumber(result), nil
+	},
+}
+
+// Returns the argument at idx if it is a number, false if it is missing or NaN
+func numberArg(args []interpreter_env.RuntimeValue, idx int) (float64, bool) {
+	if idx >= len(args) {
+		return 0, false
+	}
+
+	number, ok := args[idx].(interpreter_env.NumberVal)
+	return number.Value, ok
+}
@@ -0,0 +1,87 @@
+package nativeFns
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+func EvaluateTruthyFalsyValues(runtime interpreter_env.RuntimeValue) bool {
+	val := runtime.GetValue()
+	result := true
+	switch runtime.GetType() {
+	case interpreter_env.Boolean:
+		result = val.(bool)
+	case interpreter_env.Null:
+		result = false
+	case interpreter_env.Number:
+		result = val != 0
+	case interpreter_env.Array:
+		result = len(val.([]interpreter_env.RuntimeValue)) > 0
+	case interpreter_env.String:
+		result = len(val.(string)) > 0
+	}
+
+	return result
+}
+
+// Converts a value to the text string() gives for it
+func ToString(value interpreter_env.RuntimeValue) string {
+	switch value.GetType() {
+	case interpreter_env.Null:
+		return "null"
+	case interpreter_env.Object:
+		return "object"
+	case interpreter_env.Array:
+		arr := value.GetValue().([]interpreter_env.RuntimeValue)
+		s := "["
+		for i, v := range arr {
+			s += fmt.Sprintf("%v", v.GetValue())
+			if i != len(arr)-1 {
+				s += ", "
+			}
+		}
+		s += "]"
+		return s
+	default:
+		return fmt.Sprintf("%v", value.GetValue())
+	}
+}
+
+var ParseFns = map[string]NativeFunction{
+	"string": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if len(args) < 1 {
+			return interpreter_makers.MkString(""), nil
+		}
+
+		return interpreter_makers.MkString(ToString(args[0])), nil
+	},
+	"num": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("num", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 {
+			return interpreter_makers.MkNan(), nil
+		}
+
+		i, err := strconv.ParseFloat(args[0].(interpreter_env.StringVal).Value, 64)
+
+		if err != nil {
+			return interpreter_makers.MkNan(), nil
+		}
+
+		return interpreter_makers.MkNumber(i), nil
+	},
+	"bool": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if len(args) < 1 {
+			return interpreter_makers.MkBoolean(false), nil
+		}
+
+		result := EvaluateTruthyFalsyValues(args[0])
+
+		return interpreter_makers.MkBoolean(result), nil
+	},
+}
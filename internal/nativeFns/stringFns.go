@@ -0,0 +1,112 @@
+package nativeFns
+
+import (
+	"strings"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+var StringFns = map[string]NativeFunction{
+	"toUpperCase": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("toUpperCase", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.String {
+			return interpreter_makers.MkString(""), nil
+		}
+
+		str := args[0].GetValue().(string)
+		result := strings.ToUpper(str)
+		return interpreter_makers.MkString(result), nil
+	},
+	"toLowerCase": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("toLowerCase", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.String {
+			return interpreter_makers.MkString(""), nil
+		}
+
+		str := args[0].GetValue().(string)
+		result := strings.ToLower(str)
+		return interpreter_makers.MkString(result), nil
+	},
+	"capitalize": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("capitalize", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.String {
+			return interpreter_makers.MkString(""), nil
+		}
+
+		str := args[0].GetValue().(string)
+		if len(str) <= 0 {
+			return interpreter_makers.MkString(""), nil
+		}
+		result := strings.ToUpper(str[:1]) + str[1:]
+		return interpreter_makers.MkString(result), nil
+	},
+	"startsWith": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("startsWith", args, interpreter_env.String, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.String || args[1].GetType() != interpreter_env.String {
+			return interpreter_makers.MkBoolean(false), nil
+		}
+
+		str := args[0].GetValue().(string)
+		prefix := args[1].GetValue().(string)
+		result := strings.HasPrefix(str, prefix)
+		return interpreter_makers.MkBoolean(result), nil
+	},
+	"endsWith": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("endsWith", args, interpreter_env.String, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 2 || args[0].GetType() != interpreter_env.String || args[1].GetType() != interpreter_env.String {
+			return interpreter_makers.MkBoolean(false), nil
+		}
+
+		str := args[0].GetValue().(string)
+		suffix := args[1].GetValue().(string)
+		result := strings.HasSuffix(str, suffix)
+		return interpreter_makers.MkBoolean(result), nil
+	},
+	"reverseString": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if err := checkArgs("reverseString", args, interpreter_env.String); err != nil {
+			return nil, err
+		}
+
+		if len(args) < 1 || args[0].GetType() != interpreter_env.String {
+			return interpreter_makers.MkString(""), nil
+		}
+
+		str := args[0].GetValue().(string)
+		runes := []rune(str)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		result := string(runes)
+		return interpreter_makers.MkString(result), nil
+	},
+	"concat": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if len(args) < 1 {
+			return interpreter_makers.MkString(""), nil
+		}
+		result := ""
+		for idx, arg := range args {
+			if arg.GetType() != interpreter_env.String {
+				return nil, compilerErrors.ErrNativeArgumentType.WithArgs(idx+1, "concat", interpreter_env.String, arg.GetType())
+			}
+			result += arg.GetValue().(string)
+		}
+		return interpreter_makers.MkString(result), nil
+	},
+}
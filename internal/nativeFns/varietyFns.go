@@ -0,0 +1,40 @@
+package nativeFns
+
+import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+var VarietyFns = map[string]NativeFunction{
+	"len": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if len(args) < 1 {
+			return interpreter_makers.MkNan(), nil
+		}
+
+		switch args[0].GetType() {
+		case interpreter_env.String:
+			arg, ok := args[0].GetValue().(string)
+			if !ok {
+				return interpreter_makers.MkNan(), nil
+			}
+			return interpreter_makers.MkNumber(float64(len(arg))), nil
+		case interpreter_env.Array:
+			arg, ok := args[0].GetValue().([]interpreter_env.RuntimeValue)
+			if !ok {
+				return interpreter_makers.MkNan(), nil
+			}
+			return interpreter_makers.MkNumber(float64(len(arg))), nil
+		default:
+			return nil, compilerErrors.ErrNativeArgumentType.WithArgs(1, "len", "string or array", args[0].GetType())
+		}
+
+	},
+	"typeof": func(args []interpreter_env.RuntimeValue, rt *Runtime) (interpreter_env.RuntimeValue, error) {
+		if len(args) < 1 {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		return interpreter_makers.MkString(string(args[0].GetType())), nil
+	},
+}
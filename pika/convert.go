@@ -0,0 +1,701 @@
+package pika
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+	valueType = reflect.TypeOf((*Value)(nil)).Elem()
+)
+
+/*
+ * Converts a Go value to a Pika value:
+ *
+ *   - nil, nil pointers, nil slices and nil maps become null
+ *   - bools, strings and every numeric type become booleans, strings and numbers
+ *   - slices and arrays become arrays
+ *   - maps with string or integer keys and structs become objects, struct
+ *     fields are named by their `pika:"name"` tag or by their Go name, a
+ *     `pika:"-"` tag leaves the field out and embedded structs are flattened
+ *   - time.Time becomes a string in the RFC 3339 format
+ *   - funcs become functions that scripts can call, their arguments and
+ *     results are converted with FromValue and ToValue. A func can return
+ *     an error as its last result to stop the script.
+ *
+ * Pika values are returned as they are. A pointer, slice or map that is found
+ * twice gives the same object or array both times, so the shape of the Go
+ * data is kept, cycles included.
+ */
+func ToValue(value any) (Value, error) {
+	c := &toConverter{seen: make(map[seenKey]Value), inProgress: make(map[seenKey]bool)}
+	return c.toValue(reflect.ValueOf(value), "")
+}
+
+/*
+ * Stores a Pika value in the Go value target points to, following the rules
+ * of ToValue in reverse. Numbers can be stored in integers only if they are
+ * whole and fit. Strings in the RFC 3339 format and numbers of milliseconds
+ * since the Unix epoch can be stored in a time.Time. Null stores the zero
+ * value of the type.
+ *
+ * When the target is an empty interface values take their natural Go type:
+ * nil, bool, float64, string, []any and map[string]any, functions are kept
+ * as a Value. A func target must return an error as its last result, it is
+ * the error of the call to the Pika function.
+ */
+func FromValue(value Value, target any) error {
+	out := reflect.ValueOf(target)
+
+	if out.Kind() != reflect.Pointer || out.IsNil() {
+		return compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), reflect.TypeOf(target), ", the target must be a non-nil pointer")
+	}
+
+	c := &fromConverter{inProgress: make(map[Value]bool)}
+	return c.fromValue(value, out.Elem(), "")
+}
+
+// A slice is only the same as another one if it also has the same length
+type seenKey struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+type toConverter struct {
+	seen map[seenKey]Value
+	// Pointers to values that aren't structs being converted, they don't
+	// become an object of their own, so a cycle made only of them can't be kept
+	inProgress map[seenKey]bool
+}
+
+func (c *toConverter) toValue(v reflect.Value, path string) (Value, error) {
+	if !v.IsValid() {
+		return interpreter_makers.MkNull(), nil
+	}
+
+	if v.Type() == timeType {
+		return interpreter_makers.MkString(v.Interface().(time.Time).Format(time.RFC3339Nano)), nil
+	}
+
+	if v.Type().Implements(valueType) && v.CanInterface() {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return interpreter_makers.MkNull(), nil
+		}
+		return v.Interface().(Value), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return interpreter_makers.MkBoolean(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return interpreter_makers.MkNumber(float64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return interpreter_makers.MkNumber(float64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) {
+			return interpreter_makers.MkNan(), nil
+		}
+		return interpreter_makers.MkNumber(v.Float()), nil
+	case reflect.String:
+		return interpreter_makers.MkString(v.String()), nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return interpreter_makers.MkNull(), nil
+		}
+		return c.toValue(v.Elem(), path)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		key := seenKey{ptr: v.Pointer(), typ: v.Type()}
+
+		if v.Elem().Kind() != reflect.Struct {
+			if c.inProgress[key] {
+				return nil, compilerErrors.ErrConvertToValue.WithArgs(v.Type(), at(path)+", the pointer points to itself")
+			}
+
+			c.inProgress[key] = true
+			defer delete(c.inProgress, key)
+
+			return c.toValue(v.Elem(), path)
+		}
+
+		if seen, ok := c.seen[key]; ok {
+			return seen, nil
+		}
+
+		obj := interpreter_makers.MkObject(make(map[string]interpreter_env.RuntimeValue))
+		c.seen[key] = obj
+
+		return obj, c.fillObject(obj, v.Elem(), path)
+	case reflect.Struct:
+		obj := interpreter_makers.MkObject(make(map[string]interpreter_env.RuntimeValue))
+		return obj, c.fillObject(obj, v, path)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return interpreter_makers.MkNull(), nil
+		}
+
+		elements := make([]interpreter_env.RuntimeValue, v.Len())
+		arr := interpreter_makers.MkArray(elements)
+
+		// Arrays are copied, only a slice can contain itself
+		if v.Kind() == reflect.Slice {
+			key := seenKey{ptr: v.Pointer(), len: v.Len(), typ: v.Type()}
+			if seen, ok := c.seen[key]; ok {
+				return seen, nil
+			}
+			c.seen[key] = arr
+		}
+
+		for idx := range elements {
+			element, err := c.toValue(v.Index(idx), fmt.Sprintf("%s[%d]", path, idx))
+			if err != nil {
+				return nil, err
+			}
+			elements[idx] = element
+		}
+
+		return arr, nil
+	case reflect.Map:
+		return c.mapToValue(v, path)
+	case reflect.Func:
+		if v.IsNil() {
+			return interpreter_makers.MkNull(), nil
+		}
+		return funcToValue(v, path)
+	}
+
+	return nil, compilerErrors.ErrConvertToValue.WithArgs(v.Type(), at(path))
+}
+
+func (c *toConverter) mapToValue(v reflect.Value, path string) (Value, error) {
+	if v.IsNil() {
+		return interpreter_makers.MkNull(), nil
+	}
+
+	if !isKeyKind(v.Type().Key().Kind()) {
+		return nil, compilerErrors.ErrConvertToValue.WithArgs(v.Type(), at(path))
+	}
+
+	key := seenKey{ptr: v.Pointer(), typ: v.Type()}
+	if seen, ok := c.seen[key]; ok {
+		return seen, nil
+	}
+
+	obj := interpreter_makers.MkObject(make(map[string]interpreter_env.RuntimeValue, v.Len()))
+	c.seen[key] = obj
+
+	iter := v.MapRange()
+	for iter.Next() {
+		name := fmt.Sprint(iter.Key().Interface())
+		property, err := c.toValue(iter.Value(), join(path, name))
+
+		if err != nil {
+			return nil, err
+		}
+
+		obj.Properties[name] = property
+	}
+
+	return obj, nil
+}
+
+func (c *toConverter) fillObject(obj *interpreter_env.ObjectVal, v reflect.Value, path string) error {
+	for _, field := range fieldsOf(v.Type()) {
+		property, err := c.toValue(v.FieldByIndex(field.index), join(path, field.name))
+
+		if err != nil {
+			return err
+		}
+
+		obj.Properties[field.name] = property
+	}
+
+	return nil
+}
+
+// Returns a host function that converts its arguments, calls fn and converts its result
+func funcToValue(fn reflect.Value, path string) (Value, error) {
+	fnType := fn.Type()
+	outs := fnType.NumOut()
+
+	if outs > 2 || outs == 2 && fnType.Out(1) != errorType {
+		return nil, compilerErrors.ErrConvertToValue.WithArgs(fnType, at(path)+", funcs can only return a value and an error")
+	}
+
+	params := make([]interpreter_env.ValueType, fnType.NumIn())
+	for idx := range params {
+		params[idx] = interpreter_env.Any
+	}
+
+	name := runtime.FuncForPC(fn.Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	return &interpreter_env.HostFunction{
+		Name:     name,
+		Params:   params,
+		Variadic: fnType.IsVariadic(),
+		Handler: func(args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+			in := make([]reflect.Value, len(args))
+
+			for idx, arg := range args {
+				paramType := fnType.In(min(idx, fnType.NumIn()-1))
+				if fnType.IsVariadic() && idx >= fnType.NumIn()-1 {
+					paramType = paramType.Elem()
+				}
+
+				in[idx] = reflect.New(paramType).Elem()
+				c := &fromConverter{inProgress: make(map[Value]bool)}
+
+				if err := c.fromValue(arg, in[idx], fmt.Sprintf("argument %d of %s", idx+1, name)); err != nil {
+					return nil, err
+				}
+			}
+
+			out := fn.Call(in)
+
+			if len(out) > 0 && fnType.Out(len(out)-1) == errorType {
+				if err := out[len(out)-1]; !err.IsNil() {
+					return nil, err.Interface().(error)
+				}
+				out = out[:len(out)-1]
+			}
+
+			if len(out) == 0 {
+				return nil, nil
+			}
+
+			return ToValue(out[0].Interface())
+		},
+	}, nil
+}
+
+type fromConverter struct {
+	// Arrays and objects being converted, a value that contains itself can't be converted
+	inProgress map[Value]bool
+}
+
+func (c *fromConverter) fromValue(value Value, out reflect.Value, path string) error {
+	outType := out.Type()
+
+	if value == nil {
+		value = interpreter_makers.MkNull()
+	}
+
+	if outType == valueType {
+		out.Set(reflect.ValueOf(&value).Elem())
+		return nil
+	}
+
+	if value.GetType() == interpreter_env.Null {
+		out.Set(reflect.Zero(outType))
+		return nil
+	}
+
+	if outType == timeType {
+		return c.timeFromValue(value, out, path)
+	}
+
+	fail := compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), outType, at(path))
+
+	switch outType.Kind() {
+	case reflect.Interface:
+		return c.interfaceFromValue(value, out, path)
+	case reflect.Bool:
+		boolean, ok := value.(interpreter_env.BooleanVal)
+		if !ok {
+			return fail
+		}
+		out.SetBool(boolean.Value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, ok := value.(interpreter_env.NumberVal)
+		// The range is checked before converting, a float out of the range of int64 converts to any value
+		if !ok || !isInteger(number.Value) || number.Value < -(1<<63) || number.Value >= 1<<63 || out.OverflowInt(int64(number.Value)) {
+			return fail
+		}
+		out.SetInt(int64(number.Value))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		number, ok := value.(interpreter_env.NumberVal)
+		if !ok || !isInteger(number.Value) || number.Value < 0 || number.Value >= 1<<64 || out.OverflowUint(uint64(number.Value)) {
+			return fail
+		}
+		out.SetUint(uint64(number.Value))
+	case reflect.Float32, reflect.Float64:
+		switch number := value.(type) {
+		case interpreter_env.NumberVal:
+			out.SetFloat(number.Value)
+		case interpreter_env.NaNVal:
+			out.SetFloat(math.NaN())
+		default:
+			return fail
+		}
+	case reflect.String:
+		str, ok := value.(interpreter_env.StringVal)
+		if !ok {
+			return fail
+		}
+		out.SetString(str.Value)
+	case reflect.Pointer:
+		elem := reflect.New(outType.Elem())
+		if err := c.fromValue(value, elem.Elem(), path); err != nil {
+			return err
+		}
+		out.Set(elem)
+	case reflect.Slice, reflect.Array:
+		arr, ok := value.(*interpreter_env.ArrayVal)
+		if !ok || outType.Kind() == reflect.Array && outType.Len() != len(arr.Elements) {
+			return fail
+		}
+
+		if c.inProgress[value] {
+			return circular(value, outType, path)
+		}
+		c.inProgress[value] = true
+		defer delete(c.inProgress, value)
+
+		if outType.Kind() == reflect.Slice {
+			out.Set(reflect.MakeSlice(outType, len(arr.Elements), len(arr.Elements)))
+		}
+
+		for idx, element := range arr.Elements {
+			if err := c.fromValue(element, out.Index(idx), fmt.Sprintf("%s[%d]", path, idx)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, ok := value.(*interpreter_env.ObjectVal)
+		if !ok || !isKeyKind(outType.Key().Kind()) {
+			return fail
+		}
+		return c.mapFromValue(obj, out, path)
+	case reflect.Struct:
+		obj, ok := value.(*interpreter_env.ObjectVal)
+		if !ok {
+			return fail
+		}
+
+		if c.inProgress[value] {
+			return circular(value, outType, path)
+		}
+		c.inProgress[value] = true
+		defer delete(c.inProgress, value)
+
+		for _, field := range fieldsOf(outType) {
+			property, ok := obj.Properties[field.name]
+			if !ok {
+				continue
+			}
+
+			if err := c.fromValue(property, out.FieldByIndex(field.index), join(path, field.name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Func:
+		return funcFromValue(value, out, path)
+	default:
+		return fail
+	}
+
+	return nil
+}
+
+func (c *fromConverter) timeFromValue(value Value, out reflect.Value, path string) error {
+	switch v := value.(type) {
+	case interpreter_env.StringVal:
+		parsed, err := time.Parse(time.RFC3339Nano, v.Value)
+		if err == nil {
+			out.Set(reflect.ValueOf(parsed))
+			return nil
+		}
+	case interpreter_env.NumberVal:
+		out.Set(reflect.ValueOf(time.UnixMilli(int64(v.Value)).UTC()))
+		return nil
+	}
+
+	return compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), timeType, at(path))
+}
+
+// Values stored in an empty interface take their natural Go type
+func (c *fromConverter) interfaceFromValue(value Value, out reflect.Value, path string) error {
+	var natural reflect.Type
+
+	switch value.(type) {
+	case interpreter_env.BooleanVal:
+		natural = reflect.TypeOf(false)
+	case interpreter_env.NumberVal, interpreter_env.NaNVal:
+		natural = reflect.TypeOf(0.0)
+	case interpreter_env.StringVal:
+		natural = reflect.TypeOf("")
+	case *interpreter_env.ArrayVal:
+		natural = reflect.TypeOf([]any{})
+	case *interpreter_env.ObjectVal:
+		natural = reflect.TypeOf(map[string]any{})
+	default:
+		natural = reflect.TypeOf(value)
+	}
+
+	if !natural.AssignableTo(out.Type()) {
+		return compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), out.Type(), at(path))
+	}
+
+	if natural == reflect.TypeOf(value) {
+		out.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	converted := reflect.New(natural).Elem()
+	if err := c.fromValue(value, converted, path); err != nil {
+		return err
+	}
+
+	out.Set(converted)
+	return nil
+}
+
+func (c *fromConverter) mapFromValue(obj *interpreter_env.ObjectVal, out reflect.Value, path string) error {
+	outType := out.Type()
+
+	if c.inProgress[obj] {
+		return circular(obj, outType, path)
+	}
+	c.inProgress[obj] = true
+	defer delete(c.inProgress, obj)
+
+	converted := reflect.MakeMapWithSize(outType, len(obj.Properties))
+
+	for name, property := range obj.Properties {
+		key := reflect.New(outType.Key()).Elem()
+
+		switch outType.Key().Kind() {
+		case reflect.String:
+			key.SetString(name)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			parsed, err := strconv.ParseInt(name, 10, outType.Key().Bits())
+			if err != nil {
+				return compilerErrors.ErrConvertFromValue.WithArgs("the key "+name, outType.Key(), at(path))
+			}
+			key.SetInt(parsed)
+		default:
+			parsed, err := strconv.ParseUint(name, 10, outType.Key().Bits())
+			if err != nil {
+				return compilerErrors.ErrConvertFromValue.WithArgs("the key "+name, outType.Key(), at(path))
+			}
+			key.SetUint(parsed)
+		}
+
+		elem := reflect.New(outType.Elem()).Elem()
+		if err := c.fromValue(property, elem, join(path, name)); err != nil {
+			return err
+		}
+
+		converted.SetMapIndex(key, elem)
+	}
+
+	out.Set(converted)
+	return nil
+}
+
+// Stores in out a Go func that converts its arguments, calls the Pika function and converts its result
+func funcFromValue(value Value, out reflect.Value, path string) error {
+	fnType := out.Type()
+	outs := fnType.NumOut()
+
+	if outs == 0 || outs > 2 || fnType.Out(outs-1) != errorType {
+		return compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), fnType, at(path)+", funcs must return an error as their last result")
+	}
+
+	switch value.(type) {
+	case interpreter_env.FunctionVal, *interpreter_env.HostFunction:
+	default:
+		return compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), fnType, at(path))
+	}
+
+	out.Set(reflect.MakeFunc(fnType, func(in []reflect.Value) []reflect.Value {
+		results := make([]reflect.Value, outs)
+		for idx := range results {
+			results[idx] = reflect.Zero(fnType.Out(idx))
+		}
+
+		fail := func(err error) []reflect.Value {
+			results[outs-1] = reflect.ValueOf(&err).Elem()
+			return results
+		}
+
+		if fnType.IsVariadic() {
+			rest := in[len(in)-1]
+			in = in[:len(in)-1]
+			for idx := 0; idx < rest.Len(); idx++ {
+				in = append(in, rest.Index(idx))
+			}
+		}
+
+		args := make([]interpreter_env.RuntimeValue, len(in))
+		for idx, arg := range in {
+			converted, err := ToValue(arg.Interface())
+			if err != nil {
+				return fail(err)
+			}
+			args[idx] = converted
+		}
+
+		result, err := callFromGo(value, args)
+		if err != nil {
+			return fail(err)
+		}
+
+		if outs == 2 {
+			converted := reflect.New(fnType.Out(0)).Elem()
+			c := &fromConverter{inProgress: make(map[Value]bool)}
+
+			if err := c.fromValue(result, converted, "the result"); err != nil {
+				return fail(err)
+			}
+			results[0] = converted
+		}
+
+		return results
+	}))
+
+	return nil
+}
+
+// A function of a script called from Go is a run of the runtime that
+// declared it, with its limits, unless it is called while that runtime runs
+func callFromGo(fn Value, args []Value) (Value, error) {
+	function, ok := fn.(interpreter_env.FunctionVal)
+
+	if !ok || function.DeclarationEnv == nil {
+		return interpreter_eval.CallValue(fn, args)
+	}
+
+	realm := function.DeclarationEnv.Realm()
+
+	return realm.Run(context.Background(), realm.Limits, func() (Value, error) {
+		return interpreter_eval.CallValue(fn, args)
+	})
+}
+
+type field struct {
+	name  string
+	index []int
+}
+
+// Returns the fields of a struct that are converted, the fields of embedded
+// structs without a tag are part of the struct unless it has a field with
+// the same name
+func fieldsOf(structType reflect.Type) []field {
+	var fields []field
+	var embedded []field
+	names := make(map[string]bool)
+
+	for idx := 0; idx < structType.NumField(); idx++ {
+		structField := structType.Field(idx)
+		tag := strings.Split(structField.Tag.Get("pika"), ",")[0]
+
+		if tag == "-" {
+			continue
+		}
+
+		if structField.Anonymous && tag == "" && structField.Type.Kind() == reflect.Struct {
+			for _, inner := range fieldsOf(structField.Type) {
+				embedded = append(embedded, field{name: inner.name, index: append([]int{idx}, inner.index...)})
+			}
+			continue
+		}
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		name := structField.Name
+		if tag != "" {
+			name = tag
+		}
+
+		names[name] = true
+		fields = append(fields, field{name: name, index: []int{idx}})
+	}
+
+	for _, inner := range embedded {
+		if !names[inner.name] {
+			names[inner.name] = true
+			fields = append(fields, inner)
+		}
+	}
+
+	return fields
+}
+
+func circular(value Value, outType reflect.Type, path string) error {
+	return compilerErrors.ErrConvertFromValue.WithArgs(typeName(value), outType, at(path)+", the value contains itself")
+}
+
+func isKeyKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func typeName(value Value) string {
+	if value == nil {
+		return "nil"
+	}
+
+	if _, isNaN := value.(interpreter_env.NaNVal); isNaN {
+		return "NaN"
+	}
+
+	name := string(value.GetType())
+
+	if strings.ContainsRune("aeiou", rune(name[0])) {
+		return "an " + name
+	}
+	return "a " + name
+}
+
+// Tells if a number has no fractional part, NaN and the infinities don't
+func isInteger(number float64) bool {
+	return !math.IsInf(number, 0) && number == math.Trunc(number)
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Describes where in the value the conversion failed
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " at " + path
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,450 @@
+package pika_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pika"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+type Limits struct {
+	Retries int
+}
+
+type config struct {
+	Limits
+	Name    string            `pika:"name"`
+	Ports   []uint16          `pika:"ports"`
+	Labels  map[string]string `pika:"labels"`
+	Debug   bool
+	Ratio   float64
+	Secret  string `pika:"-"`
+	Started time.Time
+	Parent  *config
+	hidden  int
+}
+
+func TestToValue(t *testing.T) {
+	started := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	value, err := pika.ToValue(config{
+		Limits:  Limits{Retries: 3},
+		Name:    "api",
+		Ports:   []uint16{80, 443},
+		Labels:  map[string]string{"env": "prod"},
+		Debug:   true,
+		Ratio:   0.5,
+		Secret:  "hunter2",
+		Started: started,
+		hidden:  1,
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	obj, ok := value.(*interpreter_env.ObjectVal)
+
+	if !ok {
+		t.Fatalf("Expected an object, but got: %T", value)
+	}
+
+	expected := map[string]any{
+		"Retries": 3.0,
+		"name":    "api",
+		"Debug":   true,
+		"Ratio":   0.5,
+		"Started": "2024-03-01T12:00:00Z",
+		"Parent":  "null",
+	}
+
+	for name, expectedValue := range expected {
+		if property, ok := obj.Properties[name]; !ok || property.GetValue() != expectedValue {
+			t.Errorf("Expected %s to be %v, but got: %v", name, expectedValue, property)
+		}
+	}
+
+	for _, name := range []string{"Secret", "hidden", "Limits"} {
+		if _, ok := obj.Properties[name]; ok {
+			t.Errorf("Expected %s to be left out", name)
+		}
+	}
+
+	ports := obj.Properties["ports"].(*interpreter_env.ArrayVal)
+
+	if len(ports.Elements) != 2 || ports.Elements[1].GetValue() != 443.0 {
+		t.Errorf("Expected ports to be [80, 443], but got: %v", ports.Elements)
+	}
+
+	if labels := obj.Properties["labels"].(*interpreter_env.ObjectVal); labels.Properties["env"].GetValue() != "prod" {
+		t.Errorf("Expected labels.env to be prod, but got: %v", labels.Properties["env"])
+	}
+}
+
+func TestToValueBasicTypes(t *testing.T) {
+	tests := []struct {
+		input        any
+		expectedType interpreter_env.ValueType
+		expected     any
+	}{
+		{nil, interpreter_env.Null, "null"},
+		{(*config)(nil), interpreter_env.Null, "null"},
+		{true, interpreter_env.Boolean, true},
+		{int8(-3), interpreter_env.Number, -3.0},
+		{uint64(7), interpreter_env.Number, 7.0},
+		{float32(1.5), interpreter_env.Number, 1.5},
+		{"text", interpreter_env.String, "text"},
+		{interpreter_makers.MkString("value"), interpreter_env.String, "value"},
+		{map[int]bool{1: true}, interpreter_env.Object, nil},
+		{[2]string{"a", "b"}, interpreter_env.Array, nil},
+	}
+
+	for _, tt := range tests {
+		value, err := pika.ToValue(tt.input)
+
+		if err != nil {
+			t.Fatalf("Expected no error for %#v, but got: %v", tt.input, err)
+		}
+
+		if value.GetType() != tt.expectedType {
+			t.Errorf("Expected %#v to be a %s, but got: %s", tt.input, tt.expectedType, value.GetType())
+		}
+
+		if tt.expected != nil && value.GetValue() != tt.expected {
+			t.Errorf("Expected %#v to be %v, but got: %v", tt.input, tt.expected, value.GetValue())
+		}
+	}
+
+	if value, _ := pika.ToValue(math.NaN()); value.GetType() != interpreter_env.Number || value.GetValue() != "NaN" {
+		t.Errorf("Expected NaN, but got: %v", value)
+	}
+}
+
+func TestToValueErrors(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{make(chan int), "Cannot convert the Go type chan int to a Pika value"},
+		{map[string]any{"items": []any{1, complex(1, 2)}}, "Cannot convert the Go type complex128 to a Pika value at items[1]"},
+		{map[bool]int{}, "Cannot convert the Go type map[bool]int to a Pika value"},
+		{func() (int, int) { return 0, 0 }, "Cannot convert the Go type func() (int, int) to a Pika value, funcs can only return a value and an error"},
+	}
+
+	for _, tt := range tests {
+		_, err := pika.ToValue(tt.input)
+
+		if !errors.Is(err, compilerErrors.ErrConvertToValue) {
+			t.Fatalf("Expected error: %v, but got: %v", compilerErrors.ErrConvertToValue, err)
+		}
+
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected message %q, but got: %v", tt.expected, err)
+		}
+	}
+}
+
+func TestToValueKeepsSharedPointers(t *testing.T) {
+	node := &config{Name: "node"}
+	node.Parent = node
+
+	value, err := pika.ToValue(node)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	obj := value.(*interpreter_env.ObjectVal)
+
+	if obj.Properties["Parent"] != value {
+		t.Errorf("Expected the parent of the node to be the node itself")
+	}
+}
+
+func TestToValueKeepsSliceCycles(t *testing.T) {
+	items := []any{nil, 1}
+	items[0] = items
+
+	value, err := pika.ToValue(items)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	arr := value.(*interpreter_env.ArrayVal)
+
+	if arr.Elements[0] != value {
+		t.Errorf("Expected the first element to be the array itself")
+	}
+
+	// A shorter slice of the same array is a different value
+	value, err = pika.ToValue([]any{items[:1], items})
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	arr = value.(*interpreter_env.ArrayVal)
+
+	if arr.Elements[0] == arr.Elements[1] || len(arr.Elements[0].(*interpreter_env.ArrayVal).Elements) != 1 {
+		t.Errorf("Expected the slices of different lengths to be different arrays")
+	}
+
+	var loop any
+	loop = &loop
+
+	if _, err := pika.ToValue(loop); !errors.Is(err, compilerErrors.ErrConvertToValue) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrConvertToValue, err)
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	rt := pika.New()
+
+	value, err := rt.Eval(context.Background(), `{
+		Retries: 2,
+		name: "worker",
+		ports: [8080],
+		labels: { region: "eu" },
+		Debug: true,
+		Started: 1709294400000,
+		Parent: { name: "root" },
+		Secret: "ignored"
+	}`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var cfg config
+
+	if err := pika.FromValue(value, &cfg); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expected := config{
+		Limits:  Limits{Retries: 2},
+		Name:    "worker",
+		Ports:   []uint16{8080},
+		Labels:  map[string]string{"region": "eu"},
+		Debug:   true,
+		Started: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Parent:  &config{Name: "root"},
+	}
+
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected %+v, but got: %+v", expected, cfg)
+	}
+}
+
+func TestFromValueAny(t *testing.T) {
+	rt := pika.New()
+
+	value, err := rt.Eval(context.Background(), `{ items: [1, "two", true, null] }`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var result any
+
+	if err := pika.FromValue(value, &result); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expected := map[string]any{"items": []any{1.0, "two", true, nil}}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, but got: %v", expected, result)
+	}
+}
+
+func TestFromValueErrors(t *testing.T) {
+	rt := pika.New()
+
+	tests := []struct {
+		input    string
+		target   any
+		expected string
+	}{
+		{`"text"`, new(int), "Cannot convert a string to the Go type int"},
+		{`1.5`, new(int), "Cannot convert a number to the Go type int"},
+		{`300`, new(uint8), "Cannot convert a number to the Go type uint8"},
+		{`-1`, new(uint), "Cannot convert a number to the Go type uint"},
+		{`1e19`, new(int64), "Cannot convert a number to the Go type int64"},
+		{`1 / 0`, new(int64), "Cannot convert a number to the Go type int64"},
+		{`-1 / 0`, new(int64), "Cannot convert a number to the Go type int64"},
+		{`1e20`, new(uint64), "Cannot convert a number to the Go type uint64"},
+		{`1 / 0`, new(uint64), "Cannot convert a number to the Go type uint64"},
+		{`[1, 2]`, new([3]int), "Cannot convert an array to the Go type [3]int"},
+		{`{ a: 1 }`, new(string), "Cannot convert an object to the Go type string"},
+		{`{ ports: [1, "b"] }`, new(config), "Cannot convert a string to the Go type uint16 at ports[1]"},
+		{`{ a: 1 }`, new(map[int]int), "Cannot convert the key a to the Go type int"},
+		{`"yesterday"`, new(time.Time), "Cannot convert a string to the Go type time.Time"},
+		{`1`, 0, "Cannot convert a number to the Go type int, the target must be a non-nil pointer"},
+	}
+
+	for _, tt := range tests {
+		value, err := rt.Eval(context.Background(), tt.input)
+
+		if err != nil {
+			t.Fatalf("Expected no error evaluating %s, but got: %v", tt.input, err)
+		}
+
+		err = pika.FromValue(value, tt.target)
+
+		if !errors.Is(err, compilerErrors.ErrConvertFromValue) {
+			t.Fatalf("Expected error: %v, but got: %v", compilerErrors.ErrConvertFromValue, err)
+		}
+
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected message %q, but got: %v", tt.expected, err)
+		}
+	}
+}
+
+func TestFromValueCircular(t *testing.T) {
+	rt := pika.New()
+
+	value, err := rt.Eval(context.Background(), "const list = []\nlist[0] = list\nlist")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var result []any
+
+	if err := pika.FromValue(value, &result); !errors.Is(err, compilerErrors.ErrConvertFromValue) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrConvertFromValue, err)
+	}
+}
+
+func TestGoFuncsInScripts(t *testing.T) {
+	rt := pika.New()
+	failure := errors.New("no such user")
+
+	lookup, err := pika.ToValue(func(id int) (config, error) {
+		if id != 1 {
+			return config{}, failure
+		}
+		return config{Name: "ada", Ports: []uint16{22}}, nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	join, err := pika.ToValue(func(sep string, parts ...string) string {
+		result := ""
+		for idx, part := range parts {
+			if idx > 0 {
+				result += sep
+			}
+			result += part
+		}
+		return result
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := rt.Set("lookup", lookup); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := rt.Set("join", join); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := rt.Set("sep", interpreter_makers.MkString("-")); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	value, err := rt.Eval(context.Background(), "const user = lookup(1)\njoin(sep, user.name, \"x\", \"y\")")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if value.GetValue() != "ada-x-y" {
+		t.Errorf("Expected ada-x-y, but got: %v", value.GetValue())
+	}
+
+	if _, err := rt.Eval(context.Background(), "lookup(2)"); !errors.Is(err, failure) {
+		t.Errorf("Expected error: %v, but got: %v", failure, err)
+	}
+
+	if _, err := rt.Eval(context.Background(), `lookup("one")`); !errors.Is(err, compilerErrors.ErrConvertFromValue) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrConvertFromValue, err)
+	}
+}
+
+func TestPikaFunctionsInGo(t *testing.T) {
+	rt := pika.New()
+
+	value, err := rt.Eval(context.Background(), "fn add(a, b) { return a + b }\nadd")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var add func(a int, b int) (int, error)
+
+	if err := pika.FromValue(value, &add); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if result, err := add(2, 3); err != nil || result != 5 {
+		t.Errorf("Expected 5, but got: %v, %v", result, err)
+	}
+
+	var wrong func(a int) (int, error)
+
+	if err := pika.FromValue(value, &wrong); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if _, err := wrong(1); !errors.Is(err, compilerErrors.ErrNotEnoughArguments) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrNotEnoughArguments, err)
+	}
+
+	var noError func(a int, b int) int
+
+	if err := pika.FromValue(value, &noError); !errors.Is(err, compilerErrors.ErrConvertFromValue) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrConvertFromValue, err)
+	}
+}
+
+func TestPikaFunctionsInGoHaveLimits(t *testing.T) {
+	rt := pika.New()
+	rt.SetLimits(pika.Limits{MaxSteps: 1000})
+
+	value, err := rt.Eval(context.Background(), "fn spin() {\n  while true {}\n}\nspin")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	var spin func() error
+
+	if err := pika.FromValue(value, &spin); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := spin(); !errors.Is(err, pika.ErrStepLimitExceeded) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrStepLimitExceeded, err)
+	}
+
+	if _, err := rt.CallValue(value); !errors.Is(err, pika.ErrStepLimitExceeded) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrStepLimitExceeded, err)
+	}
+}
@@ -0,0 +1,331 @@
+/*
+ * Package pika embeds the Pika interpreter in Go programs. A Runtime keeps
+ * the global variables between evaluations, so a host can declare values,
+ * run scripts that use them and read or call what the scripts define:
+ *
+ *	rt := pika.New()
+ *	rt.Set("limit", interpreter_makers.MkNumber(10))
+ *	_, err := rt.Eval(ctx, "fn check(n) { return n < limit }")
+ *	ok, err := rt.Call("check", interpreter_makers.MkNumber(3))
+ */
+package pika
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
+	"github.com/Waxer59/PikaLang/pkg/parser"
+)
+
+// Value is any value a Pika program can work with
+type Value = interpreter_env.RuntimeValue
+
+// Function is a Go function that the scripts of a runtime can call, see Runtime.Register
+type Function = interpreter_env.HostFunction
+
+// Limits of every run of a runtime, see Runtime.SetLimits
+type Limits = interpreter_env.Limits
+
+// A group of native functions that reach outside of the scripts, see Runtime.Allow
+type Capability = interpreter_env.Capability
+
+const (
+	// print and prompt
+	Console = interpreter_env.Console
+	// readFile and writeFile
+	FS = interpreter_env.FS
+	// getEnv
+	Env = interpreter_env.Env
+	// now
+	Time = interpreter_env.Time
+	// randNum
+	Random = interpreter_env.Random
+	// exit
+	Process = interpreter_env.Process
+)
+
+// Error of a script stopped by exit, errors.As finds it in the error of the run
+type ExitError = interpreter_env.ExitError
+
+// Value thrown by a script and not caught, errors.As finds it in the error of the run
+type Exception = interpreter_env.Exception
+
+// A function call that was running when an error was raised
+type StackFrame = diagnostic.Frame
+
+// Returns the function calls that were running when err was raised, the
+// innermost goes first. It is empty for the errors raised outside of every function.
+func Stack(err error) []StackFrame {
+	var diag *diagnostic.Diagnostic
+	if !errors.As(err, &diag) {
+		return nil
+	}
+
+	return diag.Stack
+}
+
+// Returns the stack trace of err as it is printed for uncaught errors, one line for every running function
+func StackTrace(err error) string {
+	var diag *diagnostic.Diagnostic
+	if !errors.As(err, &diag) || !diag.HasSpan() {
+		return ""
+	}
+
+	return diag.StackTrace()
+}
+
+var (
+	// A script called a native function whose capability the runtime doesn't allow
+	ErrPermissionDenied = compilerErrors.ErrPermissionDenied
+	// A script used a path outside of the directories given to AllowFS
+	ErrPathNotAllowed = compilerErrors.ErrPathNotAllowed
+	// A script threw a value that no catch block took
+	ErrUncaughtException = compilerErrors.ErrUncaughtException
+)
+
+// Errors of the runs stopped before they end
+var (
+	// The context of the run is done, errors.Is also finds the error of the context
+	ErrCanceled = compilerErrors.ErrCanceled
+	// The run took longer than the timeout of its limits
+	ErrTimeout = compilerErrors.ErrTimeout
+	// The run evaluated more steps than its limits allow
+	ErrStepLimitExceeded = compilerErrors.ErrStepLimitExceeded
+	// The run nested more function calls than its limits allow
+	ErrStackOverflow = compilerErrors.ErrStackOverflow
+	// The run created more arrays, objects and strings than its memory limit allows
+	ErrMemoryLimitExceeded = compilerErrors.ErrMemoryLimit
+)
+
+/*
+ * A Runtime runs one script at a time, it must not be used by several
+ * goroutines at once. Different runtimes don't share anything, so they can
+ * run in parallel; Clone gives every goroutine a runtime of its own with the
+ * state of one already set up.
+ */
+type Runtime struct {
+	globals *interpreter_env.Environment
+}
+
+func New() *Runtime {
+	return &Runtime{
+		globals: interpreter_env.New(nil),
+	}
+}
+
+/*
+ * Returns a copy of the runtime with its globals, registered functions,
+ * limits, capabilities and streams. The scripts run by the copy and by r
+ * don't see the changes of each other, even to the arrays and objects they
+ * shared when the copy was made, so a runtime can be set up once and cloned
+ * for every request. It must not be called while r is running.
+ *
+ * Registered Go functions are shared, so the state they keep has to be safe
+ * for concurrent use, and so do the streams when the copies keep writing to
+ * the same ones.
+ */
+func (r *Runtime) Clone() *Runtime {
+	return &Runtime{
+		globals: r.globals.Clone(),
+	}
+}
+
+// Runs src in the global scope of the runtime and returns the value of its
+// last statement. The context is checked before the program starts.
+func (r *Runtime) Eval(ctx context.Context, src string) (Value, error) {
+	return r.eval(ctx, "", src)
+}
+
+// Runs the file at path like Eval, errors point to locations in that file
+func (r *Runtime) RunFile(ctx context.Context, path string) (Value, error) {
+	src, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.eval(ctx, path, string(src))
+}
+
+func (r *Runtime) eval(ctx context.Context, fileName string, src string) (Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p := parser.New()
+	p.SetFileName(fileName)
+	program, err := p.ProduceAST(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return interpreter_eval.Run(ctx, *program, r.globals, r.globals.Realm().Limits)
+}
+
+/*
+ * Sets the limits of the runs that start afterwards. Every call to Eval,
+ * RunFile, Call and CallValue is a run with its own step budget and timeout,
+ * calls made by host functions while a script runs are part of its run.
+ *
+ * A run that is stopped returns a different error for every reason, use
+ * errors.Is with ErrCanceled, ErrTimeout, ErrStepLimitExceeded,
+ * ErrStackOverflow and ErrMemoryLimitExceeded to tell them apart.
+ */
+func (r *Runtime) SetLimits(limits Limits) {
+	r.globals.Realm().Limits = limits
+}
+
+/*
+ * Lets the scripts of the runtime call the native functions of the given
+ * capabilities. A new runtime allows Console, Time and Random, allowing FS
+ * this way gives access to every directory.
+ */
+func (r *Runtime) Allow(capabilities ...Capability) {
+	r.globals.Realm().Capabilities.Allow(capabilities...)
+}
+
+// Stops the scripts of the runtime from calling the native functions of the given capabilities
+func (r *Runtime) Deny(capabilities ...Capability) {
+	r.globals.Realm().Capabilities.Deny(capabilities...)
+}
+
+// Stops the scripts of the runtime from calling any native function that
+// reaches outside of them, so they can only compute
+func (r *Runtime) DenyAll() {
+	r.globals.Realm().Capabilities = interpreter_env.NoCapabilities()
+}
+
+// Lets the fs functions of the scripts use the given directories and
+// everything inside of them. It fails without directories, use Allow(FS) to
+// give access to every directory.
+func (r *Runtime) AllowFS(dirs ...string) error {
+	return r.globals.Realm().Capabilities.AllowFS(dirs...)
+}
+
+// Sets the reader prompt reads the lines of the scripts from, os.Stdin by default
+func (r *Runtime) SetStdin(stdin io.Reader) {
+	r.globals.Realm().Stdio.In = stdin
+}
+
+// Sets the writer print and prompt write to, os.Stdout by default
+func (r *Runtime) SetStdout(stdout io.Writer) {
+	r.globals.Realm().Stdio.Out = stdout
+}
+
+// Sets the writer printErr writes to, os.Stderr by default
+func (r *Runtime) SetStderr(stderr io.Writer) {
+	r.globals.Realm().Stdio.Err = stderr
+}
+
+// Runs fn as a run of the runtime that can't be canceled
+func (r *Runtime) run(fn func() (Value, error)) (Value, error) {
+	realm := r.globals.Realm()
+	return realm.Run(context.Background(), realm.Limits, fn)
+}
+
+// Changes the global variable with the given name, declaring it if the
+// runtime doesn't have it yet. Constants can't be changed.
+func (r *Runtime) Set(name string, value Value) error {
+	if _, err := r.globals.LookupVar(name); err != nil {
+		r.globals.DeclareVar(name, value, false)
+		return nil
+	}
+
+	_, err := r.globals.AssignVar(name, value)
+	return err
+}
+
+// Returns the global variable with the given name, the second return value
+// is false if the runtime doesn't have it
+func (r *Runtime) Get(name string) (Value, bool) {
+	value, err := r.globals.LookupVar(name)
+
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+/*
+ * Makes fn callable from the scripts of the runtime. Its name can't be the
+ * name of a native function of the language or of a function already
+ * registered. Functions with a namespace, like "db.query", are called as
+ * db.query(...) unless the script has a variable named db.
+ *
+ * Variables declared by scripts hide the registered functions with their
+ * name, so registering a function doesn't change what a script does.
+ */
+func (r *Runtime) Register(fn Function) error {
+	if _, isNativeFn := interpreter_eval.IsNativeFunction(fn.Name); isNativeFn {
+		return compilerErrors.ErrNativeAlreadyRegistered.WithArgs(fn.Name)
+	}
+
+	return r.globals.Realm().HostFunctions.Register(fn)
+}
+
+// Calls the global function with the given name, registered and native
+// functions can be called too
+func (r *Runtime) Call(fnName string, args ...Value) (Value, error) {
+	return r.run(func() (Value, error) {
+		return r.call(fnName, args)
+	})
+}
+
+func (r *Runtime) call(fnName string, args []Value) (Value, error) {
+	value, ok := r.Get(fnName)
+
+	if !ok {
+		if hostFn, isHostFn := r.globals.Realm().HostFunctions.Lookup(fnName); isHostFn {
+			return hostFn.Call(args)
+		}
+
+		if nativeFn, isNativeFn := interpreter_eval.IsNativeFunction(fnName); isNativeFn {
+			realm := r.globals.Realm()
+
+			if err := nativeFns.CheckCapability(fnName, realm.Capabilities); err != nil {
+				return nil, err
+			}
+
+			return nativeFn(args, &nativeFns.Runtime{Call: interpreter_eval.CallValue, Capabilities: realm.Capabilities, Stdio: realm.Stdio})
+		}
+
+		return nil, compilerErrors.ErrVariableDoesNotExist.WithArgs(fnName)
+	}
+
+	switch function := value.(type) {
+	case interpreter_env.FunctionVal:
+		return interpreter_eval.CallFunction(function, fnName, args)
+	case *interpreter_env.HostFunction:
+		return function.Call(args)
+	}
+
+	return nil, compilerErrors.ErrNotAFunction.WithArgs(fnName)
+}
+
+/*
+ * Calls a function value, like a function a script gave to the host as a
+ * callback. The arguments are bound and the result returned the same way
+ * as in a call made by the script.
+ */
+func (r *Runtime) CallValue(fn Value, args ...Value) (Value, error) {
+	realm := r.globals.Realm()
+
+	// A function declared by a clone of r, given to a Go function they
+	// share, runs as part of the runs of the clone
+	if function, ok := fn.(interpreter_env.FunctionVal); ok && function.DeclarationEnv != nil {
+		realm = function.DeclarationEnv.Realm()
+	}
+
+	return realm.Run(context.Background(), realm.Limits, func() (Value, error) {
+		return interpreter_eval.CallValue(fn, args)
+	})
+}
@@ -0,0 +1,936 @@
+package pika_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pika"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+func TestEvalKeepsGlobals(t *testing.T) {
+	rt := pika.New()
+	ctx := context.Background()
+
+	if _, err := rt.Eval(ctx, "var total = 1"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	value, err := rt.Eval(ctx, "total += 2\ntotal * 10")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if value.GetValue() != 30.0 {
+		t.Errorf("Expected the last statement to be 30, but got: %v", value.GetValue())
+	}
+
+	total, ok := rt.Get("total")
+
+	if !ok || total.GetValue() != 3.0 {
+		t.Errorf("Expected total to be 3, but got: %v", total)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	rt := pika.New()
+
+	if _, err := rt.Eval(context.Background(), "var = 1"); !errors.Is(err, compilerErrors.ErrVariableExpectedIdentifierNameFollowingConstOrVar) {
+		t.Errorf("Expected a syntax error, but got: %v", err)
+	}
+
+	if _, err := rt.Eval(context.Background(), "missing + 1"); !errors.Is(err, compilerErrors.ErrVariableDoesNotExist) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrVariableDoesNotExist, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := rt.Eval(ctx, "var a = 1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error: %v, but got: %v", context.Canceled, err)
+	}
+}
+
+func TestEvalAfterAFailedEval(t *testing.T) {
+	rt := pika.New()
+	ctx := context.Background()
+
+	if _, err := rt.Eval(ctx, "fn getB() { return b }\nfn setB() { b = 5 }\nthrow \"x\"\nvar b = 2"); err == nil {
+		t.Fatal("Expected the program to throw")
+	}
+
+	if _, err := rt.Eval(ctx, "var c = \"WRONG\"\nconst k = 1"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if value, err := rt.Call("getB"); !errors.Is(err, compilerErrors.ErrVariableDoesNotExist) {
+		t.Errorf("Expected error: %v, but got: %v, %v", compilerErrors.ErrVariableDoesNotExist, value, err)
+	}
+
+	if _, err := rt.Call("setB"); !errors.Is(err, compilerErrors.ErrVariableDoesNotExist) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrVariableDoesNotExist, err)
+	}
+
+	if k, _ := rt.Get("k"); k.GetValue() != 1.0 {
+		t.Errorf("Expected k to be 1, but got: %v", k.GetValue())
+	}
+
+	if _, ok := rt.Get("b"); ok {
+		t.Error("Expected b to not exist")
+	}
+
+	value, err := rt.Eval(ctx, "var b = 3\nb + k")
+
+	if err != nil || value.GetValue() != 4.0 {
+		t.Errorf("Expected b to be declared again, but got: %v, %v", value, err)
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	rt := pika.New()
+	ctx := context.Background()
+
+	if err := rt.Set("limit", interpreter_makers.MkNumber(10)); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if _, err := rt.Eval(ctx, "var result = limit * 2\nlimit = 1"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if result, _ := rt.Get("result"); result.GetValue() != 20.0 {
+		t.Errorf("Expected result to be 20, but got: %v", result.GetValue())
+	}
+
+	if limit, _ := rt.Get("limit"); limit.GetValue() != 1.0 {
+		t.Errorf("Expected limit to be 1, but got: %v", limit.GetValue())
+	}
+
+	if _, ok := rt.Get("missing"); ok {
+		t.Error("Expected missing to not exist")
+	}
+
+	if _, err := rt.Eval(ctx, "const max = 5"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := rt.Set("max", interpreter_makers.MkNumber(6)); !errors.Is(err, compilerErrors.ErrVariableIsConstant) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrVariableIsConstant, err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	rt := pika.New()
+	_, err := rt.Eval(context.Background(), `
+		var calls = 0
+		fn add(a, b) {
+			calls++
+			return a + b
+		}
+		const notAFunction = 1`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	result, err := rt.Call("add", interpreter_makers.MkNumber(2), interpreter_makers.MkNumber(3))
+
+	if err != nil || result.GetValue() != 5.0 {
+		t.Errorf("Expected add to return 5, but got: %v, %v", result, err)
+	}
+
+	if calls, _ := rt.Get("calls"); calls.GetValue() != 1.0 {
+		t.Errorf("Expected calls to be 1, but got: %v", calls.GetValue())
+	}
+
+	length, err := rt.Call("len", interpreter_makers.MkString("four"))
+
+	if err != nil || length.GetValue() != 4.0 {
+		t.Errorf("Expected len to return 4, but got: %v, %v", length, err)
+	}
+
+	tests := []struct {
+		fnName      string
+		args        []pika.Value
+		expectedErr error
+	}{
+		{"add", []pika.Value{interpreter_makers.MkNumber(1)}, compilerErrors.ErrNotEnoughArguments},
+		{"notAFunction", nil, compilerErrors.ErrNotAFunction},
+		{"missing", nil, compilerErrors.ErrVariableDoesNotExist},
+	}
+
+	for _, test := range tests {
+		if _, err := rt.Call(test.fnName, test.args...); !errors.Is(err, test.expectedErr) {
+			t.Errorf("Expected calling %s to fail with: %v, but got: %v", test.fnName, test.expectedErr, err)
+		}
+	}
+}
+
+func TestCallValue(t *testing.T) {
+	rt := pika.New()
+	handlers := map[string]pika.Value{}
+
+	err := rt.Register(pika.Function{
+		Name:   "on",
+		Params: []interpreter_env.ValueType{interpreter_env.String, interpreter_env.Function},
+		Handler: func(args []pika.Value) (pika.Value, error) {
+			handlers[args[0].GetValue().(string)] = args[1]
+			return nil, nil
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	_, err = rt.Eval(context.Background(), `
+		var total = 0
+		on("add", (n) => {
+			total += n
+			return total
+		})`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	for idx := 1; idx <= 3; idx++ {
+		result, err := rt.CallValue(handlers["add"], interpreter_makers.MkNumber(float64(idx)))
+
+		if err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+
+		if expected := float64(idx * (idx + 1) / 2); result.GetValue() != expected {
+			t.Errorf("Expected the handler to return %v, but got: %v", expected, result.GetValue())
+		}
+	}
+
+	if total, _ := rt.Get("total"); total.GetValue() != 6.0 {
+		t.Errorf("Expected total to be 6, but got: %v", total.GetValue())
+	}
+
+	if _, err := rt.CallValue(handlers["add"]); !errors.Is(err, compilerErrors.ErrNotEnoughArguments) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrNotEnoughArguments, err)
+	}
+
+	if _, err := rt.CallValue(interpreter_makers.MkNumber(1)); !errors.Is(err, compilerErrors.ErrNotAFunction) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrNotAFunction, err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      pika.Limits
+		input       string
+		expectedErr error
+	}{
+		{"steps", pika.Limits{MaxSteps: 1000}, "while true {}", pika.ErrStepLimitExceeded},
+		{"timeout", pika.Limits{Timeout: 20 * time.Millisecond}, "while true {}", pika.ErrTimeout},
+		{"call depth", pika.Limits{MaxCallDepth: 50}, "fn loop(n) { return loop(n + 1) }\nloop(0)", pika.ErrStackOverflow},
+		{"default call depth", pika.Limits{}, "fn loop(n) { return loop(n + 1) }\nloop(0)", pika.ErrStackOverflow},
+		{"callbacks", pika.Limits{MaxSteps: 1000}, "map([1, 2], (n) => {\n  while true {}\n})", pika.ErrStepLimitExceeded},
+		{"case tests", pika.Limits{MaxSteps: 1000}, "fn spin() {\n  while true {}\n}\nswitch 1 {\n  case spin():\n    1\n  default:\n    2\n}", pika.ErrStepLimitExceeded},
+		{"array index", pika.Limits{MaxMemory: 1 << 20}, "const arr = []\narr[1000000000000] = 1", pika.ErrMemoryLimitExceeded},
+		{"push", pika.Limits{MaxMemory: 1 << 20}, "const arr = []\nwhile true {\n  push(arr, 1, 2, 3)\n}", pika.ErrMemoryLimitExceeded},
+		{"objects", pika.Limits{MaxMemory: 1 << 20}, "const obj = {}\nvar i = 0\nwhile true {\n  obj[string(i)] = i\n  i++\n}", pika.ErrMemoryLimitExceeded},
+		{"strings", pika.Limits{MaxMemory: 1 << 20}, "var text = \"abc\"\nwhile true {\n  text = text + text\n}", pika.ErrMemoryLimitExceeded},
+		{"compound strings", pika.Limits{MaxMemory: 1 << 20}, "var text = \"abc\"\nwhile true {\n  text += text\n}", pika.ErrMemoryLimitExceeded},
+		{"literals", pika.Limits{MaxMemory: 1 << 20}, "var all = []\nwhile true {\n  all = [all, { a: 1 }]\n}", pika.ErrMemoryLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := pika.New()
+			rt.SetLimits(tt.limits)
+
+			if _, err := rt.Eval(context.Background(), tt.input); !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error: %v, but got: %v", tt.expectedErr, err)
+			}
+
+			// A stopped run doesn't stop the runs after it
+			if _, err := rt.Eval(context.Background(), "var after = 1"); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := pika.New().Eval(ctx, "while true {}")
+
+	if !errors.Is(err, pika.ErrCanceled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrCanceled, err)
+	}
+}
+
+func TestLimitsOfCalls(t *testing.T) {
+	rt := pika.New()
+	rt.SetLimits(pika.Limits{MaxSteps: 500})
+
+	_, err := rt.Eval(context.Background(), `
+		fn spin(n) {
+			var i = 0
+			while i < n {
+				i++
+			}
+			return i
+		}`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Every call is a run with a budget of its own
+	for idx := 0; idx < 3; idx++ {
+		if _, err := rt.Call("spin", interpreter_makers.MkNumber(20)); err != nil {
+			t.Fatalf("Expected no error, but got: %v", err)
+		}
+	}
+
+	if _, err := rt.Call("spin", interpreter_makers.MkNumber(1000)); !errors.Is(err, pika.ErrStepLimitExceeded) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrStepLimitExceeded, err)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Setenv("PIKA_TEST_VALUE", "from env")
+
+	tests := []struct {
+		name        string
+		setUp       func(rt *pika.Runtime)
+		input       string
+		expected    any
+		expectedErr error
+	}{
+		{"time by default", func(rt *pika.Runtime) {}, "now() > 0", true, nil},
+		{"env denied by default", func(rt *pika.Runtime) {}, `getEnv("PIKA_TEST_VALUE")`, nil, pika.ErrPermissionDenied},
+		{"fs denied by default", func(rt *pika.Runtime) {}, `readFile("go.mod")`, nil, pika.ErrPermissionDenied},
+		{"process denied by default", func(rt *pika.Runtime) {}, "exit(1)", nil, pika.ErrPermissionDenied},
+		{"allowed env", func(rt *pika.Runtime) { rt.Allow(pika.Env) }, `getEnv("PIKA_TEST_VALUE")`, "from env", nil},
+		{"denied time", func(rt *pika.Runtime) { rt.Deny(pika.Time) }, "now()", nil, pika.ErrPermissionDenied},
+		{"deny all", func(rt *pika.Runtime) { rt.DenyAll() }, "randNum(1, 2)", nil, pika.ErrPermissionDenied},
+		{"deny all still computes", func(rt *pika.Runtime) { rt.DenyAll() }, `len(map([1, 2], (n) => { return n }))`, 2.0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := pika.New()
+			tt.setUp(rt)
+
+			value, err := rt.Eval(context.Background(), tt.input)
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Expected error: %v, but got: %v", tt.expectedErr, err)
+			}
+
+			if err == nil && value.GetValue() != tt.expected {
+				t.Errorf("Expected %v, but got: %v", tt.expected, value.GetValue())
+			}
+		})
+	}
+
+	rt := pika.New()
+
+	if _, err := rt.Call("getEnv", interpreter_makers.MkString("PIKA_TEST_VALUE")); !errors.Is(err, pika.ErrPermissionDenied) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrPermissionDenied, err)
+	}
+
+	rt.Allow(pika.Process)
+	_, err := rt.Eval(context.Background(), "exit(4)")
+
+	var exit *pika.ExitError
+	if !errors.As(err, &exit) || exit.Code != 4 {
+		t.Errorf("Expected the script to exit with code 4, but got: %v", err)
+	}
+}
+
+func TestNativeArgumentTypes(t *testing.T) {
+	tests := []string{
+		"num(5)",
+		`randNum("1", 2)`,
+		`randNum(1, "2")`,
+		`pow(2, "3")`,
+		"toUpperCase(5)",
+		"toLowerCase(true)",
+		"capitalize([])",
+		"reverseString({})",
+		`startsWith("pika", 1)`,
+		`endsWith(null, "a")`,
+		`concat("a", 1)`,
+		`includes("abc", "a")`,
+		"push({}, 1)",
+		"pop(1)",
+		`shift("abc")`,
+		`indexOf("abc", "a")`,
+		"len(5)",
+		"map([1], 1)",
+		"filter({}, (n) => { return n })",
+		`sort([2, 1], "desc")`,
+		"getEnv(1)",
+		"readFile(1)",
+		`writeFile("notes.txt", 1)`,
+		`exit("1")`,
+	}
+
+	rt := pika.New()
+	rt.Allow(pika.Env, pika.Process)
+
+	if err := rt.AllowFS(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range tests {
+		_, err := rt.Eval(context.Background(), input)
+
+		if !errors.Is(err, compilerErrors.ErrNativeArgumentType) {
+			t.Errorf("Expected %s to fail with: %v, but got: %v", input, compilerErrors.ErrNativeArgumentType, err)
+		}
+	}
+
+	// No native panics, whatever the types of its arguments
+	values := []string{"1", "NaN", `"a"`, "true", "null", "[]", "{}", "() => { return 0 }"}
+
+	var stdout bytes.Buffer
+	safe := pika.New()
+	safe.Allow(pika.Env, pika.Process)
+	safe.SetStdin(strings.NewReader(""))
+	safe.SetStdout(&stdout)
+	safe.SetStderr(&stdout)
+
+	for name := range nativeFns.NativeFunctions {
+		for _, first := range values {
+			for _, second := range values {
+				safe.Eval(context.Background(), fmt.Sprintf("%s(%s, %s)", name, first, second))
+			}
+		}
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	rt := pika.New()
+	_, err := rt.Eval(context.Background(), "fn fail() {\n  throw { message: \"broken\", id: 7 }\n}\nfail()")
+
+	var exception *pika.Exception
+	if !errors.Is(err, pika.ErrUncaughtException) || !errors.As(err, &exception) {
+		t.Fatalf("Expected error: %v, but got: %v", pika.ErrUncaughtException, err)
+	}
+
+	if !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected the error to have the message of the thrown object, but got: %v", err)
+	}
+
+	if id := exception.Value.(*interpreter_env.ObjectVal).Properties["id"]; id.GetValue() != 7.0 {
+		t.Errorf("Expected the thrown object, but got: %v", exception.Value.GetValue())
+	}
+
+	// The limits of the run and exit stop the script even inside a try
+	rt.SetLimits(pika.Limits{MaxSteps: 1000})
+
+	if _, err := rt.Eval(context.Background(), "try {\n  while true {}\n} catch {}"); !errors.Is(err, pika.ErrStepLimitExceeded) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrStepLimitExceeded, err)
+	}
+
+	rt.SetLimits(pika.Limits{})
+	rt.Allow(pika.Process)
+	_, err = rt.Eval(context.Background(), "var ran = false\ntry {\n  exit(3)\n} finally {\n  ran = true\n}")
+
+	var exit *pika.ExitError
+	if !errors.As(err, &exit) || exit.Code != 3 {
+		t.Errorf("Expected the script to exit with code 3, but got: %v", err)
+	}
+
+	if ran, _ := rt.Get("ran"); ran.GetValue() != false {
+		t.Errorf("Expected finally not to run after exit, but got: %v", ran.GetValue())
+	}
+}
+
+func TestStack(t *testing.T) {
+	rt := pika.New()
+	_, err := rt.Eval(context.Background(), "fn fail() {\n  throw 1\n}\nfn run(callback) {\n  callback()\n}\nrun(() => {\n  fail()\n})")
+
+	if !errors.Is(err, pika.ErrUncaughtException) {
+		t.Fatalf("Expected error: %v, but got: %v", pika.ErrUncaughtException, err)
+	}
+
+	expected := []struct {
+		function string
+		line     int
+	}{
+		{"fail", 8},
+		{diagnostic.AnonymousFunction, 5},
+		{"run", 7},
+	}
+
+	stack := pika.Stack(err)
+
+	if len(stack) != len(expected) {
+		t.Fatalf("Expected %d frames, but got: %v", len(expected), stack)
+	}
+
+	for idx, frame := range stack {
+		if frame.Function != expected[idx].function || frame.CallSite.Start.Line != expected[idx].line {
+			t.Errorf("Expected %s called at line %d, but got: %s at line %d", expected[idx].function, expected[idx].line, frame.Function, frame.CallSite.Start.Line)
+		}
+	}
+
+	// Functions called by the host have no call site in the program
+	_, err = rt.Call("fail")
+
+	if trace := pika.StackTrace(err); trace != "at fail (2:3)" {
+		t.Errorf("Expected the stack trace of a call of the host, but got: %q", trace)
+	}
+
+	if _, err := rt.Eval(context.Background(), "var a = 1\na()"); len(pika.Stack(err)) != 0 {
+		t.Errorf("Expected no frames outside of functions, but got: %v", pika.Stack(err))
+	}
+}
+
+func TestStdio(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		stdin          string
+		expectedStdout string
+		expectedStderr string
+	}{
+		{"print", `print(1, "one", [true, null])`, "", "1\n\"one\"\n[ true, null ]\n", ""},
+		{"printErr", `printErr("failed")`, "", "", "\"failed\"\n"},
+		{"prompt", "const first = prompt(\"Name\")\nconst second = prompt(\"Again\")\nprint(first, second)", "ada lovelace\r\nada\n", "NameAgain\"ada lovelace\"\n\"ada\"\n", ""},
+		{"prompt without a line ending", `print(prompt("Name"))`, "ada", "Name\"ada\"\n", ""},
+		{"prompt at the end of the input", `print(prompt("Name"))`, "", "Namenull\n", ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			rt := pika.New()
+			rt.SetStdin(strings.NewReader(tt.stdin))
+			rt.SetStdout(&stdout)
+			rt.SetStderr(&stderr)
+
+			if _, err := rt.Eval(context.Background(), tt.input); err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if stdout.String() != tt.expectedStdout {
+				t.Errorf("Expected stdout %q, but got: %q", tt.expectedStdout, stdout.String())
+			}
+
+			if stderr.String() != tt.expectedStderr {
+				t.Errorf("Expected stderr %q, but got: %q", tt.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestAllowFS(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "allowed")
+	outside := filepath.Join(dir, "outside")
+
+	for _, path := range []string{allowed, outside} {
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(outside, filepath.Join(allowed, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := pika.New()
+
+	if err := rt.AllowFS(allowed); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if err := rt.Set("path", interpreter_makers.MkString(filepath.Join(allowed, "notes.txt"))); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := rt.Eval(context.Background(), `
+		writeFile(path, "some notes")
+		readFile(path)`)
+
+	if err != nil || value.GetValue() != "some notes" {
+		t.Fatalf("Expected the file to be written and read, but got: %v, %v", value, err)
+	}
+
+	for _, path := range []string{"../outside/secret.txt", "link/secret.txt", ".."} {
+		if err := rt.Set("path", interpreter_makers.MkString(filepath.Join(allowed, path))); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := rt.Eval(context.Background(), "readFile(path)")
+
+		if !errors.Is(err, pika.ErrPathNotAllowed) {
+			t.Errorf("Expected reading %s to fail with: %v, but got: %v", path, pika.ErrPathNotAllowed, err)
+		}
+	}
+
+	// Denying FS forgets the allowed directories, allowing it again only allows the new ones
+	for name, deny := range map[string]func(rt *pika.Runtime){
+		"Deny":    func(rt *pika.Runtime) { rt.Deny(pika.FS) },
+		"DenyAll": func(rt *pika.Runtime) { rt.DenyAll() },
+	} {
+		reallowed := pika.New()
+
+		if err := reallowed.AllowFS(allowed); err != nil {
+			t.Fatal(err)
+		}
+
+		deny(reallowed)
+
+		if err := reallowed.AllowFS(outside); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := reallowed.Set("path", interpreter_makers.MkString(filepath.Join(allowed, "notes.txt"))); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := reallowed.Eval(context.Background(), "readFile(path)"); !errors.Is(err, pika.ErrPathNotAllowed) {
+			t.Errorf("Expected reading the directory allowed before %s to fail with: %v, but got: %v", name, pika.ErrPathNotAllowed, err)
+		}
+	}
+
+	denied := pika.New()
+
+	if err := denied.AllowFS(); !errors.Is(err, compilerErrors.ErrNoFSDirectories) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrNoFSDirectories, err)
+	}
+
+	if _, err := denied.Eval(context.Background(), `readFile("notes.txt")`); !errors.Is(err, pika.ErrPermissionDenied) {
+		t.Errorf("Expected error: %v, but got: %v", pika.ErrPermissionDenied, err)
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.pk")
+
+	if err := os.WriteFile(path, []byte("var a = 1\nvar b = missing"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := pika.New().RunFile(context.Background(), path)
+
+	var diag *diagnostic.Diagnostic
+
+	if !errors.As(err, &diag) || diag.Span.File != path || diag.Span.Start.Line != 2 {
+		t.Errorf("Expected an error on line 2 of %s, but got: %v", path, err)
+	}
+}
+
+var errNotFound = errors.New("not found")
+
+func newRuntimeWithFunctions(t *testing.T) *pika.Runtime {
+	rt := pika.New()
+
+	functions := []pika.Function{
+		{
+			Name:   "double",
+			Params: []interpreter_env.ValueType{interpreter_env.Number},
+			Handler: func(args []pika.Value) (pika.Value, error) {
+				return interpreter_makers.MkNumber(args[0].GetValue().(float64) * 2), nil
+			},
+		},
+		{
+			Name:     "sum",
+			Params:   []interpreter_env.ValueType{interpreter_env.Number},
+			Variadic: true,
+			Handler: func(args []pika.Value) (pika.Value, error) {
+				total := 0.0
+				for _, arg := range args {
+					total += arg.GetValue().(float64)
+				}
+				return interpreter_makers.MkNumber(total), nil
+			},
+		},
+		{
+			Name:     "count",
+			Variadic: true,
+			Handler: func(args []pika.Value) (pika.Value, error) {
+				return interpreter_makers.MkNumber(float64(len(args))), nil
+			},
+		},
+		{
+			Name: "ping",
+			Handler: func(args []pika.Value) (pika.Value, error) {
+				return interpreter_makers.MkNumber(0), nil
+			},
+		},
+		{
+			Name:   "db.query",
+			Params: []interpreter_env.ValueType{interpreter_env.String},
+			Handler: func(args []pika.Value) (pika.Value, error) {
+				if args[0].GetValue() != "users" {
+					return nil, errNotFound
+				}
+				return interpreter_makers.MkString("rows"), nil
+			},
+		},
+	}
+
+	for _, fn := range functions {
+		if err := rt.Register(fn); err != nil {
+			t.Fatalf("Expected %s to be registered, but got: %v", fn.Name, err)
+		}
+	}
+
+	return rt
+}
+
+func TestRegisteredFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    any
+		expectedErr error
+	}{
+		{name: "plain function", input: "double(4)", expected: 8.0},
+		{name: "variadic function", input: "sum(1, 2, 3)", expected: 6.0},
+		{name: "variadic function without the rest", input: "sum()", expected: 0.0},
+		{name: "variadic argument type", input: "sum(1, true)", expectedErr: compilerErrors.ErrNativeArgumentType},
+		{name: "variadic function without parameters", input: `count(1, "two", [3])`, expected: 3.0},
+		{name: "function without parameters", input: "ping()", expected: 0.0},
+		{name: "arguments to a function without parameters", input: "ping(1, 2)", expectedErr: compilerErrors.ErrTooManyArguments},
+		{name: "namespaced function", input: `db.query("users")`, expected: "rows"},
+		{name: "handler error", input: `db.query("orders")`, expectedErr: errNotFound},
+		{name: "handler error code", input: `db.query("orders")`, expectedErr: compilerErrors.ErrNativeFunctionFailed},
+		{name: "argument type", input: `double("four")`, expectedErr: compilerErrors.ErrNativeArgumentType},
+		{name: "too many arguments", input: "double(1, 2)", expectedErr: compilerErrors.ErrTooManyArguments},
+		{name: "functions of the script win", input: "fn double(n) {\nreturn n\n}\ndouble(4)", expected: 4.0},
+		{name: "variables hide namespaces", input: "const db = { query: (table) => {\nreturn 1\n} }\ndb.query(\"users\")", expected: 1.0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := newRuntimeWithFunctions(t).Eval(context.Background(), test.input)
+
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Errorf("Expected error: %v, but got: %v", test.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if value.GetValue() != test.expected {
+				t.Errorf("Expected %v, but got: %v", test.expected, value.GetValue())
+			}
+		})
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+	handler := func(args []pika.Value) (pika.Value, error) { return nil, nil }
+
+	tests := []struct {
+		name        string
+		expectedErr error
+	}{
+		{"len", compilerErrors.ErrNativeAlreadyRegistered},
+		{"double", compilerErrors.ErrNativeAlreadyRegistered},
+		{"db", compilerErrors.ErrNativeAlreadyRegistered},
+		{"double.half", compilerErrors.ErrNativeAlreadyRegistered},
+		{"", compilerErrors.ErrNativeInvalidName},
+		{"1st", compilerErrors.ErrNativeInvalidName},
+		{"a.b.c", compilerErrors.ErrNativeInvalidName},
+		{"db.", compilerErrors.ErrNativeInvalidName},
+	}
+
+	rt := newRuntimeWithFunctions(t)
+
+	for _, test := range tests {
+		if err := rt.Register(pika.Function{Name: test.name, Handler: handler}); !errors.Is(err, test.expectedErr) {
+			t.Errorf("Expected registering %q to fail with: %v, but got: %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+func TestRegisteredFunctionsBelongToTheirRuntime(t *testing.T) {
+	rt := newRuntimeWithFunctions(t)
+
+	if value, err := rt.Call("double", interpreter_makers.MkNumber(2)); err != nil || value.GetValue() != 4.0 {
+		t.Errorf("Expected double to return 4, but got: %v, %v", value, err)
+	}
+
+	if _, err := pika.New().Eval(context.Background(), "double(2)"); !errors.Is(err, compilerErrors.ErrVariableDoesNotExist) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrVariableDoesNotExist, err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	rt := newRuntimeWithFunctions(t)
+	rt.SetLimits(pika.Limits{MaxSteps: 10000})
+	rt.Deny(pika.Time)
+
+	_, err := rt.Eval(context.Background(), `
+const config = { items: [1, 2] }
+config.self = config
+const shared = [config.items, config.items]
+var count = 0
+fn next() {
+  count += 1
+  return count
+}`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	clone := rt.Clone()
+
+	if _, err := clone.Eval(context.Background(), "push(config.items, 3)\nnext()"); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	tests := []struct {
+		rt          *pika.Runtime
+		input       string
+		expected    any
+		expectedErr error
+	}{
+		{rt, "len(config.items)", 2.0, nil},
+		{rt, "next()", 1.0, nil},
+		{clone, "len(config.items)", 3.0, nil},
+		{clone, "next()", 2.0, nil},
+		{clone, "config.self == config", true, nil},
+		{clone, "shared[0] == config.items && len(shared[1]) == 3", true, nil},
+		{clone, "double(4)", 8.0, nil},
+		{clone, "now()", nil, pika.ErrPermissionDenied},
+		{clone, "while true {}", nil, pika.ErrStepLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		value, err := tt.rt.Eval(context.Background(), tt.input)
+
+		if !errors.Is(err, tt.expectedErr) {
+			t.Fatalf("Expected error evaluating %s: %v, but got: %v", tt.input, tt.expectedErr, err)
+		}
+
+		if err == nil && value.GetValue() != tt.expected {
+			t.Errorf("Expected %s to be %v, but got: %v", tt.input, tt.expected, value.GetValue())
+		}
+	}
+
+	handler := func(args []pika.Value) (pika.Value, error) { return nil, nil }
+
+	if err := clone.Register(pika.Function{Name: "onlyInClone", Handler: handler}); err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	if _, err := rt.Eval(context.Background(), "onlyInClone()"); !errors.Is(err, compilerErrors.ErrVariableDoesNotExist) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrVariableDoesNotExist, err)
+	}
+}
+
+// Run with -race, the runtimes must not share anything they change
+func TestRuntimesInParallel(t *testing.T) {
+	rt := newRuntimeWithFunctions(t)
+
+	err := rt.Register(pika.Function{
+		Name:   "apply",
+		Params: []interpreter_env.ValueType{interpreter_env.Function, interpreter_env.Any},
+		Handler: func(args []pika.Value) (pika.Value, error) {
+			return rt.CallValue(args[0], args[1])
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	_, err = rt.Eval(context.Background(), `
+const items = [1, 2, 3, 4, 5, 6]
+fn score(limit) {
+  const doubled = map(items, (n) => { return apply((m) => { return double(m) }, n) })
+  push(items, limit)
+  return len(filter(doubled, (n) => { return n > limit }))
+}`)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	// Goroutines instead of parallel subtests, so the runtimes run at the
+	// same time whatever the -parallel flag is
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for limit := 0; limit < 12; limit++ {
+				var stdout bytes.Buffer
+				clone := rt.Clone()
+				clone.SetStdout(&stdout)
+				clone.SetLimits(pika.Limits{MaxSteps: 100000, Timeout: time.Minute})
+
+				if _, err := clone.Eval(context.Background(), fmt.Sprintf("print(score(%d))", limit)); err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+					return
+				}
+
+				expected := fmt.Sprintln(6 - limit/2)
+
+				if stdout.String() != expected {
+					t.Errorf("Expected score(%d) to print %q, but got: %q", limit, expected, stdout.String())
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			var stdout bytes.Buffer
+			other := pika.New()
+			other.SetStdout(&stdout)
+
+			if _, err := other.Eval(context.Background(), "var total = 0\nfor (var i = 0; i < 1000; i++) { total += i }\nprint(total)"); err != nil {
+				t.Errorf("Expected no error, but got: %v", err)
+				return
+			}
+
+			if stdout.String() != "499500\n" {
+				t.Errorf("Expected 499500, but got: %q", stdout.String())
+			}
+		}()
+	}
+
+	wg.Wait()
+}
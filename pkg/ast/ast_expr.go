@@ -1,6 +1,9 @@
 package ast
 
-import "github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+import (
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
 
 type Expr interface {
 	Stmt
@@ -11,103 +14,160 @@ type AssigmentExpr struct {
 	Assigne  Expr
 	Value    Expr
 	Operator string
+	Span     token_type.Span
 }
 
 func (a AssigmentExpr) GetKind() ast_types.NodeType {
 	return a.Kind
 }
 
+func (a AssigmentExpr) GetSpan() token_type.Span {
+	return a.Span
+}
+
 type BinaryExpr struct {
 	Kind     ast_types.NodeType
 	Left     Expr
 	Right    Expr
 	Operator string
+	Span     token_type.Span
 }
 
 func (b BinaryExpr) GetKind() ast_types.NodeType {
 	return b.Kind
 }
 
+func (b BinaryExpr) GetSpan() token_type.Span {
+	return b.Span
+}
+
 type CallExpr struct {
 	Kind   ast_types.NodeType
 	Args   []Expr
 	Caller Expr
+	// Set by the resolver to the name of the native function called, if any
+	Native string
+	Span   token_type.Span
 }
 
 func (c CallExpr) GetKind() ast_types.NodeType {
 	return c.Kind
 }
 
+func (c CallExpr) GetSpan() token_type.Span {
+	return c.Span
+}
+
 type MemberExpr struct {
 	Kind     ast_types.NodeType
 	Object   Expr
 	Property Expr
 	Computed bool
+	Span     token_type.Span
 }
 
 func (m MemberExpr) GetKind() ast_types.NodeType {
 	return m.Kind
 }
 
+func (m MemberExpr) GetSpan() token_type.Span {
+	return m.Span
+}
+
+// Depth and Slot are filled by the resolver, Depth is the number of scopes
+// between the use of the variable and its declaration and Slot is the
+// position of the variable in the scope that declares it
 type Identifier struct {
 	Kind   ast_types.NodeType
 	Symbol string
+	Depth  int
+	Slot   int
+	Span   token_type.Span
 }
 
 func (i Identifier) GetKind() ast_types.NodeType {
 	return i.Kind
 }
 
+func (i Identifier) GetSpan() token_type.Span {
+	return i.Span
+}
+
 type ConditionalExpr struct {
 	Kind       ast_types.NodeType
 	Condition  Expr
 	Consequent Expr
 	Alternate  Expr
+	Span       token_type.Span
 }
 
 func (c ConditionalExpr) GetKind() ast_types.NodeType {
 	return c.Kind
 }
 
+func (c ConditionalExpr) GetSpan() token_type.Span {
+	return c.Span
+}
+
 type LogicalExpr struct {
 	Kind     ast_types.NodeType
 	Left     Expr
 	Right    Expr
 	Operator string
+	Span     token_type.Span
 }
 
 func (l LogicalExpr) GetKind() ast_types.NodeType {
 	return l.Kind
 }
 
+func (l LogicalExpr) GetSpan() token_type.Span {
+	return l.Span
+}
+
 type UnaryExpr struct {
 	Kind     ast_types.NodeType
 	Operator string
 	Argument Expr
 	Prefix   bool
+	Span     token_type.Span
 }
 
 func (u UnaryExpr) GetKind() ast_types.NodeType {
 	return u.Kind
 }
 
+func (u UnaryExpr) GetSpan() token_type.Span {
+	return u.Span
+}
+
 type UpdateExpr struct {
 	Kind     ast_types.NodeType
 	Operator string
 	Argument Identifier
 	Prefix   bool
+	Span     token_type.Span
 }
 
 func (u UpdateExpr) GetKind() ast_types.NodeType {
 	return u.Kind
 }
 
+func (u UpdateExpr) GetSpan() token_type.Span {
+	return u.Span
+}
+
 type ArrowFunctionExpr struct {
 	Kind   ast_types.NodeType
 	Params []Identifier
 	Body   []Stmt
+	Span   token_type.Span
 }
 
 func (a ArrowFunctionExpr) GetKind() ast_types.NodeType {
 	return a.Kind
 }
+
+func (a ArrowFunctionExpr) GetSpan() token_type.Span {
+	return a.Span
+}
@@ -1,76 +1,136 @@
 package ast
 
-import "github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+import (
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
 
 type Property struct {
 	Kind  ast_types.NodeType
 	Key   string
 	Value Expr
+	Span  token_type.Span
 }
 
 func (p Property) GetKind() ast_types.NodeType {
 	return p.Kind
 }
 
+func (p Property) GetSpan() token_type.Span {
+	return p.Span
+}
+
 type NumericLiteral struct {
 	Kind  ast_types.NodeType
 	Value float64
+	Span  token_type.Span
 }
 
 func (n NumericLiteral) GetKind() ast_types.NodeType {
 	return n.Kind
 }
 
+func (n NumericLiteral) GetSpan() token_type.Span {
+	return n.Span
+}
+
 type ObjectLiteral struct {
 	Kind       ast_types.NodeType
 	Properties []Property
+	Span       token_type.Span
 }
 
 func (o ObjectLiteral) GetKind() ast_types.NodeType {
 	return o.Kind
 }
 
+func (o ObjectLiteral) GetSpan() token_type.Span {
+	return o.Span
+}
+
 type NullLiteral struct {
 	Kind  ast_types.NodeType
 	Value any // nil
+	Span  token_type.Span
 }
 
 func (n NullLiteral) GetKind() ast_types.NodeType {
 	return n.Kind
 }
 
+func (n NullLiteral) GetSpan() token_type.Span {
+	return n.Span
+}
+
 type BooleanLiteral struct {
 	Kind  ast_types.NodeType
 	Value bool
+	Span  token_type.Span
 }
 
 func (b BooleanLiteral) GetKind() ast_types.NodeType {
 	return b.Kind
 }
 
+func (b BooleanLiteral) GetSpan() token_type.Span {
+	return b.Span
+}
+
 type StringLiteral struct {
 	Kind  ast_types.NodeType
 	Value string
+	Span  token_type.Span
 }
 
 func (s StringLiteral) GetKind() ast_types.NodeType {
 	return s.Kind
 }
 
+func (s StringLiteral) GetSpan() token_type.Span {
+	return s.Span
+}
+
+// A template literal, its text is split by the embedded expressions so
+// there is always one more piece of text than expressions
+type TemplateLiteral struct {
+	Kind        ast_types.NodeType
+	Quasis      []string
+	Expressions []Expr
+	Span        token_type.Span
+}
+
+func (t TemplateLiteral) GetKind() ast_types.NodeType {
+	return t.Kind
+}
+
+func (t TemplateLiteral) GetSpan() token_type.Span {
+	return t.Span
+}
+
 type NaNLiteral struct {
 	Kind  ast_types.NodeType
 	Value any // nil
+	Span  token_type.Span
 }
 
 func (n NaNLiteral) GetKind() ast_types.NodeType {
 	return n.Kind
 }
 
+func (n NaNLiteral) GetSpan() token_type.Span {
+	return n.Span
+}
+
 type ArrayLiteral struct {
 	Kind     ast_types.NodeType
 	Elements []Expr
+	Span     token_type.Span
 }
 
 func (a ArrayLiteral) GetKind() ast_types.NodeType {
 	return a.Kind
 }
+
+func (a ArrayLiteral) GetSpan() token_type.Span {
+	return a.Span
+}
@@ -2,119 +2,230 @@ package ast
 
 import (
 	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 )
 
 type Stmt interface {
 	GetKind() ast_types.NodeType
+	GetSpan() token_type.Span
 }
 
 type Program struct {
 	Kind ast_types.NodeType
 	Body []Stmt
+	Span token_type.Span
 }
 
 func (p Program) GetKind() ast_types.NodeType {
 	return p.Kind
 }
 
+func (p Program) GetSpan() token_type.Span {
+	return p.Span
+}
+
 type FunctionDeclaration struct {
 	Kind   ast_types.NodeType
 	Params []Identifier
 	Name   string
 	Body   []Stmt
+	Span   token_type.Span
 }
 
 func (f FunctionDeclaration) GetKind() ast_types.NodeType {
 	return f.Kind
 }
 
+func (f FunctionDeclaration) GetSpan() token_type.Span {
+	return f.Span
+}
+
 type VariableDeclaration struct {
 	Kind       ast_types.NodeType
 	Constant   bool
 	Identifier string
 	Value      Expr
+	Span       token_type.Span
 }
 
 func (vd VariableDeclaration) GetKind() ast_types.NodeType {
 	return vd.Kind
 }
 
+func (vd VariableDeclaration) GetSpan() token_type.Span {
+	return vd.Span
+}
+
 type IfStatement struct {
 	Kind       ast_types.NodeType
 	Test       Expr
 	Body       []Stmt
 	ElseIfStmt []ElseIfStatement
 	ElseBody   []Stmt
+	Span       token_type.Span
 }
 
 type ElseIfStatement struct {
 	Test Expr
 	Body []Stmt
+	Span token_type.Span
 }
 
 func (cd IfStatement) GetKind() ast_types.NodeType {
 	return cd.Kind
 }
 
+func (cd IfStatement) GetSpan() token_type.Span {
+	return cd.Span
+}
+
 type SwitchStatement struct {
 	Kind         ast_types.NodeType
 	Discriminant Expr
 	CaseStmts    []CaseStatement
 	DefaultStmt  CaseStatement
+	Span         token_type.Span
 }
 
 type CaseStatement struct {
 	Test []Expr
 	Body []Stmt
+	Span token_type.Span
 }
 
 func (cs SwitchStatement) GetKind() ast_types.NodeType {
 	return cs.Kind
 }
 
+func (cs SwitchStatement) GetSpan() token_type.Span {
+	return cs.Span
+}
+
 type ReturnStatement struct {
 	Kind     ast_types.NodeType
 	Argument Expr
+	Span     token_type.Span
 }
 
 func (rs ReturnStatement) GetKind() ast_types.NodeType {
 	return rs.Kind
 }
 
+func (rs ReturnStatement) GetSpan() token_type.Span {
+	return rs.Span
+}
+
 type WhileStatement struct {
-	Kind ast_types.NodeType
-	Test Expr
-	Body []Stmt
+	Kind  ast_types.NodeType
+	Test  Expr
+	Body  []Stmt
+	Label string
+	Span  token_type.Span
 }
 
 func (ws WhileStatement) GetKind() ast_types.NodeType {
 	return ws.Kind
 }
 
+func (ws WhileStatement) GetSpan() token_type.Span {
+	return ws.Span
+}
+
 type ContinueStatement struct {
-	Kind ast_types.NodeType
+	Kind  ast_types.NodeType
+	Label string
+	Span  token_type.Span
 }
 
 func (cs ContinueStatement) GetKind() ast_types.NodeType {
 	return cs.Kind
 }
 
+func (cs ContinueStatement) GetSpan() token_type.Span {
+	return cs.Span
+}
+
 type BreakStatement struct {
-	Kind ast_types.NodeType
+	Kind  ast_types.NodeType
+	Label string
+	Span  token_type.Span
 }
 
 func (bs BreakStatement) GetKind() ast_types.NodeType {
 	return bs.Kind
 }
 
+func (bs BreakStatement) GetSpan() token_type.Span {
+	return bs.Span
+}
+
 type ForStatement struct {
 	Kind   ast_types.NodeType
 	Init   Expr
 	Test   Expr
 	Update Expr
 	Body   []Stmt
+	Label  string
+	Span   token_type.Span
 }
 
 func (fs ForStatement) GetKind() ast_types.NodeType {
 	return fs.Kind
 }
+
+func (fs ForStatement) GetSpan() token_type.Span {
+	return fs.Span
+}
+
+type TryStatement struct {
+	Kind  ast_types.NodeType
+	Block []Stmt
+	// nil if the statement only has a finally block
+	Handler *CatchClause
+	// nil if the statement only has a catch block
+	Finalizer []Stmt
+	Span      token_type.Span
+}
+
+type CatchClause struct {
+	// Name of the variable holding the caught error, "" if it isn't named
+	Param string
+	Body  []Stmt
+	Span  token_type.Span
+}
+
+func (ts TryStatement) GetKind() ast_types.NodeType {
+	return ts.Kind
+}
+
+func (ts TryStatement) GetSpan() token_type.Span {
+	return ts.Span
+}
+
+type ThrowStatement struct {
+	Kind     ast_types.NodeType
+	Argument Expr
+	Span     token_type.Span
+}
+
+func (ts ThrowStatement) GetKind() ast_types.NodeType {
+	return ts.Kind
+}
+
+func (ts ThrowStatement) GetSpan() token_type.Span {
+	return ts.Span
+}
+
+// Takes the place of a statement that could not be parsed
+type ErrorNode struct {
+	Kind ast_types.NodeType
+	Span token_type.Span
+}
+
+func (en ErrorNode) GetKind() ast_types.NodeType {
+	return en.Kind
+}
+
+func (en ErrorNode) GetSpan() token_type.Span {
+	return en.Span
+}
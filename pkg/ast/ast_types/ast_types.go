@@ -14,6 +14,9 @@ const (
 	ContinueStatement   NodeType = "ContinueStatement"
 	BreakStatement      NodeType = "BreakStatement"
 	ForStatement        NodeType = "ForStatement"
+	TryStatement        NodeType = "TryStatement"
+	ThrowStatement      NodeType = "ThrowStatement"
+	ErrorNode           NodeType = "ErrorNode"
 
 	// EXPRESSIONS
 	AssigmentExpr     NodeType = "AssigmentExpr"
@@ -28,21 +31,26 @@ const (
 	ArrowFunctionExpr NodeType = "ArrowFunctionExpr"
 
 	// LITERALS
-	ObjectLiteral  NodeType = "ObjectLiteral"
-	Property       NodeType = "Property"
-	NumericLiteral NodeType = "NumericLiteral"
-	NullLiteral    NodeType = "NullLiteral"
-	BooleanLiteral NodeType = "BooleanLiteral"
-	StringLiteral  NodeType = "StringLiteral"
-	NaNLiteral     NodeType = "NaNLiteral"
-	ArrayLiteral   NodeType = "ArrayLiteral"
+	ObjectLiteral   NodeType = "ObjectLiteral"
+	Property        NodeType = "Property"
+	NumericLiteral  NodeType = "NumericLiteral"
+	NullLiteral     NodeType = "NullLiteral"
+	BooleanLiteral  NodeType = "BooleanLiteral"
+	StringLiteral   NodeType = "StringLiteral"
+	TemplateLiteral NodeType = "TemplateLiteral"
+	NaNLiteral      NodeType = "NaNLiteral"
+	ArrayLiteral    NodeType = "ArrayLiteral"
 )
 
 var (
 	// MATH EXPR
 	AdditiveExpr       = []string{"+", "-"}
 	MultiplicativeExpr = []string{"*", "/", "%"}
-	MathExpr           = []string{"+", "-", "*", "/", "%", "**"}
+	MathExpr           = []string{"+", "-", "*", "/", "%", "**", "&", "|", "^", "<<", ">>", ">>>"}
+
+	// BITWISE EXPR
+	ShiftExpr   = []string{"<<", ">>", ">>>"}
+	BitwiseExpr = []string{"&", "|", "^"}
 
 	// BOOLEAN EXPR
 	ComparisonExpr = []string{"<", "<=", ">", ">="}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Waxer59/PikaLang/internal/utils"
 	"github.com/Waxer59/PikaLang/pkg/cli/exitCodes"
+	"github.com/Waxer59/PikaLang/pkg/cli/report"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
 	"github.com/Waxer59/PikaLang/pkg/parser"
@@ -66,13 +67,14 @@ func startRepl(cCtx *cli.Context) error {
 		}
 
 		if err != nil {
-			return fmt.Errorf(err.Error())
+			report.Print(err, code)
+			continue
 		}
 
 		eval, err := interpreter_eval.Evaluate(*program, env)
 
 		if err != nil {
-			color.Red(err.Error())
+			report.Print(err, code)
 		} else {
 			fmt.Println(eval.GetValue())
 		}
@@ -1,18 +1,22 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/Waxer59/PikaLang/internal/utils"
+	"github.com/Waxer59/PikaLang/pkg/ast"
 	"github.com/Waxer59/PikaLang/pkg/cli/exitCodes"
+	"github.com/Waxer59/PikaLang/pkg/cli/report"
+	"github.com/Waxer59/PikaLang/pkg/compiler"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
 	"github.com/Waxer59/PikaLang/pkg/parser"
+	"github.com/Waxer59/PikaLang/pkg/vm"
 
-	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
@@ -23,6 +27,28 @@ func SetUpRunCommand() *cli.Command {
 		Name:   "run",
 		Usage:  "Run a file",
 		Action: runApp,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "vm",
+				Usage: "compile the file to bytecode and run it on the virtual machine",
+			},
+			&cli.StringSliceFlag{
+				Name:  "allow-fs",
+				Usage: "let the program read and write files in the given `DIR`, can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "allow-env",
+				Usage: "let the program read environment variables",
+			},
+			&cli.BoolFlag{
+				Name:  "allow-process",
+				Usage: "let the program exit the process with a code",
+			},
+			&cli.StringSliceFlag{
+				Name:  "deny",
+				Usage: "stop the program from using a `CAPABILITY` it has by default: console, time or random",
+			},
+		},
 	}
 
 	return &runCommand
@@ -36,7 +62,6 @@ func runApp(cCtx *cli.Context) error {
 		return cli.Exit("File extension must be .pk", int(exitCodes.FileExtensionError))
 	}
 
-	env := interpreter_env.New(nil)
 	wd, err := os.Getwd()
 
 	if err != nil {
@@ -62,17 +87,87 @@ func runApp(cCtx *cli.Context) error {
 
 	p := parser.New()
 
+	if relativeName, err := filepath.Rel(wd, fileName); err == nil {
+		p.SetFileName(relativeName)
+	} else {
+		p.SetFileName(fileName)
+	}
+
 	program, err := p.ProduceAST(src)
 
 	if err != nil {
-		return fmt.Errorf(err.Error())
+		report.Print(err, src)
+		return cli.Exit("", int(exitCodes.SyntaxError))
+	}
+
+	capabilities, err := capabilitiesFromFlags(cCtx)
+
+	if err != nil {
+		report.Print(err, src)
+		return cli.Exit("", int(exitCodes.RuntimeError))
+	}
+
+	if cCtx.Bool("vm") {
+		err = runBytecode(*program, capabilities)
+	} else {
+		env := interpreter_env.New(nil)
+		env.Realm().Capabilities = capabilities
+		_, err = interpreter_eval.Evaluate(*program, env)
 	}
 
-	_, err = interpreter_eval.Evaluate(*program, env)
+	var exit *interpreter_env.ExitError
+
+	if errors.As(err, &exit) {
+		return cli.Exit("", exit.Code)
+	}
 
 	if err != nil {
-		color.Red(err.Error())
+		report.Print(err, src)
+		return cli.Exit("", int(exitCodes.RuntimeError))
 	}
 
 	return nil
 }
+
+func runBytecode(program ast.Program, capabilities *interpreter_env.Capabilities) error {
+	bytecode, err := compiler.Compile(program)
+
+	if err != nil {
+		return err
+	}
+
+	machine := vm.New(bytecode)
+	machine.SetCapabilities(capabilities)
+
+	return machine.Run()
+}
+
+func capabilitiesFromFlags(cCtx *cli.Context) (*interpreter_env.Capabilities, error) {
+	capabilities := interpreter_env.DefaultCapabilities()
+
+	if dirs := cCtx.StringSlice("allow-fs"); len(dirs) > 0 {
+		if err := capabilities.AllowFS(dirs...); err != nil {
+			return nil, err
+		}
+	}
+
+	if cCtx.Bool("allow-env") {
+		capabilities.Allow(interpreter_env.Env)
+	}
+
+	if cCtx.Bool("allow-process") {
+		capabilities.Allow(interpreter_env.Process)
+	}
+
+	for _, name := range cCtx.StringSlice("deny") {
+		capability, err := interpreter_env.ParseCapability(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		capabilities.Deny(capability)
+	}
+
+	return capabilities, nil
+}
@@ -8,4 +8,6 @@ const (
 	FileReadError
 	GetWDError
 	FileExtensionError
+	SyntaxError
+	RuntimeError
 )
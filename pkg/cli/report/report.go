@@ -0,0 +1,177 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+type palette struct {
+	severity *color.Color
+	gutter   *color.Color
+	bold     *color.Color
+}
+
+func newPalette(severity diagnostic.Severity, colored bool) palette {
+	severityColor := color.FgRed
+	switch severity {
+	case diagnostic.Warning:
+		severityColor = color.FgYellow
+	case diagnostic.Note:
+		severityColor = color.FgCyan
+	}
+
+	p := palette{
+		severity: color.New(severityColor, color.Bold),
+		gutter:   color.New(color.FgBlue, color.Bold),
+		bold:     color.New(color.Bold),
+	}
+
+	for _, c := range []*color.Color{p.severity, p.gutter, p.bold} {
+		if colored {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+	}
+
+	return p
+}
+
+// Prints err to stderr, using colors only when stderr is a terminal
+func Print(err error, src string) {
+	colored := !color.NoColor && (isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd()))
+	Render(os.Stderr, err, src, colored)
+}
+
+// Writes err to w. Diagnostics are rendered with the offending source lines,
+// any other error is written as a single line.
+func Render(w io.Writer, err error, src string, colored bool) {
+	var list diagnostic.List
+
+	if errors.As(err, &list) {
+		for idx, diag := range list {
+			if idx > 0 {
+				fmt.Fprintln(w)
+			}
+			RenderDiagnostic(w, diag, src, colored)
+		}
+
+		if len(list) > 1 {
+			p := newPalette(diagnostic.Error, colored)
+			fmt.Fprintf(w, "\n%s\n", p.severity.Sprintf("%s: found %d errors", diagnostic.Error, len(list)))
+		}
+		return
+	}
+
+	var diag *diagnostic.Diagnostic
+
+	if !errors.As(err, &diag) {
+		p := newPalette(diagnostic.Error, colored)
+		fmt.Fprintf(w, "%s %s\n", p.severity.Sprint(diagnostic.Error.String()+":"), p.bold.Sprint(err.Error()))
+		return
+	}
+
+	RenderDiagnostic(w, diag, src, colored)
+}
+
+/*
+ * Renders a diagnostic in the following form:
+ *
+ * ERROR[P0201]: Variable does not exist in this scope: y
+ *   --> main.pk:3:7
+ *    |
+ *  3 | print(y)
+ *    |       ^
+ *    = help: declare it with 'var y'
+ *
+ * Runtime errors raised inside a function end with their stack trace.
+ */
+func RenderDiagnostic(w io.Writer, diag *diagnostic.Diagnostic, src string, colored bool) {
+	p := newPalette(diag.Severity, colored)
+
+	fmt.Fprintf(w, "%s %s\n", p.severity.Sprintf("%s[%s]:", diag.Severity, diag.Code), p.bold.Sprint(diag.Message))
+
+	lines := strings.Split(src, "\n")
+	startLine := diag.Span.Start.Line
+	endLine := diag.Span.End.Line
+
+	if endLine < startLine {
+		endLine = startLine
+	}
+
+	hasSnippet := diag.HasSpan() && startLine <= len(lines)
+
+	gutterWidth := len(fmt.Sprint(endLine))
+	emptyGutter := strings.Repeat(" ", gutterWidth+1) + p.gutter.Sprint("|")
+
+	if diag.HasSpan() {
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat(" ", gutterWidth), p.gutter.Sprint("-->"), diag.Location())
+	}
+
+	if hasSnippet {
+		fmt.Fprintln(w, emptyGutter)
+
+		for lineNumber := startLine; lineNumber <= endLine && lineNumber <= len(lines); lineNumber++ {
+			line := strings.TrimRight(lines[lineNumber-1], "\r")
+			lineRunes := []rune(line)
+
+			fmt.Fprintf(w, "%s %s %s\n", p.gutter.Sprintf("%*d", gutterWidth, lineNumber), p.gutter.Sprint("|"), line)
+
+			from := 1
+			if lineNumber == startLine {
+				from = diag.Span.Start.Column
+			}
+
+			to := len(lineRunes) + 1
+			if lineNumber == endLine && diag.Span.End.Line == endLine {
+				to = diag.Span.End.Column
+			}
+
+			// Zero width spans still get a caret
+			if to <= from {
+				to = from + 1
+			}
+
+			fmt.Fprintf(w, "%s %s%s\n", emptyGutter, underlinePadding(lineRunes, from), p.severity.Sprint(strings.Repeat("^", to-from)))
+		}
+	}
+
+	for _, note := range diag.Notes {
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat(" ", gutterWidth+1), p.gutter.Sprint("="), p.bold.Sprint("note: ")+note)
+	}
+
+	if diag.Help != "" {
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat(" ", gutterWidth+1), p.gutter.Sprint("="), p.bold.Sprint("help: ")+diag.Help)
+	}
+
+	if len(diag.Stack) > 0 {
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat(" ", gutterWidth+1), p.gutter.Sprint("="), p.bold.Sprint("stack trace:"))
+
+		for _, line := range strings.Split(diag.StackTrace(), "\n") {
+			fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", gutterWidth+5), line)
+		}
+	}
+}
+
+// Whitespace placed before the carets, tabs are kept so they line up with the source line
+func underlinePadding(line []rune, column int) string {
+	var padding strings.Builder
+
+	for idx := 0; idx < column-1; idx++ {
+		if idx < len(line) && line[idx] == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	return padding.String()
+}
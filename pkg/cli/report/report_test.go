@@ -0,0 +1,102 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+func TestRenderDiagnostic(t *testing.T) {
+	src := "var x = 1\n\tprint(y)\n"
+	diag := diagnostic.New("P0201", diagnostic.Runtime, "Variable does not exist in this scope: y").
+		At(token_type.Span{
+			File:  "main.pk",
+			Start: token_type.Position{Offset: 17, Line: 2, Column: 8},
+			End:   token_type.Position{Offset: 18, Line: 2, Column: 9},
+		}).
+		WithNote("variables must be declared before being used").
+		WithHelp("declare it with 'var y'")
+
+	var out bytes.Buffer
+	Render(&out, diag, src, false)
+
+	expected := "ERROR[P0201]: Variable does not exist in this scope: y\n" +
+		" --> main.pk:2:8\n" +
+		"  |\n" +
+		"2 | \tprint(y)\n" +
+		"  | \t      ^\n" +
+		"  = note: variables must be declared before being used\n" +
+		"  = help: declare it with 'var y'\n"
+
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestRenderMultilineSpan(t *testing.T) {
+	src := "if x {\n  y\n}"
+	diag := diagnostic.New("P0001", diagnostic.Syntax, "Broken block").
+		At(token_type.Span{
+			Start: token_type.Position{Offset: 5, Line: 1, Column: 6},
+			End:   token_type.Position{Offset: 13, Line: 3, Column: 2},
+		})
+
+	var out bytes.Buffer
+	Render(&out, diag, src, false)
+
+	expected := "ERROR[P0001]: Broken block\n" +
+		" --> 1:6\n" +
+		"  |\n" +
+		"1 | if x {\n" +
+		"  |      ^\n" +
+		"2 |   y\n" +
+		"  | ^^^\n" +
+		"3 | }\n" +
+		"  | ^\n"
+
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestRenderStackTrace(t *testing.T) {
+	src := "fn fail() {\n  throw 1\n}\nfail()"
+	diag := diagnostic.New("P1101", diagnostic.Runtime, "Uncaught exception: 1").
+		At(token_type.Span{
+			File:  "main.pk",
+			Start: token_type.Position{Offset: 14, Line: 2, Column: 3},
+			End:   token_type.Position{Offset: 21, Line: 2, Column: 10},
+		}).
+		WithStack([]diagnostic.Frame{{
+			Function: "fail",
+			CallSite: token_type.Span{File: "main.pk", Start: token_type.Position{Offset: 24, Line: 4, Column: 1}},
+		}})
+
+	var out bytes.Buffer
+	Render(&out, diag, src, false)
+
+	expected := "ERROR[P1101]: Uncaught exception: 1\n" +
+		" --> main.pk:2:3\n" +
+		"  |\n" +
+		"2 |   throw 1\n" +
+		"  |   ^^^^^^^\n" +
+		"  = stack trace:\n" +
+		"      at fail (main.pk:2:3)\n" +
+		"      at <main> (main.pk:4:1)\n"
+
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+}
+
+func TestRenderPlainError(t *testing.T) {
+	var out bytes.Buffer
+	Render(&out, errors.New("something failed"), "", false)
+
+	if out.String() != "ERROR: something failed\n" {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+}
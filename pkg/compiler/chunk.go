@@ -0,0 +1,67 @@
+package compiler
+
+import (
+	"sort"
+
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+// A sequence of instructions with the values and functions they refer to
+type Chunk struct {
+	Code      []byte
+	Constants []interpreter_env.RuntimeValue
+	Functions []*Function
+	// Spans are stored only when they change, sorted by offset
+	spans []spanEntry
+}
+
+type spanEntry struct {
+	offset int
+	span   token_type.Span
+}
+
+// Returns the span of the source code that produced the instruction at the given offset
+func (c *Chunk) SpanAt(offset int) token_type.Span {
+	idx := sort.Search(len(c.spans), func(i int) bool {
+		return c.spans[i].offset > offset
+	})
+
+	if idx == 0 {
+		return token_type.Span{}
+	}
+
+	return c.spans[idx-1].span
+}
+
+func (c *Chunk) write(instruction []byte, span token_type.Span) int {
+	offset := len(c.Code)
+
+	if len(c.spans) == 0 || c.spans[len(c.spans)-1].span != span {
+		c.spans = append(c.spans, spanEntry{offset: offset, span: span})
+	}
+
+	c.Code = append(c.Code, instruction...)
+
+	return offset
+}
+
+// A compiled function declaration, arrow function or the program itself
+type Function struct {
+	Name         string
+	Arity        int
+	Arrow        bool
+	UpvalueCount int
+	// Names of the variables captured, to report the ones used before they are declared
+	UpvalueNames []string
+	Chunk        Chunk
+}
+
+// Result of compiling a program
+type Bytecode struct {
+	Main *Function
+	// Names of the global variables, indexed by the global slot
+	Globals []string
+	// Names of the native functions, indexed by the operand of OpCallNative
+	Natives []string
+}
@@ -0,0 +1,244 @@
+/*
+ * Package compiler lowers a program to bytecode for the virtual machine of
+ * the pkg/vm package.
+ *
+ * Variables declared at the top level of the program are globals, addressed
+ * by a global slot. Variables declared inside of blocks and functions are
+ * locals, stored in the stack of the function at a fixed slot. Functions
+ * capture the locals of the functions around them as upvalues.
+ *
+ * Identifiers are compiled from the variable the resolver bound them to. A
+ * function can use a variable declared after it in its scope, so the slots
+ * of the variables of a block are reserved when the block starts, and they
+ * hold nil until the variable is declared.
+ */
+package compiler
+
+import (
+	"math"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+	"github.com/Waxer59/PikaLang/pkg/resolver"
+)
+
+const (
+	maxOperand = math.MaxUint16
+	maxArgs    = math.MaxUint8
+)
+
+type local struct {
+	name     string
+	depth    int
+	constant bool
+	// Captured locals have to be closed when they go out of scope
+	captured bool
+}
+
+type upvalue struct {
+	// Slot of the local in the enclosing function, or index of its upvalue
+	index    int
+	isLocal  bool
+	constant bool
+	name     string
+}
+
+// A scope of the resolver, whose variables identifiers are bound to by the
+// number of scopes to go up and their slot in the scope
+type varScope struct {
+	parent *varScope
+	// Function whose stack holds the variables, nil for the globals of the program
+	owner *funcCompiler
+	// Local slot of every variable of the scope, in the order the resolver declares them
+	slots []int
+	// Number of variables of the scope declared so far
+	declared int
+}
+
+type loop struct {
+	label string
+	// Depth of the scope the loop is in, break and continue discard every local deeper than it
+	scopeDepth int
+	// Offset of the start of the loop, -1 if continue has to jump forward
+	continueTarget int
+	breakJumps     []int
+	continueJumps  []int
+	// Number of try blocks around the loop, break and continue leave the ones inside of it
+	tries int
+}
+
+// A try block being compiled, the code leaving it with return, break or
+// continue removes its handler and runs its finally block
+type tryBlock struct {
+	// nil if the try statement doesn't have a finally block
+	finalizer []ast.Stmt
+	// Number of loops around the try statement, the finally block can only jump out of them
+	loops int
+	// Scope of the try statement
+	scope *varScope
+}
+
+// Globals and natives are shared by all the functions of a program
+type symbols struct {
+	globals     map[string]int
+	globalNames []string
+	natives     map[string]int
+	nativeNames []string
+}
+
+type funcCompiler struct {
+	enclosing  *funcCompiler
+	function   *Function
+	locals     []local
+	upvalues   []upvalue
+	scopeDepth int
+	loops      []*loop
+	tries      []*tryBlock
+	names      map[string]int
+	symbols    *symbols
+	scope      *varScope
+	// Span of the node being compiled, attached to the emitted instructions
+	span token_type.Span
+}
+
+// The program is checked by the resolver first, so it reports the same early
+// errors as the interpreter and calls the same native functions
+func Compile(program ast.Program) (*Bytecode, error) {
+	program, err := resolver.Resolve(program, nil, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := &funcCompiler{
+		function: &Function{Name: "<program>"},
+		names:    make(map[string]int),
+		symbols: &symbols{
+			globals: make(map[string]int),
+			natives: make(map[string]int),
+		},
+		scope: &varScope{},
+		span:  program.Span,
+	}
+
+	for _, stmt := range program.Body {
+		if err := c.compileStmt(stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	c.emit(OpNull)
+	c.emit(OpReturn)
+
+	return &Bytecode{
+		Main:    c.function,
+		Globals: c.symbols.globalNames,
+		Natives: c.symbols.nativeNames,
+	}, nil
+}
+
+func (c *funcCompiler) compileStmt(stmt ast.Stmt) error {
+	prevSpan := c.span
+	c.span = stmt.GetSpan()
+	defer func() { c.span = prevSpan }()
+
+	switch stmt.GetKind() {
+	case ast_types.VariableDeclaration:
+		return c.compileVariableDeclaration(stmt.(ast.VariableDeclaration))
+	case ast_types.FunctionDeclaration:
+		return c.compileFunctionDeclaration(stmt.(ast.FunctionDeclaration))
+	case ast_types.IfStatement:
+		return c.compileIfStatement(stmt.(ast.IfStatement))
+	case ast_types.SwitchStatement:
+		return c.compileSwitchStatement(stmt.(ast.SwitchStatement))
+	case ast_types.WhileStatement:
+		return c.compileWhileStatement(stmt.(ast.WhileStatement))
+	case ast_types.ForStatement:
+		return c.compileForStatement(stmt.(ast.ForStatement))
+	case ast_types.ReturnStatement:
+		return c.compileReturnStatement(stmt.(ast.ReturnStatement))
+	case ast_types.BreakStatement:
+		return c.compileJump(stmt.(ast.BreakStatement).Label, true)
+	case ast_types.ContinueStatement:
+		return c.compileJump(stmt.(ast.ContinueStatement).Label, false)
+	case ast_types.TryStatement:
+		return c.compileTryStatement(stmt.(ast.TryStatement))
+	case ast_types.ThrowStatement:
+		if err := c.compileExpr(stmt.(ast.ThrowStatement).Argument); err != nil {
+			return err
+		}
+		c.emit(OpThrow)
+		return nil
+	case ast_types.ErrorNode:
+		return compilerErrors.ErrParsingError.At(c.span)
+	default:
+		if err := c.compileExpr(stmt); err != nil {
+			return err
+		}
+		c.emit(OpPop)
+		return nil
+	}
+}
+
+func (c *funcCompiler) compileExpr(expr ast.Expr) error {
+	prevSpan := c.span
+	c.span = expr.GetSpan()
+	defer func() { c.span = prevSpan }()
+
+	switch expr.GetKind() {
+
+	// LITERALS
+	case ast_types.Identifier:
+		return c.compileGetVariable(expr.(ast.Identifier))
+	case ast_types.NumericLiteral:
+		return c.emitConstant(interpreter_makers.MkNumber(expr.(ast.NumericLiteral).Value))
+	case ast_types.StringLiteral:
+		return c.emitConstant(interpreter_makers.MkString(expr.(ast.StringLiteral).Value))
+	case ast_types.BooleanLiteral:
+		if expr.(ast.BooleanLiteral).Value {
+			c.emit(OpTrue)
+		} else {
+			c.emit(OpFalse)
+		}
+		return nil
+	case ast_types.NullLiteral:
+		c.emit(OpNull)
+		return nil
+	case ast_types.NaNLiteral:
+		c.emit(OpNaN)
+		return nil
+	case ast_types.ArrayLiteral:
+		return c.compileArrayLiteral(expr.(ast.ArrayLiteral))
+	case ast_types.ObjectLiteral:
+		return c.compileObjectLiteral(expr.(ast.ObjectLiteral))
+	case ast_types.TemplateLiteral:
+		return c.compileTemplateLiteral(expr.(ast.TemplateLiteral))
+
+	// EXPRESSIONS
+	case ast_types.BinaryExpr:
+		return c.compileBinaryExpr(expr.(ast.BinaryExpr))
+	case ast_types.LogicalExpr:
+		return c.compileLogicalExpr(expr.(ast.LogicalExpr))
+	case ast_types.UnaryExpr:
+		return c.compileUnaryExpr(expr.(ast.UnaryExpr))
+	case ast_types.UpdateExpr:
+		return c.compileUpdateExpr(expr.(ast.UpdateExpr))
+	case ast_types.ConditionalExpr:
+		return c.compileConditionalExpr(expr.(ast.ConditionalExpr))
+	case ast_types.AssigmentExpr:
+		return c.compileAssignment(expr.(ast.AssigmentExpr))
+	case ast_types.MemberExpr:
+		return c.compileMemberExpr(expr.(ast.MemberExpr))
+	case ast_types.CallExpr:
+		return c.compileCallExpr(expr.(ast.CallExpr))
+	case ast_types.ArrowFunctionExpr:
+		arrowFn := expr.(ast.ArrowFunctionExpr)
+		return c.compileFunction("", arrowFn.Params, arrowFn.Body, true)
+
+	default:
+		return compilerErrors.ErrBytecodeUnsupportedNode.WithArgs(expr.GetKind()).At(c.span)
+	}
+}
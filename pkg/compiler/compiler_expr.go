@@ -0,0 +1,314 @@
+package compiler
+
+import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_ops"
+)
+
+func (c *funcCompiler) compileArrayLiteral(arrayExpr ast.ArrayLiteral) error {
+	if len(arrayExpr.Elements) > maxOperand {
+		return compilerErrors.ErrBytecodeTooManyConstants.At(c.span)
+	}
+
+	for _, element := range arrayExpr.Elements {
+		if err := c.compileExpr(element); err != nil {
+			return err
+		}
+	}
+
+	c.emit(OpArray, len(arrayExpr.Elements))
+	return nil
+}
+
+// Pushes the pieces of text that aren't empty and the embedded values, OpTemplate joins them
+func (c *funcCompiler) compileTemplateLiteral(template ast.TemplateLiteral) error {
+	parts := 0
+
+	for idx, quasi := range template.Quasis {
+		if quasi != "" {
+			if err := c.emitConstant(interpreter_makers.MkString(quasi)); err != nil {
+				return err
+			}
+			parts++
+		}
+
+		if idx < len(template.Expressions) {
+			if err := c.compileExpr(template.Expressions[idx]); err != nil {
+				return err
+			}
+			parts++
+		}
+	}
+
+	if parts > maxOperand {
+		return compilerErrors.ErrBytecodeTooManyConstants.At(c.span)
+	}
+
+	c.emit(OpTemplate, parts)
+	return nil
+}
+
+func (c *funcCompiler) compileObjectLiteral(objectExpr ast.ObjectLiteral) error {
+	if len(objectExpr.Properties) > maxOperand {
+		return compilerErrors.ErrBytecodeTooManyConstants.At(c.span)
+	}
+
+	for _, property := range objectExpr.Properties {
+		if err := c.emitConstant(interpreter_makers.MkString(property.Key)); err != nil {
+			return err
+		}
+
+		// The resolver gives every property without value the variable with its name
+		if err := c.compileExpr(property.Value); err != nil {
+			return err
+		}
+	}
+
+	c.emit(OpObject, len(objectExpr.Properties))
+	return nil
+}
+
+func (c *funcCompiler) compileBinaryExpr(binop ast.BinaryExpr) error {
+	op, ok := BinaryOperators[binop.Operator]
+
+	if !ok {
+		return compilerErrors.ErrBytecodeUnsupportedNode.WithArgs("the operator " + binop.Operator).At(c.span)
+	}
+
+	if err := c.compileExpr(binop.Left); err != nil {
+		return err
+	}
+
+	if err := c.compileExpr(binop.Right); err != nil {
+		return err
+	}
+
+	c.emit(op)
+	return nil
+}
+
+// Both operands are always evaluated, as in the interpreter
+func (c *funcCompiler) compileLogicalExpr(logicalExpr ast.LogicalExpr) error {
+	if err := c.compileExpr(logicalExpr.Left); err != nil {
+		return err
+	}
+
+	if err := c.compileExpr(logicalExpr.Right); err != nil {
+		return err
+	}
+
+	switch logicalExpr.Operator {
+	case "&&":
+		c.emit(OpAnd)
+	case "||":
+		c.emit(OpOr)
+	default:
+		return compilerErrors.ErrBytecodeUnsupportedNode.WithArgs("the operator " + logicalExpr.Operator).At(c.span)
+	}
+
+	return nil
+}
+
+func (c *funcCompiler) compileUnaryExpr(expr ast.UnaryExpr) error {
+	if err := c.compileExpr(expr.Argument); err != nil {
+		return err
+	}
+
+	switch expr.Operator {
+	case "!":
+		c.emit(OpNot)
+	case "-":
+		c.emit(OpNegate)
+	case "+":
+		c.emit(OpUnaryPlus)
+	case "~":
+		c.emit(OpBitwiseNot)
+	default:
+		c.emit(OpPop)
+		c.emit(OpNull)
+	}
+
+	return nil
+}
+
+func (c *funcCompiler) compileUpdateExpr(expr ast.UpdateExpr) error {
+	if err := c.compileGetVariable(expr.Argument); err != nil {
+		return err
+	}
+
+	// The postfix form evaluates to the previous value
+	if !expr.Prefix {
+		c.emit(OpDup)
+	}
+
+	switch expr.Operator {
+	case "++":
+		c.emit(OpIncrement)
+	case "--":
+		c.emit(OpDecrement)
+	default:
+		return compilerErrors.ErrSyntaxInvalidUpdateExpr.At(c.span)
+	}
+
+	if err := c.compileSetVariable(expr.Argument); err != nil {
+		return err
+	}
+
+	if !expr.Prefix {
+		c.emit(OpPop)
+	}
+
+	return nil
+}
+
+func (c *funcCompiler) compileConditionalExpr(conditionalExpr ast.ConditionalExpr) error {
+	if err := c.compileExpr(conditionalExpr.Condition); err != nil {
+		return err
+	}
+
+	elseJump := c.emitJump(OpJumpIfFalse)
+
+	if err := c.compileExpr(conditionalExpr.Consequent); err != nil {
+		return err
+	}
+
+	endJump := c.emitJump(OpJump)
+
+	if err := c.patchJump(elseJump); err != nil {
+		return err
+	}
+
+	if err := c.compileExpr(conditionalExpr.Alternate); err != nil {
+		return err
+	}
+
+	return c.patchJump(endJump)
+}
+
+func (c *funcCompiler) compileAssignment(assignment ast.AssigmentExpr) error {
+	assignmentOperator := -1
+	for idx, operator := range AssignmentOperators {
+		if operator == assignment.Operator {
+			assignmentOperator = idx
+		}
+	}
+
+	if assignmentOperator == -1 {
+		return compilerErrors.ErrSyntaxInvalidAssignment.At(c.span)
+	}
+
+	switch assignment.Assigne.GetKind() {
+	case ast_types.Identifier:
+		ident := assignment.Assigne.(ast.Identifier)
+
+		if err := c.compileExpr(assignment.Value); err != nil {
+			return err
+		}
+
+		// The value is evaluated before reading the variable, as in the interpreter
+		if operator, ok := interpreter_ops.CompoundOperator(assignment.Operator); ok {
+			if err := c.compileGetVariable(ident); err != nil {
+				return err
+			}
+			c.emit(OpSwap)
+			c.emit(BinaryOperators[operator])
+		}
+
+		return c.compileSetVariable(ident)
+	case ast_types.MemberExpr:
+		member := assignment.Assigne.(ast.MemberExpr)
+
+		if err := c.compileExpr(member.Object); err != nil {
+			return err
+		}
+
+		if member.Computed {
+			if err := c.compileExpr(member.Property); err != nil {
+				return err
+			}
+		}
+
+		if err := c.compileExpr(assignment.Value); err != nil {
+			return err
+		}
+
+		if member.Computed {
+			c.emit(OpSetIndex, assignmentOperator)
+			return nil
+		}
+
+		return c.emitName(OpSetProperty, member.Property.(ast.Identifier).Symbol, assignmentOperator)
+	default:
+		return compilerErrors.ErrSyntaxInvalidAssignment.At(c.span)
+	}
+}
+
+func (c *funcCompiler) compileMemberExpr(expr ast.MemberExpr) error {
+	if err := c.compileExpr(expr.Object); err != nil {
+		return err
+	}
+
+	if !expr.Computed {
+		return c.emitName(OpGetProperty, expr.Property.(ast.Identifier).Symbol)
+	}
+
+	if err := c.compileExpr(expr.Property); err != nil {
+		return err
+	}
+
+	c.emit(OpGetIndex)
+	return nil
+}
+
+/*
+ * The arguments are evaluated before the function. As in the interpreter,
+ * calls with the name of a native function always call the native one.
+ */
+func (c *funcCompiler) compileCallExpr(expr ast.CallExpr) error {
+	if len(expr.Args) > maxArgs {
+		return compilerErrors.ErrBytecodeTooManyArguments.At(c.span)
+	}
+
+	for _, arg := range expr.Args {
+		if err := c.compileExpr(arg); err != nil {
+			return err
+		}
+	}
+
+	if expr.Native != "" {
+		c.emit(OpCallNative, c.nativeSlot(expr.Native), len(expr.Args))
+		return nil
+	}
+
+	if err := c.compileExpr(expr.Caller); err != nil {
+		return err
+	}
+
+	idx, err := c.name(callName(expr))
+	if err != nil {
+		return err
+	}
+
+	c.emit(OpCall, len(expr.Args), idx)
+	return nil
+}
+
+// Name used to find native functions and to report errors
+func callName(expr ast.CallExpr) string {
+	switch caller := expr.Caller.(type) {
+	case ast.Identifier:
+		return caller.Symbol
+	case ast.MemberExpr:
+		if !caller.Computed {
+			return caller.Property.(ast.Identifier).Symbol
+		}
+		if property, ok := caller.Property.(ast.StringLiteral); ok {
+			return property.Value
+		}
+	}
+
+	return ""
+}
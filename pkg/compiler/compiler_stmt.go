@@ -0,0 +1,534 @@
+package compiler
+
+import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+)
+
+func (c *funcCompiler) compileVariableDeclaration(declaration ast.VariableDeclaration) error {
+	if declaration.Value != nil {
+		if err := c.compileExpr(declaration.Value); err != nil {
+			return err
+		}
+	} else {
+		c.emit(OpNull)
+	}
+
+	return c.declareVariable(declaration.Identifier, declaration.Constant)
+}
+
+// Functions are constants, the body can call the function because its slot
+// is reserved when the block starts
+func (c *funcCompiler) compileFunctionDeclaration(declaration ast.FunctionDeclaration) error {
+	if err := c.compileFunction(declaration.Name, declaration.Params, declaration.Body, false); err != nil {
+		return err
+	}
+
+	return c.declareVariable(declaration.Name, true)
+}
+
+func (c *funcCompiler) compileFunction(name string, params []ast.Identifier, body []ast.Stmt, arrow bool) error {
+	fc := &funcCompiler{
+		enclosing:  c,
+		function:   &Function{Name: name, Arity: len(params), Arrow: arrow},
+		scopeDepth: 1,
+		names:      make(map[string]int),
+		symbols:    c.symbols,
+		span:       c.span,
+	}
+	fc.scope = &varScope{parent: c.scope, owner: fc}
+
+	// The parameters and the variables of the body share a scope
+	for _, param := range params {
+		if err := fc.declareVariable(param.Symbol, false); err != nil {
+			return err
+		}
+	}
+
+	if err := fc.reserveVariables(body); err != nil {
+		return err
+	}
+
+	for _, stmt := range body {
+		if err := fc.compileStmt(stmt); err != nil {
+			return err
+		}
+	}
+
+	fc.emit(OpNull)
+	fc.emit(OpReturn)
+
+	fc.function.UpvalueCount = len(fc.upvalues)
+	for _, upvalue := range fc.upvalues {
+		fc.function.UpvalueNames = append(fc.function.UpvalueNames, upvalue.name)
+	}
+
+	chunk := c.chunk()
+	if len(chunk.Functions) > maxOperand {
+		return compilerErrors.ErrBytecodeTooManyConstants.At(c.span)
+	}
+	chunk.Functions = append(chunk.Functions, fc.function)
+
+	c.emit(OpClosure, len(chunk.Functions)-1, len(fc.upvalues))
+
+	for _, upvalue := range fc.upvalues {
+		isLocal := 0
+		if upvalue.isLocal {
+			isLocal = 1
+		}
+		chunk.write([]byte{byte(isLocal), byte(upvalue.index >> 8), byte(upvalue.index)}, c.span)
+	}
+
+	return nil
+}
+
+func (c *funcCompiler) compileBlock(body []ast.Stmt) error {
+	c.beginScope()
+	c.openVarScope()
+
+	if err := c.reserveVariables(body); err != nil {
+		return err
+	}
+
+	for _, stmt := range body {
+		if err := c.compileStmt(stmt); err != nil {
+			return err
+		}
+	}
+
+	c.closeVarScope()
+	c.endScope()
+	return nil
+}
+
+func (c *funcCompiler) compileIfStatement(declaration ast.IfStatement) error {
+	var endJumps []int
+
+	if err := c.compileExpr(declaration.Test); err != nil {
+		return err
+	}
+
+	elseJump := c.emitJump(OpJumpIfFalse)
+
+	if err := c.compileBlock(declaration.Body); err != nil {
+		return err
+	}
+
+	endJumps = append(endJumps, c.emitJump(OpJump))
+
+	if err := c.patchJump(elseJump); err != nil {
+		return err
+	}
+
+	for _, elseIfStatement := range declaration.ElseIfStmt {
+		if err := c.compileExpr(elseIfStatement.Test); err != nil {
+			return err
+		}
+
+		nextJump := c.emitJump(OpJumpIfFalse)
+
+		if err := c.compileBlock(elseIfStatement.Body); err != nil {
+			return err
+		}
+
+		endJumps = append(endJumps, c.emitJump(OpJump))
+
+		if err := c.patchJump(nextJump); err != nil {
+			return err
+		}
+	}
+
+	if err := c.compileBlock(declaration.ElseBody); err != nil {
+		return err
+	}
+
+	return c.patchJumps(endJumps)
+}
+
+// The discriminant is stored in a hidden local that every case compares with its tests
+func (c *funcCompiler) compileSwitchStatement(declaration ast.SwitchStatement) error {
+	var endJumps []int
+
+	c.beginScope()
+
+	if err := c.compileExpr(declaration.Discriminant); err != nil {
+		return err
+	}
+
+	discriminantSlot := len(c.locals)
+	if err := c.addLocal("", false); err != nil {
+		return err
+	}
+
+	for _, caseStatement := range declaration.CaseStmts {
+		var bodyJumps []int
+
+		for _, test := range caseStatement.Test {
+			c.emit(OpGetLocal, discriminantSlot)
+
+			if err := c.compileExpr(test); err != nil {
+				return err
+			}
+
+			c.emit(OpCaseMatch)
+			nextTest := c.emitJump(OpJumpIfFalse)
+			bodyJumps = append(bodyJumps, c.emitJump(OpJump))
+
+			if err := c.patchJump(nextTest); err != nil {
+				return err
+			}
+		}
+
+		nextCase := c.emitJump(OpJump)
+
+		if err := c.patchJumps(bodyJumps); err != nil {
+			return err
+		}
+
+		if err := c.compileBlock(caseStatement.Body); err != nil {
+			return err
+		}
+
+		endJumps = append(endJumps, c.emitJump(OpJump))
+
+		if err := c.patchJump(nextCase); err != nil {
+			return err
+		}
+	}
+
+	if err := c.compileBlock(declaration.DefaultStmt.Body); err != nil {
+		return err
+	}
+
+	if err := c.patchJumps(endJumps); err != nil {
+		return err
+	}
+
+	c.endScope()
+	return nil
+}
+
+func (c *funcCompiler) compileWhileStatement(declaration ast.WhileStatement) error {
+	loopStart := len(c.chunk().Code)
+
+	if err := c.compileExpr(declaration.Test); err != nil {
+		return err
+	}
+
+	exitJump := c.emitJump(OpJumpIfFalse)
+
+	c.loops = append(c.loops, &loop{label: declaration.Label, scopeDepth: c.scopeDepth, continueTarget: loopStart, tries: len(c.tries)})
+
+	if err := c.compileBlock(declaration.Body); err != nil {
+		return err
+	}
+
+	if err := c.emitLoop(loopStart); err != nil {
+		return err
+	}
+
+	if err := c.patchJump(exitJump); err != nil {
+		return err
+	}
+
+	return c.endLoop()
+}
+
+/*
+ * The variables declared in the init of the loop are locals of their own
+ * scope. At the end of every iteration the captured ones are closed, so
+ * the closures created in the iteration keep the values they had and the
+ * next iteration starts with fresh variables.
+ */
+func (c *funcCompiler) compileForStatement(declaration ast.ForStatement) error {
+	c.beginScope()
+	c.openVarScope()
+	loopLocals := len(c.locals)
+
+	if declaration.Init != nil {
+		if err := c.compileStmt(declaration.Init); err != nil {
+			return err
+		}
+	}
+
+	loopStart := len(c.chunk().Code)
+	exitJump := -1
+
+	if declaration.Test != nil {
+		if err := c.compileExpr(declaration.Test); err != nil {
+			return err
+		}
+		exitJump = c.emitJump(OpJumpIfFalse)
+	}
+
+	currentLoop := &loop{label: declaration.Label, scopeDepth: c.scopeDepth, continueTarget: -1, tries: len(c.tries)}
+	c.loops = append(c.loops, currentLoop)
+
+	if err := c.compileBlock(declaration.Body); err != nil {
+		return err
+	}
+
+	if err := c.patchJumps(currentLoop.continueJumps); err != nil {
+		return err
+	}
+
+	for _, loopLocal := range c.locals[loopLocals:] {
+		if loopLocal.captured {
+			c.emit(OpCloseUpvalues, loopLocals)
+			break
+		}
+	}
+
+	if declaration.Update != nil {
+		if err := c.compileStmt(declaration.Update); err != nil {
+			return err
+		}
+	}
+
+	if err := c.emitLoop(loopStart); err != nil {
+		return err
+	}
+
+	if exitJump != -1 {
+		if err := c.patchJump(exitJump); err != nil {
+			return err
+		}
+	}
+
+	if err := c.endLoop(); err != nil {
+		return err
+	}
+
+	c.closeVarScope()
+	c.endScope()
+	return nil
+}
+
+func (c *funcCompiler) endLoop() error {
+	currentLoop := c.loops[len(c.loops)-1]
+	c.loops = c.loops[:len(c.loops)-1]
+
+	return c.patchJumps(currentLoop.breakJumps)
+}
+
+// Compiles a break or a continue, the parser already checked that the loop exists
+func (c *funcCompiler) compileJump(label string, isBreak bool) error {
+	var target *loop
+
+	for idx := len(c.loops) - 1; idx >= 0; idx-- {
+		if label == "" || c.loops[idx].label == label {
+			target = c.loops[idx]
+			break
+		}
+	}
+
+	if target == nil {
+		if isBreak {
+			return compilerErrors.ErrLoopsBreakNotInLoop.At(c.span)
+		}
+		return compilerErrors.ErrLoopsContinueNotInLoop.At(c.span)
+	}
+
+	if err := c.leaveTries(target.tries); err != nil {
+		return err
+	}
+
+	c.discardLocals(target.scopeDepth)
+
+	if isBreak {
+		target.breakJumps = append(target.breakJumps, c.emitJump(OpJump))
+		return nil
+	}
+
+	if target.continueTarget == -1 {
+		target.continueJumps = append(target.continueJumps, c.emitJump(OpJump))
+		return nil
+	}
+
+	return c.emitLoop(target.continueTarget)
+}
+
+func (c *funcCompiler) compileReturnStatement(declaration ast.ReturnStatement) error {
+	if declaration.Argument != nil {
+		if err := c.compileExpr(declaration.Argument); err != nil {
+			return err
+		}
+	} else {
+		c.emit(OpNull)
+	}
+
+	if len(c.tries) == 0 {
+		c.emit(OpReturn)
+		return nil
+	}
+
+	// The value waits in a local while the finally blocks run
+	c.beginScope()
+	slot := len(c.locals)
+
+	if err := c.addLocal("", false); err != nil {
+		return err
+	}
+
+	if err := c.leaveTries(0); err != nil {
+		return err
+	}
+
+	c.emit(OpGetLocal, slot)
+	c.emit(OpReturn)
+	c.forgetScope()
+
+	return nil
+}
+
+/*
+ * A try statement with catch and finally blocks is compiled as a try
+ * statement with a finally block around one with a catch block.
+ */
+func (c *funcCompiler) compileTryStatement(declaration ast.TryStatement) error {
+	if declaration.Finalizer == nil {
+		return c.compileTryCatch(declaration.Block, declaration.Handler)
+	}
+
+	return c.compileTryFinally(declaration.Finalizer, func() error {
+		if declaration.Handler == nil {
+			return c.compileBlock(declaration.Block)
+		}
+		return c.compileTryCatch(declaration.Block, declaration.Handler)
+	})
+}
+
+// The VM jumps to the catch block with the caught value on the stack, where
+// it is the first local of the block
+func (c *funcCompiler) compileTryCatch(block []ast.Stmt, handler *ast.CatchClause) error {
+	handlerJump := c.emitJump(OpTry)
+
+	err := c.compileProtected(nil, func() error {
+		return c.compileBlock(block)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	endJump := c.emitJump(OpJump)
+
+	if err := c.patchJump(handlerJump); err != nil {
+		return err
+	}
+
+	c.beginScope()
+	c.openVarScope()
+
+	// The caught value is a local even if the catch block doesn't name it,
+	// the resolver only declares the parameter with a name
+	if handler.Param == "" {
+		if err := c.addLocal("", false); err != nil {
+			return err
+		}
+	} else if err := c.declareVariable(handler.Param, false); err != nil {
+		return err
+	}
+
+	if err := c.reserveVariables(handler.Body); err != nil {
+		return err
+	}
+
+	for _, stmt := range handler.Body {
+		if err := c.compileStmt(stmt); err != nil {
+			return err
+		}
+	}
+
+	c.closeVarScope()
+	c.endScope()
+
+	return c.patchJump(endJump)
+}
+
+/*
+ * The finally block is compiled twice: after the protected code, and where
+ * the VM jumps when the protected code fails. There the error is on the
+ * stack, and it is thrown again when the finally block ends.
+ */
+func (c *funcCompiler) compileTryFinally(finalizer []ast.Stmt, protected func() error) error {
+	handlerJump := c.emitJump(OpTryFinally)
+
+	if err := c.compileProtected(finalizer, protected); err != nil {
+		return err
+	}
+
+	if err := c.compileBlock(finalizer); err != nil {
+		return err
+	}
+
+	endJump := c.emitJump(OpJump)
+
+	if err := c.patchJump(handlerJump); err != nil {
+		return err
+	}
+
+	c.beginScope()
+	slot := len(c.locals)
+
+	if err := c.addLocal("", false); err != nil {
+		return err
+	}
+
+	if err := c.compileBlock(finalizer); err != nil {
+		return err
+	}
+
+	c.emit(OpGetLocal, slot)
+	c.emit(OpThrow)
+	c.forgetScope()
+
+	return c.patchJump(endJump)
+}
+
+// Compiles the code protected by the handler just emitted, removing the
+// handler when the code ends
+func (c *funcCompiler) compileProtected(finalizer []ast.Stmt, protected func() error) error {
+	c.tries = append(c.tries, &tryBlock{finalizer: finalizer, loops: len(c.loops), scope: c.scope})
+	err := protected()
+	c.tries = c.tries[:len(c.tries)-1]
+
+	if err != nil {
+		return err
+	}
+
+	c.emit(OpPopTry)
+	return nil
+}
+
+// Emits the code that leaves the try blocks from the innermost to the one at
+// index outer: it removes their handlers and runs their finally blocks
+func (c *funcCompiler) leaveTries(outer int) error {
+	tries, loops := c.tries, c.loops
+	defer func() { c.tries, c.loops = tries, loops }()
+
+	for idx := len(tries) - 1; idx >= outer; idx-- {
+		c.emit(OpPopTry)
+
+		if tries[idx].finalizer == nil {
+			continue
+		}
+
+		// The finally block runs outside of its try statement, so it only
+		// sees the try blocks and loops around it
+		c.tries = tries[:idx:idx]
+		c.loops = loops[:tries[idx].loops:tries[idx].loops]
+
+		// Its identifiers are bound to the scopes around the try statement
+		scope := c.scope
+		c.scope = tries[idx].scope
+
+		if err := c.compileBlock(tries[idx].finalizer); err != nil {
+			return err
+		}
+
+		c.scope = scope
+	}
+
+	return nil
+}
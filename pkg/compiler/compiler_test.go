@@ -0,0 +1,63 @@
+package compiler_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/compiler"
+	"github.com/Waxer59/PikaLang/pkg/parser"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       compiler.Opcode
+		operands []int
+		expected []byte
+	}{
+		{compiler.OpConstant, []int{65534}, []byte{byte(compiler.OpConstant), 255, 254}},
+		{compiler.OpAdd, []int{}, []byte{byte(compiler.OpAdd)}},
+		{compiler.OpCall, []int{2, 258}, []byte{byte(compiler.OpCall), 2, 1, 2}},
+	}
+
+	for _, test := range tests {
+		instruction := compiler.Make(test.op, test.operands...)
+
+		if !bytes.Equal(instruction, test.expected) {
+			t.Errorf("Expected %s to be encoded as %v, but got: %v", test.op, test.expected, instruction)
+		}
+
+		def, err := compiler.Lookup(test.op)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		operands, read := compiler.ReadOperands(def, instruction[1:])
+		if read != len(instruction)-1 {
+			t.Errorf("Expected to read %d bytes, but got: %d", len(instruction)-1, read)
+		}
+
+		for idx, operand := range test.operands {
+			if operands[idx] != operand {
+				t.Errorf("Expected operand %d to be %d, but got: %d", idx, operand, operands[idx])
+			}
+		}
+	}
+}
+
+func TestCompileTooManyArguments(t *testing.T) {
+	args := strings.Repeat("1, ", 256)
+	program, err := parser.New().ProduceAST("fn f() {}\nf(" + args + "1)")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = compiler.Compile(*program)
+
+	if !errors.Is(err, compilerErrors.ErrBytecodeTooManyArguments) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrBytecodeTooManyArguments, err)
+	}
+}
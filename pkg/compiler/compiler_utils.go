@@ -0,0 +1,367 @@
+package compiler
+
+import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+)
+
+// VARIABLES
+
+// Declares a variable whose value is on top of the stack, in the next slot
+// of the current scope
+func (c *funcCompiler) declareVariable(name string, constant bool) error {
+	if c.scope.owner == nil {
+		slot, err := c.globalSlot(name)
+		if err != nil {
+			return err
+		}
+
+		if constant {
+			c.emit(OpDefineConstGlobal, slot)
+		} else {
+			c.emit(OpDefineGlobal, slot)
+		}
+		return nil
+	}
+
+	scope := c.scope
+	idx := scope.declared
+	scope.declared++
+
+	if idx < len(scope.slots) {
+		c.emit(OpDefineLocal, scope.slots[idx])
+		return nil
+	}
+
+	if err := c.addLocal(name, constant); err != nil {
+		return err
+	}
+
+	scope.slots = append(scope.slots, len(c.locals)-1)
+	return nil
+}
+
+// Reserves the slots of the variables and functions declared by the
+// statements of the current scope, so the functions of the scope can
+// capture the ones declared after them
+func (c *funcCompiler) reserveVariables(body []ast.Stmt) error {
+	reserved := 0
+
+	for _, stmt := range body {
+		switch stmt.GetKind() {
+		case ast_types.VariableDeclaration:
+			declaration := stmt.(ast.VariableDeclaration)
+			if err := c.addLocal(declaration.Identifier, declaration.Constant); err != nil {
+				return err
+			}
+		case ast_types.FunctionDeclaration:
+			if err := c.addLocal(stmt.(ast.FunctionDeclaration).Name, true); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+
+		c.scope.slots = append(c.scope.slots, len(c.locals)-1)
+		reserved++
+	}
+
+	if reserved > 0 {
+		c.emit(OpReserve, reserved)
+	}
+
+	return nil
+}
+
+func (c *funcCompiler) openVarScope() {
+	c.scope = &varScope{parent: c.scope, owner: c}
+}
+
+func (c *funcCompiler) closeVarScope() {
+	c.scope = c.scope.parent
+}
+
+// The variable an identifier is bound to, the owner is nil for the globals
+func (c *funcCompiler) resolveVariable(ident ast.Identifier) (*funcCompiler, int, error) {
+	scope := c.scope
+	for depth := 0; depth < ident.Depth && scope != nil; depth++ {
+		scope = scope.parent
+	}
+
+	if scope == nil {
+		return nil, 0, compilerErrors.ErrVariableDoesNotExist.WithArgs(ident.Symbol).At(ident.Span)
+	}
+
+	if scope.owner == nil {
+		return nil, 0, nil
+	}
+
+	if ident.Slot >= len(scope.slots) {
+		return nil, 0, compilerErrors.ErrVariableDoesNotExist.WithArgs(ident.Symbol).At(ident.Span)
+	}
+
+	return scope.owner, scope.slots[ident.Slot], nil
+}
+
+func (c *funcCompiler) compileGetVariable(ident ast.Identifier) error {
+	owner, slot, err := c.resolveVariable(ident)
+	if err != nil {
+		return err
+	}
+
+	switch owner {
+	case nil:
+		slot, err := c.globalSlot(ident.Symbol)
+		if err != nil {
+			return err
+		}
+
+		c.emit(OpGetGlobal, slot)
+	case c:
+		c.emit(OpGetLocal, slot)
+	default:
+		c.emit(OpGetUpvalue, c.capture(owner, slot))
+	}
+
+	return nil
+}
+
+// Assigns the value on top of the stack, leaving it there
+func (c *funcCompiler) compileSetVariable(ident ast.Identifier) error {
+	owner, slot, err := c.resolveVariable(ident)
+	if err != nil {
+		return err
+	}
+
+	switch owner {
+	case nil:
+		slot, err := c.globalSlot(ident.Symbol)
+		if err != nil {
+			return err
+		}
+
+		c.emit(OpSetGlobal, slot)
+	case c:
+		if c.locals[slot].constant {
+			return c.emitName(OpAssignConstant, ident.Symbol)
+		}
+		c.emit(OpSetLocal, slot)
+	default:
+		idx := c.capture(owner, slot)
+		if c.upvalues[idx].constant {
+			return c.emitName(OpAssignConstant, ident.Symbol)
+		}
+		c.emit(OpSetUpvalue, idx)
+	}
+
+	return nil
+}
+
+// Returns the upvalue of a local of an enclosing function, capturing it in
+// every function between them
+func (c *funcCompiler) capture(owner *funcCompiler, slot int) int {
+	if c.enclosing == owner {
+		local := &owner.locals[slot]
+		local.captured = true
+		return c.addUpvalue(slot, true, local.constant, local.name)
+	}
+
+	idx := c.enclosing.capture(owner, slot)
+	enclosing := c.enclosing.upvalues[idx]
+	return c.addUpvalue(idx, false, enclosing.constant, enclosing.name)
+}
+
+func (c *funcCompiler) addUpvalue(index int, isLocal bool, constant bool, name string) int {
+	for idx, upvalue := range c.upvalues {
+		if upvalue.index == index && upvalue.isLocal == isLocal {
+			return idx
+		}
+	}
+
+	c.upvalues = append(c.upvalues, upvalue{index: index, isLocal: isLocal, constant: constant, name: name})
+	return len(c.upvalues) - 1
+}
+
+func (c *funcCompiler) addLocal(name string, constant bool) error {
+	if len(c.locals) > maxOperand {
+		return compilerErrors.ErrBytecodeTooManyLocals.At(c.span)
+	}
+
+	c.locals = append(c.locals, local{name: name, depth: c.scopeDepth, constant: constant})
+	return nil
+}
+
+func (c *funcCompiler) globalSlot(name string) (int, error) {
+	if slot, ok := c.symbols.globals[name]; ok {
+		return slot, nil
+	}
+
+	if len(c.symbols.globalNames) > maxOperand {
+		return 0, compilerErrors.ErrBytecodeTooManyConstants.At(c.span)
+	}
+
+	slot := len(c.symbols.globalNames)
+	c.symbols.globals[name] = slot
+	c.symbols.globalNames = append(c.symbols.globalNames, name)
+
+	return slot, nil
+}
+
+func (c *funcCompiler) nativeSlot(name string) int {
+	if slot, ok := c.symbols.natives[name]; ok {
+		return slot
+	}
+
+	slot := len(c.symbols.nativeNames)
+	c.symbols.natives[name] = slot
+	c.symbols.nativeNames = append(c.symbols.nativeNames, name)
+
+	return slot
+}
+
+// SCOPES
+
+func (c *funcCompiler) beginScope() {
+	c.scopeDepth++
+}
+
+func (c *funcCompiler) endScope() {
+	c.discardLocals(c.scopeDepth - 1)
+	c.forgetScope()
+}
+
+// Ends a scope after code that returns or throws, its locals don't have to
+// be removed from the stack because the code after it never runs
+func (c *funcCompiler) forgetScope() {
+	c.scopeDepth--
+
+	for len(c.locals) > 0 && c.locals[len(c.locals)-1].depth > c.scopeDepth {
+		c.locals = c.locals[:len(c.locals)-1]
+	}
+}
+
+// Emits the code that removes from the stack the locals deeper than depth
+func (c *funcCompiler) discardLocals(depth int) {
+	pending := 0
+
+	flush := func() {
+		switch {
+		case pending == 1:
+			c.emit(OpPop)
+		case pending > 1:
+			c.emit(OpPopN, pending)
+		}
+		pending = 0
+	}
+
+	for idx := len(c.locals) - 1; idx >= 0 && c.locals[idx].depth > depth; idx-- {
+		if c.locals[idx].captured {
+			flush()
+			c.emit(OpCloseUpvalue)
+		} else {
+			pending++
+		}
+	}
+
+	flush()
+}
+
+// EMISSION
+
+func (c *funcCompiler) chunk() *Chunk {
+	return &c.function.Chunk
+}
+
+func (c *funcCompiler) emit(op Opcode, operands ...int) int {
+	return c.chunk().write(Make(op, operands...), c.span)
+}
+
+func (c *funcCompiler) emitConstant(value interpreter_env.RuntimeValue) error {
+	idx, err := c.addConstant(value)
+	if err != nil {
+		return err
+	}
+
+	c.emit(OpConstant, idx)
+	return nil
+}
+
+func (c *funcCompiler) addConstant(value interpreter_env.RuntimeValue) (int, error) {
+	chunk := c.chunk()
+
+	if len(chunk.Constants) > maxOperand {
+		return 0, compilerErrors.ErrBytecodeTooManyConstants.At(c.span)
+	}
+
+	chunk.Constants = append(chunk.Constants, value)
+	return len(chunk.Constants) - 1, nil
+}
+
+// Returns the index of a string constant, reusing the same constant for every use of a name
+func (c *funcCompiler) name(name string) (int, error) {
+	if idx, ok := c.names[name]; ok {
+		return idx, nil
+	}
+
+	idx, err := c.addConstant(interpreter_makers.MkString(name))
+	if err != nil {
+		return 0, err
+	}
+
+	c.names[name] = idx
+	return idx, nil
+}
+
+// Emits an instruction whose first operand is a name
+func (c *funcCompiler) emitName(op Opcode, name string, operands ...int) error {
+	idx, err := c.name(name)
+	if err != nil {
+		return err
+	}
+
+	c.emit(op, append([]int{idx}, operands...)...)
+	return nil
+}
+
+// Emits a jump whose offset is set later by patchJump
+func (c *funcCompiler) emitJump(op Opcode) int {
+	return c.emit(op, maxOperand)
+}
+
+func (c *funcCompiler) patchJump(offset int) error {
+	code := c.chunk().Code
+	jump := len(code) - offset - 3
+
+	if jump > maxOperand {
+		return compilerErrors.ErrBytecodeJumpTooLarge.At(c.span)
+	}
+
+	code[offset+1] = byte(jump >> 8)
+	code[offset+2] = byte(jump)
+
+	return nil
+}
+
+func (c *funcCompiler) patchJumps(offsets []int) error {
+	for _, offset := range offsets {
+		if err := c.patchJump(offset); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *funcCompiler) emitLoop(loopStart int) error {
+	jump := len(c.chunk().Code) + 3 - loopStart
+
+	if jump > maxOperand {
+		return compilerErrors.ErrBytecodeJumpTooLarge.At(c.span)
+	}
+
+	c.emit(OpLoop, jump)
+	return nil
+}
@@ -0,0 +1,261 @@
+package compiler
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+type Opcode byte
+
+const (
+	OpConstant Opcode = iota
+	OpNull
+	OpTrue
+	OpFalse
+	OpNaN
+	OpPop
+	OpPopN
+	OpReserve
+	OpDup
+	OpSwap
+
+	// VARIABLES
+	OpGetLocal
+	OpSetLocal
+	OpDefineLocal
+	OpGetUpvalue
+	OpSetUpvalue
+	OpGetGlobal
+	OpSetGlobal
+	OpDefineGlobal
+	OpDefineConstGlobal
+	OpCloseUpvalue
+	OpCloseUpvalues
+	OpAssignConstant
+
+	// ARRAYS & OBJECTS
+	OpArray
+	OpObject
+	OpTemplate
+	OpGetProperty
+	OpGetIndex
+	OpSetProperty
+	OpSetIndex
+
+	// OPERATORS
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpModulo
+	OpPower
+	OpBitwiseAnd
+	OpBitwiseOr
+	OpBitwiseXor
+	OpShiftLeft
+	OpShiftRight
+	OpUnsignedShiftRight
+	OpEqual
+	OpNotEqual
+	OpLess
+	OpLessEqual
+	OpGreater
+	OpGreaterEqual
+	OpAnd
+	OpOr
+	OpNot
+	OpNegate
+	OpUnaryPlus
+	OpBitwiseNot
+	OpIncrement
+	OpDecrement
+	OpCaseMatch
+
+	// CONTROL FLOW
+	OpJump
+	OpJumpIfFalse
+	OpLoop
+	OpCall
+	OpCallNative
+	OpClosure
+	OpReturn
+
+	// EXCEPTIONS
+	OpTry
+	OpTryFinally
+	OpPopTry
+	OpThrow
+)
+
+type Definition struct {
+	Name string
+	// Size in bytes of every operand
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant:           {"OpConstant", []int{2}},
+	OpNull:               {"OpNull", []int{}},
+	OpTrue:               {"OpTrue", []int{}},
+	OpFalse:              {"OpFalse", []int{}},
+	OpNaN:                {"OpNaN", []int{}},
+	OpPop:                {"OpPop", []int{}},
+	OpPopN:               {"OpPopN", []int{2}},
+	OpReserve:            {"OpReserve", []int{2}},
+	OpDup:                {"OpDup", []int{}},
+	OpSwap:               {"OpSwap", []int{}},
+	OpGetLocal:           {"OpGetLocal", []int{2}},
+	OpSetLocal:           {"OpSetLocal", []int{2}},
+	OpDefineLocal:        {"OpDefineLocal", []int{2}},
+	OpGetUpvalue:         {"OpGetUpvalue", []int{2}},
+	OpSetUpvalue:         {"OpSetUpvalue", []int{2}},
+	OpGetGlobal:          {"OpGetGlobal", []int{2}},
+	OpSetGlobal:          {"OpSetGlobal", []int{2}},
+	OpDefineGlobal:       {"OpDefineGlobal", []int{2}},
+	OpDefineConstGlobal:  {"OpDefineConstGlobal", []int{2}},
+	OpCloseUpvalue:       {"OpCloseUpvalue", []int{}},
+	OpCloseUpvalues:      {"OpCloseUpvalues", []int{2}},
+	OpAssignConstant:     {"OpAssignConstant", []int{2}},
+	OpArray:              {"OpArray", []int{2}},
+	OpTemplate:           {"OpTemplate", []int{2}},
+	OpObject:             {"OpObject", []int{2}},
+	OpGetProperty:        {"OpGetProperty", []int{2}},
+	OpGetIndex:           {"OpGetIndex", []int{}},
+	OpSetProperty:        {"OpSetProperty", []int{2, 1}},
+	OpSetIndex:           {"OpSetIndex", []int{1}},
+	OpAdd:                {"OpAdd", []int{}},
+	OpSubtract:           {"OpSubtract", []int{}},
+	OpMultiply:           {"OpMultiply", []int{}},
+	OpDivide:             {"OpDivide", []int{}},
+	OpModulo:             {"OpModulo", []int{}},
+	OpPower:              {"OpPower", []int{}},
+	OpBitwiseAnd:         {"OpBitwiseAnd", []int{}},
+	OpBitwiseOr:          {"OpBitwiseOr", []int{}},
+	OpBitwiseXor:         {"OpBitwiseXor", []int{}},
+	OpShiftLeft:          {"OpShiftLeft", []int{}},
+	OpShiftRight:         {"OpShiftRight", []int{}},
+	OpUnsignedShiftRight: {"OpUnsignedShiftRight", []int{}},
+	OpEqual:              {"OpEqual", []int{}},
+	OpNotEqual:           {"OpNotEqual", []int{}},
+	OpLess:               {"OpLess", []int{}},
+	OpLessEqual:          {"OpLessEqual", []int{}},
+	OpGreater:            {"OpGreater", []int{}},
+	OpGreaterEqual:       {"OpGreaterEqual", []int{}},
+	OpAnd:                {"OpAnd", []int{}},
+	OpOr:                 {"OpOr", []int{}},
+	OpNot:                {"OpNot", []int{}},
+	OpNegate:             {"OpNegate", []int{}},
+	OpUnaryPlus:          {"OpUnaryPlus", []int{}},
+	OpBitwiseNot:         {"OpBitwiseNot", []int{}},
+	OpIncrement:          {"OpIncrement", []int{}},
+	OpDecrement:          {"OpDecrement", []int{}},
+	OpCaseMatch:          {"OpCaseMatch", []int{}},
+	OpJump:               {"OpJump", []int{2}},
+	OpJumpIfFalse:        {"OpJumpIfFalse", []int{2}},
+	OpLoop:               {"OpLoop", []int{2}},
+	OpCall:               {"OpCall", []int{1, 2}},
+	OpCallNative:         {"OpCallNative", []int{2, 1}},
+	OpClosure:            {"OpClosure", []int{2, 2}},
+	OpReturn:             {"OpReturn", []int{}},
+	OpTry:                {"OpTry", []int{2}},
+	OpTryFinally:         {"OpTryFinally", []int{2}},
+	OpPopTry:             {"OpPopTry", []int{}},
+	OpThrow:              {"OpThrow", []int{}},
+}
+
+// Binary operators of the language and the opcode that applies them
+var BinaryOperators = map[string]Opcode{
+	"+":   OpAdd,
+	"-":   OpSubtract,
+	"*":   OpMultiply,
+	"/":   OpDivide,
+	"%":   OpModulo,
+	"**":  OpPower,
+	"&":   OpBitwiseAnd,
+	"|":   OpBitwiseOr,
+	"^":   OpBitwiseXor,
+	"<<":  OpShiftLeft,
+	">>":  OpShiftRight,
+	">>>": OpUnsignedShiftRight,
+	"==":  OpEqual,
+	"!=":  OpNotEqual,
+	"<":   OpLess,
+	"<=":  OpLessEqual,
+	">":   OpGreater,
+	">=":  OpGreaterEqual,
+}
+
+// Operators of the OpSetProperty and OpSetIndex instructions, encoded as their position
+var AssignmentOperators = []string{"=", "+=", "-=", "*=", "/=", "%=", "**=", "&=", "|=", "^=", "<<=", ">>=", ">>>="}
+
+func (op Opcode) String() string {
+	if def, ok := definitions[op]; ok {
+		return def.Name
+	}
+	return fmt.Sprintf("Opcode(%d)", byte(op))
+}
+
+func Lookup(op Opcode) (*Definition, error) {
+	def, ok := definitions[op]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+
+	return def, nil
+}
+
+// Encodes an instruction, operands are written in big endian
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	instructionLen := 1
+	for _, width := range def.OperandWidths {
+		instructionLen += width
+	}
+
+	instruction := make([]byte, instructionLen)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for idx, operand := range operands {
+		if idx >= len(def.OperandWidths) {
+			break
+		}
+
+		width := def.OperandWidths[idx]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(operand))
+		case 1:
+			instruction[offset] = byte(operand)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// Decodes the operands of an instruction, the second return value is the number of bytes read
+func ReadOperands(def *Definition, ins []byte) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for idx, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[idx] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[idx] = int(ins[offset])
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+func ReadUint16(ins []byte) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
@@ -0,0 +1,154 @@
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+	Note
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "WARNING"
+	case Note:
+		return "NOTE"
+	default:
+		return "ERROR"
+	}
+}
+
+type Category string
+
+const (
+	Syntax  Category = "syntax"
+	Runtime Category = "runtime"
+	Type    Category = "type"
+)
+
+// Diagnostic is an error reported by the lexer, the parser or the interpreter.
+// Two diagnostics are considered the same by errors.Is when they share the
+// same code, so the catalogue values can be used as sentinels.
+type Diagnostic struct {
+	Code     string
+	Severity Severity
+	Category Category
+	Message  string
+	Span     token_type.Span
+	Notes    []string
+	Help     string
+	// Error that caused the diagnostic, like the error returned by a native function
+	Cause error
+	// Function calls that were running when a runtime error was reported, the innermost goes first
+	Stack []Frame
+}
+
+func New(code string, category Category, message string) *Diagnostic {
+	return &Diagnostic{
+		Code:     code,
+		Severity: Error,
+		Category: category,
+		Message:  message,
+	}
+}
+
+func (d *Diagnostic) Error() string {
+	msg := fmt.Sprintf("%s[%s]: %s", d.Severity, d.Code, d.Message)
+
+	if d.HasSpan() {
+		msg += " (" + d.Location() + ")"
+	}
+
+	return msg
+}
+
+func (d *Diagnostic) Is(target error) bool {
+	t, ok := target.(*Diagnostic)
+	return ok && t.Code == d.Code
+}
+
+func (d *Diagnostic) Unwrap() error {
+	return d.Cause
+}
+
+// Returns true if the diagnostic points to a location in the source code
+func (d *Diagnostic) HasSpan() bool {
+	return d.Span.Start.Line > 0
+}
+
+// Returns the location of the diagnostic as file:line:column
+func (d *Diagnostic) Location() string {
+	return location(d.Span)
+}
+
+func location(span token_type.Span) string {
+	var parts []string
+
+	if span.File != "" {
+		parts = append(parts, span.File)
+	}
+
+	parts = append(parts, fmt.Sprint(span.Start.Line), fmt.Sprint(span.Start.Column))
+
+	return strings.Join(parts, ":")
+}
+
+// Returns a copy of the diagnostic pointing to the given span
+func (d *Diagnostic) At(span token_type.Span) *Diagnostic {
+	diag := d.clone()
+	diag.Span = span
+	return diag
+}
+
+// Returns a copy of the diagnostic with the message formatted with the given args
+func (d *Diagnostic) WithArgs(args ...any) *Diagnostic {
+	diag := d.clone()
+	diag.Message = fmt.Sprintf(d.Message, args...)
+	return diag
+}
+
+// Returns a copy of the diagnostic with an extra note
+func (d *Diagnostic) WithNote(note string) *Diagnostic {
+	diag := d.clone()
+	diag.Notes = append(diag.Notes, note)
+	return diag
+}
+
+// Returns a copy of the diagnostic with the given help text
+func (d *Diagnostic) WithHelp(help string) *Diagnostic {
+	diag := d.clone()
+	diag.Help = help
+	return diag
+}
+
+// Returns a copy of the diagnostic caused by err, errors.Is and errors.As look into it
+func (d *Diagnostic) WithCause(err error) *Diagnostic {
+	diag := d.clone()
+	diag.Cause = err
+	return diag
+}
+
+func (d *Diagnostic) clone() *Diagnostic {
+	diag := *d
+	diag.Notes = append([]string(nil), d.Notes...)
+	return &diag
+}
+
+// Attaches the span to err if it is a diagnostic that doesn't point anywhere yet
+func WithSpan(err error, span token_type.Span) error {
+	diag, ok := err.(*Diagnostic)
+
+	if !ok || diag.HasSpan() {
+		return err
+	}
+
+	return diag.At(span)
+}
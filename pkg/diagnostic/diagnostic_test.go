@@ -0,0 +1,136 @@
+package diagnostic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+var errTest = New("P9999", Runtime, "Variable does not exist in this scope: %s")
+
+func TestDiagnosticError(t *testing.T) {
+	span := token_type.Span{
+		File:  "main.pk",
+		Start: token_type.Position{Offset: 4, Line: 2, Column: 3},
+		End:   token_type.Position{Offset: 5, Line: 2, Column: 4},
+	}
+
+	tests := []struct {
+		diag     *Diagnostic
+		expected string
+	}{
+		{errTest, "ERROR[P9999]: Variable does not exist in this scope: %s"},
+		{errTest.WithArgs("x"), "ERROR[P9999]: Variable does not exist in this scope: x"},
+		{errTest.WithArgs("x").At(span), "ERROR[P9999]: Variable does not exist in this scope: x (main.pk:2:3)"},
+	}
+
+	for _, test := range tests {
+		if test.diag.Error() != test.expected {
+			t.Errorf("Expected %q, but got %q", test.expected, test.diag.Error())
+		}
+	}
+}
+
+func TestDiagnosticMatching(t *testing.T) {
+	err := fmt.Errorf("running script: %w", errTest.WithArgs("x").WithNote("declared here").At(token_type.Span{}))
+
+	if !errors.Is(err, errTest) {
+		t.Errorf("Expected %v to match %v", err, errTest)
+	}
+
+	if errors.Is(err, New("P0000", Runtime, "Other")) {
+		t.Errorf("Expected %v to only match its own code", err)
+	}
+
+	var diag *Diagnostic
+	if !errors.As(err, &diag) {
+		t.Fatalf("Expected %v to be a diagnostic", err)
+	}
+
+	if diag.Code != "P9999" || diag.Category != Runtime || len(diag.Notes) != 1 {
+		t.Errorf("Unexpected diagnostic: %+v", diag)
+	}
+
+	if len(errTest.Notes) != 0 {
+		t.Errorf("Expected the sentinel to be left untouched, but got notes: %v", errTest.Notes)
+	}
+}
+
+func TestWithSpan(t *testing.T) {
+	first := token_type.Span{Start: token_type.Position{Line: 1, Column: 1}}
+	second := token_type.Span{Start: token_type.Position{Line: 5, Column: 1}}
+
+	err := WithSpan(WithSpan(errTest, first), second)
+
+	if err.(*Diagnostic).Span != first {
+		t.Errorf("Expected the first span to be kept, but got %v", err.(*Diagnostic).Span)
+	}
+
+	plain := errors.New("plain")
+	if WithSpan(plain, first) != plain {
+		t.Errorf("Expected non diagnostic errors to be returned untouched")
+	}
+}
+
+func TestWithCause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := errTest.WithArgs("x").WithCause(cause)
+
+	if !errors.Is(err, cause) || !errors.Is(err, errTest) {
+		t.Errorf("Expected %v to match both its code and its cause", err)
+	}
+
+	if errTest.Cause != nil {
+		t.Errorf("Expected the sentinel to be left untouched, but got cause: %v", errTest.Cause)
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	at := func(line int) token_type.Span {
+		return token_type.Span{File: "main.pk", Start: token_type.Position{Line: line, Column: 3}}
+	}
+
+	calls := make([]Frame, 30)
+	for idx := range calls {
+		calls[idx] = Frame{Function: "loop", CallSite: at(2)}
+	}
+
+	tests := []struct {
+		name     string
+		diag     *Diagnostic
+		expected string
+	}{
+		{"outside of functions", errTest.At(at(1)), "at <main> (main.pk:1:3)"},
+		{
+			"nested calls",
+			errTest.At(at(2)).WithStack([]Frame{{Function: "fail", CallSite: at(5)}, {Function: AnonymousFunction, CallSite: at(9)}}),
+			"at fail (main.pk:2:3)\nat <anonymous> (main.pk:5:3)\nat <main> (main.pk:9:3)",
+		},
+		{
+			"called by the host",
+			errTest.At(at(2)).WithStack([]Frame{{Function: "fail", CallSite: at(5)}, {Function: "handler"}}),
+			"at fail (main.pk:2:3)\nat handler (main.pk:5:3)",
+		},
+		{
+			"deep recursion",
+			errTest.At(at(2)).WithStack(calls),
+			strings.Repeat("at loop (main.pk:2:3)\n", 10) + "... 11 more calls\n" + strings.Repeat("at loop (main.pk:2:3)\n", 9) + "at <main> (main.pk:2:3)",
+		},
+	}
+
+	for _, test := range tests {
+		if trace := test.diag.StackTrace(); trace != test.expected {
+			t.Errorf("Expected the stack trace of %s to be:\n%s\nbut got:\n%s", test.name, test.expected, trace)
+		}
+	}
+
+	stack := []Frame{{Function: "fail"}}
+	err := WithStack(WithStack(errTest, func() []Frame { return stack }), func() []Frame { return nil })
+
+	if len(err.(*Diagnostic).Stack) != 1 {
+		t.Errorf("Expected the first stack to be kept, but got %v", err.(*Diagnostic).Stack)
+	}
+}
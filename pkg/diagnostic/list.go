@@ -0,0 +1,27 @@
+package diagnostic
+
+import "strings"
+
+// List is an error made of several diagnostics, it is returned by the
+// parser so every syntax error of a file can be reported at once
+type List []*Diagnostic
+
+func (l List) Error() string {
+	messages := make([]string, len(l))
+
+	for idx, diag := range l {
+		messages[idx] = diag.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (l List) Unwrap() []error {
+	errs := make([]error, len(l))
+
+	for idx, diag := range l {
+		errs[idx] = diag
+	}
+
+	return errs
+}
@@ -0,0 +1,94 @@
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+// Name of the frames of functions without a name, like arrow functions
+const AnonymousFunction = "<anonymous>"
+
+// Name given in stack traces to the code outside of every function
+const MainFunction = "<main>"
+
+// Frames shown at each end of a stack trace too long to be shown whole
+const shownFrames = 10
+
+// Frame is a function call that was running when a diagnostic was reported
+type Frame struct {
+	// Name of the called function
+	Function string
+	// Where the function was called, it doesn't point anywhere for the
+	// functions called by the host or by a native function
+	CallSite token_type.Span
+}
+
+// Returns a copy of the diagnostic with the calls that were running when it
+// was reported, the innermost call goes first
+func (d *Diagnostic) WithStack(stack []Frame) *Diagnostic {
+	diag := d.clone()
+	diag.Stack = stack
+	return diag
+}
+
+/*
+ * Returns the stack trace of the diagnostic, one line for every running
+ * function with where it was when the diagnostic was reported:
+ *
+ * at fail (main.pk:2:5)
+ * at <anonymous> (main.pk:6:12)
+ * at <main> (main.pk:9:1)
+ */
+func (d *Diagnostic) StackTrace() string {
+	var lines []string
+
+	for idx, frame := range d.Stack {
+		span := d.Span
+		if idx > 0 {
+			span = d.Stack[idx-1].CallSite
+		}
+
+		lines = append(lines, traceLine(frame.Function, span))
+	}
+
+	if len(d.Stack) == 0 {
+		lines = append(lines, traceLine(MainFunction, d.Span))
+	} else if callSite := d.Stack[len(d.Stack)-1].CallSite; callSite.Start.Line > 0 {
+		lines = append(lines, traceLine(MainFunction, callSite))
+	}
+
+	if len(lines) > 2*shownFrames {
+		hidden := len(lines) - 2*shownFrames
+		lines = append(append(lines[:shownFrames:shownFrames], fmt.Sprintf("... %d more calls", hidden)), lines[len(lines)-shownFrames:]...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func traceLine(function string, span token_type.Span) string {
+	if span.Start.Line == 0 {
+		return "at " + function
+	}
+
+	return fmt.Sprintf("at %s (%s)", function, location(span))
+}
+
+// Attaches the stack to err if it is a diagnostic without one. The stack is
+// only built when it is attached, so it can be called on every error.
+func WithStack(err error, stack func() []Frame) error {
+	diag, ok := err.(*Diagnostic)
+
+	if !ok || diag.Stack != nil {
+		return err
+	}
+
+	frames := stack()
+
+	if len(frames) == 0 {
+		return err
+	}
+
+	return diag.WithStack(frames)
+}
@@ -1,68 +1,232 @@
 package interpreter_env
 
 import (
-	"errors"
+	"context"
 
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 )
 
+// A scope of variables, scopes are shared by pointer so every closure
+// created inside of a scope sees the changes made to it afterwards.
+//
+// Variables are stored by slot in the order they are declared, the resolver
+// gives every identifier the slot of its variable and the number of scopes
+// to go up to reach it. A slot whose value is nil is reserved for a variable
+// that hasn't been declared yet.
 type Environment struct {
 	parent    *Environment
-	variables map[string]any
-	constants map[string]any
+	realm     *Realm
+	names     []string
+	values    []RuntimeValue
+	constants []bool
+	// Slots reserved by Reserve for the variables with these names
+	reserved map[string]int
 }
 
-func New(parentENV *Environment) Environment {
-	return Environment{
-		parent:    parentENV,
-		variables: make(map[string]any),
-		constants: make(map[string]any),
+// State shared by every scope of a runtime
+type Realm struct {
+	HostFunctions *HostFunctions
+	// Groups of native functions the programs can call
+	Capabilities *Capabilities
+	// Streams of the console functions
+	Stdio *Stdio
+	// Limits of the runs started by the host
+	Limits Limits
+	// Limits of the program being run, programs run outside of Run only have the default call depth limit
+	Guard   *Guard
+	running bool
+}
+
+func NewRealm() *Realm {
+	return &Realm{
+		HostFunctions: NewHostFunctions(),
+		Capabilities:  DefaultCapabilities(),
+		Stdio:         DefaultStdio(),
+		Guard:         NewGuard(context.Background(), Limits{}),
 	}
 }
 
-func (e *Environment) DeclareVar(varName string, value RuntimeValue, constant bool) (RuntimeValue, error) {
+/*
+ * Runs fn with a guard for the given context and limits. A run started
+ * while another one is running, like a host function calling back into the
+ * program, is part of the outer run and shares its limits.
+ */
+func (r *Realm) Run(ctx context.Context, limits Limits, fn func() (RuntimeValue, error)) (RuntimeValue, error) {
+	if r.running {
+		return fn()
+	}
+
+	prevGuard := r.Guard
+	r.Guard = NewGuard(ctx, limits)
+	r.running = true
 
-	if _, ok := e.variables[varName]; ok {
-		return nil, errors.New(compilerErrors.ErrVariableAlreadyExists + varName)
+	defer func() {
+		r.Guard = prevGuard
+		r.running = false
+	}()
+
+	if err := r.Guard.Check(); err != nil {
+		return nil, err
 	}
 
-	e.variables[varName] = value
+	return fn()
+}
+
+// Returns a new scope inside of parentENV, a nil parent gives a global scope
+// with a realm of its own
+func New(parentENV *Environment) *Environment {
+	if parentENV == nil {
+		return NewGlobal(NewRealm())
+	}
 
-	if constant {
-		e.constants[varName] = value
+	return &Environment{
+		parent: parentENV,
+		realm:  parentENV.realm,
 	}
+}
 
-	return value, nil
+// Returns a global scope whose programs run in the given realm
+func NewGlobal(realm *Realm) *Environment {
+	return &Environment{
+		realm: realm,
+	}
 }
 
-func (e *Environment) AssignVar(varName string, value RuntimeValue) (RuntimeValue, error) {
-	if _, ok := e.constants[varName]; ok {
-		return nil, errors.New(compilerErrors.ErrVariableIsConstant + varName)
+func (e *Environment) Realm() *Realm {
+	return e.realm
+}
+
+// Returns a new scope with the same parent and a copy of the variables of e,
+// used to give every iteration of a for loop its own loop variables
+func (e *Environment) Copy() *Environment {
+	return &Environment{
+		parent:    e.parent,
+		realm:     e.realm,
+		names:     append([]string(nil), e.names...),
+		values:    append([]RuntimeValue(nil), e.values...),
+		constants: append([]bool(nil), e.constants...),
+	}
+}
+
+/*
+ * Takes the next slot of the scope for a variable that will be declared
+ * later, the variable doesn't exist until DeclareVar is called with its name.
+ * The resolver gives the global variables of a program their slots before it
+ * runs, reserving them keeps those slots from being given to the variables of
+ * the next program if this one stops before declaring them.
+ */
+func (e *Environment) Reserve(varName string, constant bool) {
+	if e.reserved == nil {
+		e.reserved = make(map[string]int)
+	}
+
+	e.reserved[varName] = len(e.values)
+	e.names = append(e.names, varName)
+	e.values = append(e.values, nil)
+	e.constants = append(e.constants, constant)
+}
+
+// Declares a variable in the slot reserved for it or in the next slot of the scope
+func (e *Environment) DeclareVar(varName string, value RuntimeValue, constant bool) RuntimeValue {
+	if slot, ok := e.reserved[varName]; ok {
+		delete(e.reserved, varName)
+		e.values[slot] = value
+		e.constants[slot] = constant
+
+		return value
 	}
 
-	env, err := e.Resolve(varName)
-	env.variables[varName] = value
+	e.names = append(e.names, varName)
+	e.values = append(e.values, value)
+	e.constants = append(e.constants, constant)
 
-	return value, err
+	return value
 }
 
-// Returns the environment that contains the variable
-func (e *Environment) Resolve(varName string) (Environment, error) {
-	if _, ok := e.variables[varName]; ok {
-		return *e, nil
+func (e *Environment) ancestor(depth int) *Environment {
+	env := e
+
+	for ; depth > 0; depth-- {
+		env = env.parent
 	}
 
-	if e.parent == nil {
-		return *e, errors.New(compilerErrors.ErrVariableDoesNotExist + varName)
+	return env
+}
+
+// Returns the variable in the given slot of the scope depth levels up, the
+// second return value is false if the variable isn't declared yet
+func (e *Environment) Get(depth int, slot int) (RuntimeValue, bool) {
+	env := e.ancestor(depth)
+
+	if slot >= len(env.values) || env.values[slot] == nil {
+		return nil, false
+	}
+
+	return env.values[slot], true
+}
+
+// Changes the variable in the given slot of the scope depth levels up, it
+// returns false if the variable isn't declared yet
+func (e *Environment) Set(depth int, slot int, value RuntimeValue) bool {
+	env := e.ancestor(depth)
+
+	if slot >= len(env.values) || env.values[slot] == nil {
+		return false
+	}
+
+	env.values[slot] = value
+
+	return true
+}
+
+// Returns the names of the variables of the scope ordered by slot
+func (e *Environment) Names() []string {
+	return e.names
+}
+
+// Returns false if the slot is reserved for a variable that isn't declared yet
+func (e *Environment) IsDeclared(slot int) bool {
+	return e.values[slot] != nil
+}
+
+func (e *Environment) IsConstant(slot int) bool {
+	return e.constants[slot]
+}
+
+func (e *Environment) AssignVar(varName string, value RuntimeValue) (RuntimeValue, error) {
+	env, slot, err := e.Resolve(varName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if env.constants[slot] {
+		return nil, compilerErrors.ErrVariableIsConstant.WithArgs(varName)
+	}
+
+	env.values[slot] = value
+
+	return value, nil
+}
+
+// Returns the environment that contains the variable and its slot, used when
+// the variable isn't known by the resolver
+func (e *Environment) Resolve(varName string) (*Environment, int, error) {
+	for env := e; env != nil; env = env.parent {
+		for slot, name := range env.names {
+			if name == varName && env.values[slot] != nil {
+				return env, slot, nil
+			}
+		}
 	}
 
-	return e.parent.Resolve(varName)
+	return nil, 0, compilerErrors.ErrVariableDoesNotExist.WithArgs(varName)
 }
 
 func (e *Environment) LookupVar(varName string) (RuntimeValue, error) {
-	env, err := e.Resolve(varName)
+	env, slot, err := e.Resolve(varName)
 	if err != nil {
 		return nil, err
 	}
-	return env.variables[varName].(RuntimeValue), nil
+	return env.values[slot], nil
 }
@@ -0,0 +1,203 @@
+package interpreter_env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+)
+
+// A group of native functions that reach outside of the program
+type Capability string
+
+const (
+	Console Capability = "console"
+	FS      Capability = "fs"
+	Env     Capability = "env"
+	Time    Capability = "time"
+	Random  Capability = "random"
+	Process Capability = "process"
+)
+
+var capabilities = []Capability{Console, FS, Env, Time, Random, Process}
+
+// The groups of native functions the programs of a realm can call, the ones
+// that only compute can always be called. The fs functions can use every
+// directory when FS is allowed with Allow, or only some of them when it is
+// allowed with AllowFS.
+type Capabilities struct {
+	allowed map[Capability]bool
+	// Directories the fs functions can use, any directory if it is nil
+	fsRoots []string
+}
+
+// Returns the capabilities of a new runtime: the console, the time and the
+// random numbers, but not the file system, the environment or the process
+func DefaultCapabilities() *Capabilities {
+	return &Capabilities{
+		allowed: map[Capability]bool{Console: true, Time: true, Random: true},
+	}
+}
+
+// Returns capabilities that don't allow anything, the programs can only compute
+func NoCapabilities() *Capabilities {
+	return &Capabilities{allowed: map[Capability]bool{}}
+}
+
+// Returns the capability with the given name
+func ParseCapability(name string) (Capability, error) {
+	for _, capability := range capabilities {
+		if string(capability) == name {
+			return capability, nil
+		}
+	}
+
+	return "", compilerErrors.ErrUnknownCapability.WithArgs(name)
+}
+
+// Allowing FS this way lets the fs functions use every directory, even if
+// AllowFS limited them to some directories before
+func (c *Capabilities) Allow(capabilities ...Capability) {
+	for _, capability := range capabilities {
+		c.allowed[capability] = true
+
+		if capability == FS {
+			c.fsRoots = nil
+		}
+	}
+}
+
+// Denying FS forgets the directories allowed with AllowFS
+func (c *Capabilities) Deny(capabilities ...Capability) {
+	for _, capability := range capabilities {
+		delete(c.allowed, capability)
+
+		if capability == FS {
+			c.fsRoots = nil
+		}
+	}
+}
+
+// Returns capabilities that allow the same as c and can be changed without changing c
+func (c *Capabilities) Copy() *Capabilities {
+	allowed := make(map[Capability]bool, len(c.allowed))
+
+	for capability, isAllowed := range c.allowed {
+		allowed[capability] = isAllowed
+	}
+
+	var fsRoots []string
+	if c.fsRoots != nil {
+		fsRoots = append([]string{}, c.fsRoots...)
+	}
+
+	return &Capabilities{allowed: allowed, fsRoots: fsRoots}
+}
+
+func (c *Capabilities) Allows(capability Capability) bool {
+	return c.allowed[capability]
+}
+
+// Allows the fs functions to use the given directories and everything inside
+// of them, at least one directory has to be given
+func (c *Capabilities) AllowFS(dirs ...string) error {
+	if len(dirs) == 0 {
+		return compilerErrors.ErrNoFSDirectories
+	}
+
+	roots := make([]string, 0, len(dirs))
+
+	for _, dir := range dirs {
+		root, err := resolvePath(dir)
+
+		if err != nil {
+			return err
+		}
+
+		roots = append(roots, root)
+	}
+
+	c.allowed[FS] = true
+	c.fsRoots = append(c.fsRoots, roots...)
+
+	return nil
+}
+
+/*
+ * Returns the absolute path the fs function fnName can use for path, or a
+ * permission error if fs isn't allowed or the path is outside of the
+ * allowed directories. Symbolic links are followed, so a link can't point
+ * outside of them.
+ */
+func (c *Capabilities) CheckPath(fnName string, path string) (string, error) {
+	if !c.Allows(FS) {
+		return "", compilerErrors.ErrPermissionDenied.WithArgs(fnName, FS)
+	}
+
+	resolved, err := resolvePath(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	if c.fsRoots == nil {
+		return resolved, nil
+	}
+
+	for _, root := range c.fsRoots {
+		rel, err := filepath.Rel(root, resolved)
+
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", compilerErrors.ErrPathNotAllowed.WithArgs(fnName, path)
+}
+
+// Returns the absolute path with the links of its deepest existing directory resolved
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	existing := abs
+	var rest []string
+
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+
+		parent := filepath.Dir(existing)
+
+		if parent == existing {
+			return abs, nil
+		}
+
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(append([]string{resolved}, rest...)...), nil
+}
+
+// Error of a program stopped by the exit function of the process capability,
+// the host decides what to do with the code
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("The program exited with code %d", e.Code)
+}
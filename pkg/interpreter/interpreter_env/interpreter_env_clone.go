@@ -0,0 +1,108 @@
+package interpreter_env
+
+import "context"
+
+/*
+ * Returns a copy of the global scope e with a realm of its own. Every value
+ * reachable from its variables is copied: arrays, objects and the scopes
+ * closures were declared in, keeping the references they share and the
+ * cycles they form. The copy and e can run at the same time, in different
+ * goroutines, without seeing the changes of each other.
+ *
+ * Host functions are shared, they are Go code the copy can't look into.
+ */
+func (e *Environment) Clone() *Environment {
+	realm := &Realm{
+		HostFunctions: e.realm.HostFunctions.Copy(),
+		Capabilities:  e.realm.Capabilities.Copy(),
+		Stdio:         e.realm.Stdio.Copy(),
+		Limits:        e.realm.Limits,
+		Guard:         NewGuard(context.Background(), Limits{}),
+	}
+
+	c := &cloner{
+		realm:  realm,
+		envs:   make(map[*Environment]*Environment),
+		values: make(map[RuntimeValue]RuntimeValue),
+	}
+
+	return c.env(e)
+}
+
+// Copies scopes and values into a realm, remembering the copies already made
+// so shared references stay shared
+type cloner struct {
+	realm  *Realm
+	envs   map[*Environment]*Environment
+	values map[RuntimeValue]RuntimeValue
+}
+
+func (c *cloner) env(e *Environment) *Environment {
+	if e == nil {
+		return nil
+	}
+
+	if clone, ok := c.envs[e]; ok {
+		return clone
+	}
+
+	clone := &Environment{
+		realm:     c.realm,
+		names:     append([]string(nil), e.names...),
+		values:    make([]RuntimeValue, len(e.values)),
+		constants: append([]bool(nil), e.constants...),
+	}
+	c.envs[e] = clone
+	clone.parent = c.env(e.parent)
+
+	if e.reserved != nil {
+		clone.reserved = make(map[string]int, len(e.reserved))
+
+		for name, slot := range e.reserved {
+			clone.reserved[name] = slot
+		}
+	}
+
+	for slot, value := range e.values {
+		clone.values[slot] = c.value(value)
+	}
+
+	return clone
+}
+
+func (c *cloner) value(value RuntimeValue) RuntimeValue {
+	switch val := value.(type) {
+	case *ArrayVal:
+		if clone, ok := c.values[val]; ok {
+			return clone
+		}
+
+		clone := &ArrayVal{Type: val.Type, Elements: make([]RuntimeValue, len(val.Elements))}
+		c.values[val] = clone
+
+		for idx, el := range val.Elements {
+			clone.Elements[idx] = c.value(el)
+		}
+
+		return clone
+	case *ObjectVal:
+		if clone, ok := c.values[val]; ok {
+			return clone
+		}
+
+		clone := &ObjectVal{Type: val.Type, Properties: make(map[string]RuntimeValue, len(val.Properties))}
+		c.values[val] = clone
+
+		for key, property := range val.Properties {
+			clone.Properties[key] = c.value(property)
+		}
+
+		return clone
+	case FunctionVal:
+		val.DeclarationEnv = c.env(val.DeclarationEnv)
+		return val
+	}
+
+	// The rest of values can't be changed, so they can be shared
+	return value
+}
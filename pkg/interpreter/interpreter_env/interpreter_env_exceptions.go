@@ -0,0 +1,104 @@
+package interpreter_env
+
+import (
+	"errors"
+	"fmt"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+)
+
+// A value thrown by a program, it goes up through the calls as the cause of
+// an uncaught exception error until a catch takes it
+type Exception struct {
+	Value RuntimeValue
+}
+
+func (e *Exception) Error() string {
+	return describeValue(e.Value)
+}
+
+// Returns the error of a throw statement with the given value
+func Throw(value RuntimeValue) error {
+	return compilerErrors.ErrUncaughtException.WithArgs(describeValue(value)).WithCause(&Exception{Value: value})
+}
+
+// Errors that stop the program whatever it does, catch and finally blocks don't run for them
+var uncatchableErrors = []error{
+	compilerErrors.ErrCanceled,
+	compilerErrors.ErrTimeout,
+	compilerErrors.ErrStepLimitExceeded,
+	compilerErrors.ErrStackOverflow,
+	compilerErrors.ErrMemoryLimit,
+}
+
+// Returns false for the errors of the limits of the run and for exit
+func Catchable(err error) bool {
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return false
+	}
+
+	for _, uncatchable := range uncatchableErrors {
+		if errors.Is(err, uncatchable) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+ * Returns the value a catch block gets for err. It is the thrown value for
+ * the errors of throw statements, and an object with the message, the code
+ * and the stack of the error for the errors of the language.
+ */
+func CaughtValue(err error) RuntimeValue {
+	var exception *Exception
+	if errors.As(err, &exception) {
+		return exception.Value
+	}
+
+	message := err.Error()
+	code := ""
+	stack := ""
+
+	var diag *diagnostic.Diagnostic
+	if errors.As(err, &diag) {
+		message = diag.Message
+		code = diag.Code
+
+		if diag.HasSpan() {
+			stack = diag.StackTrace()
+		}
+	}
+
+	return &ObjectVal{
+		Type: Object,
+		Properties: map[string]RuntimeValue{
+			"message": StringVal{Type: String, Value: message},
+			"code":    StringVal{Type: String, Value: code},
+			"stack":   StringVal{Type: String, Value: stack},
+		},
+	}
+}
+
+// Describes a thrown value in the message of its error, objects are
+// described by their message property
+func describeValue(value RuntimeValue) string {
+	switch val := value.(type) {
+	case StringVal:
+		return val.Value
+	case *ObjectVal:
+		if message, ok := val.Properties["message"].(StringVal); ok {
+			return message.Value
+		}
+		return "object"
+	case *ArrayVal:
+		return "array"
+	case FunctionVal, *HostFunction:
+		return "function"
+	}
+
+	return fmt.Sprint(value.GetValue())
+}
@@ -0,0 +1,136 @@
+package interpreter_env
+
+import (
+	"context"
+	"time"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+)
+
+// Nested calls allowed when the limits don't set it, deeper recursion would
+// use up the stack of the Go program running the interpreter
+const DefaultMaxCallDepth = 10000
+
+// The context and the clock are checked every this many steps, checking them
+// is much slower than counting
+const checkInterval = 1024
+
+// Limits of a run of a program, a zero field doesn't limit the run
+type Limits struct {
+	// Number of nodes of the program that can be evaluated
+	MaxSteps int
+	// Number of nested function calls, DefaultMaxCallDepth if it is zero
+	MaxCallDepth int
+	// How long the program can run
+	Timeout time.Duration
+	// Estimated bytes of the arrays, objects and strings the program can
+	// create. Memory of the values dropped by the program isn't given back,
+	// so it limits everything the program allocates.
+	MaxMemory int
+}
+
+// Stops the program being run in a realm when its context is done or when it
+// reaches one of its limits
+type Guard struct {
+	ctx      context.Context
+	limits   Limits
+	deadline time.Time
+	steps    int
+	memory   int
+	// Function calls being run, the innermost goes last
+	frames []diagnostic.Frame
+}
+
+func NewGuard(ctx context.Context, limits Limits) *Guard {
+	guard := &Guard{ctx: ctx, limits: limits}
+
+	if guard.limits.MaxCallDepth == 0 {
+		guard.limits.MaxCallDepth = DefaultMaxCallDepth
+	}
+
+	if limits.Timeout > 0 {
+		guard.deadline = time.Now().Add(limits.Timeout)
+	}
+
+	return guard
+}
+
+// Counts a step of the program
+func (g *Guard) Step() error {
+	g.steps++
+
+	if g.limits.MaxSteps > 0 && g.steps > g.limits.MaxSteps {
+		return compilerErrors.ErrStepLimitExceeded.WithArgs(g.limits.MaxSteps)
+	}
+
+	if g.steps%checkInterval == 0 {
+		return g.Check()
+	}
+
+	return nil
+}
+
+// Returns an error if the context is done or the program ran out of time
+func (g *Guard) Check() error {
+	if err := g.ctx.Err(); err != nil {
+		return compilerErrors.ErrCanceled.WithArgs(err).WithCause(err)
+	}
+
+	if !g.deadline.IsZero() && time.Now().After(g.deadline) {
+		return compilerErrors.ErrTimeout.WithArgs(g.limits.Timeout).WithCause(context.DeadlineExceeded)
+	}
+
+	return nil
+}
+
+// Counts a call of a function, every call has to be followed by LeaveCall once the function returns
+func (g *Guard) EnterCall(frame diagnostic.Frame) error {
+	if len(g.frames) >= g.limits.MaxCallDepth {
+		return compilerErrors.ErrStackOverflow.WithArgs(g.limits.MaxCallDepth)
+	}
+
+	g.frames = append(g.frames, frame)
+	return nil
+}
+
+// Returns an error if a new call would go past the call depth limit when
+// depth calls are running, for the VM, which keeps its own call frames
+func (g *Guard) CheckCallDepth(depth int) error {
+	if depth >= g.limits.MaxCallDepth {
+		return compilerErrors.ErrStackOverflow.WithArgs(g.limits.MaxCallDepth)
+	}
+
+	return nil
+}
+
+func (g *Guard) LeaveCall() {
+	g.frames = g.frames[:len(g.frames)-1]
+}
+
+// Returns the function calls being run, the innermost goes first
+func (g *Guard) Stack() []diagnostic.Frame {
+	stack := make([]diagnostic.Frame, len(g.frames))
+
+	for idx, frame := range g.frames {
+		stack[len(stack)-1-idx] = frame
+	}
+
+	return stack
+}
+
+// Counts the memory of a value created by the program. A nil guard doesn't
+// limit anything, so code shared with the VM can take one.
+func (g *Guard) Allocate(bytes int) error {
+	if g == nil {
+		return nil
+	}
+
+	g.memory += bytes
+
+	if g.limits.MaxMemory > 0 && g.memory > g.limits.MaxMemory {
+		return compilerErrors.ErrMemoryLimit.WithArgs(g.limits.MaxMemory)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package interpreter_env
+
+import (
+	"errors"
+	"strings"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+)
+
+// Type of the parameters of host functions that accept every value
+const Any ValueType = "any"
+
+// A function written in Go that the scripts of a runtime can call. The name
+// can have a namespace, "db.query" is called from scripts as db.query(...)
+type HostFunction struct {
+	Name string
+	// Types of the parameters, the arguments are checked before calling Handler
+	Params []ValueType
+	// The last parameter takes the rest of the arguments. A variadic function
+	// without parameters takes any number of arguments of any type, one that
+	// isn't variadic takes no arguments.
+	Variadic bool
+	Handler  func(args []RuntimeValue) (RuntimeValue, error)
+}
+
+// Host functions are values too, so Go can hand them to scripts as callbacks
+func (fn *HostFunction) GetType() ValueType {
+	return Function
+}
+
+func (fn *HostFunction) GetValue() any {
+	return fn
+}
+
+// Checks the arguments against the signature of the function and calls it
+func (fn *HostFunction) Call(args []RuntimeValue) (RuntimeValue, error) {
+	paramsNumber := len(fn.Params)
+
+	if fn.Variadic && len(args) < paramsNumber-1 || !fn.Variadic && len(args) < paramsNumber {
+		return nil, compilerErrors.ErrNotEnoughArguments.WithArgs(fn.Name)
+	} else if !fn.Variadic && len(args) > paramsNumber {
+		return nil, compilerErrors.ErrTooManyArguments.WithArgs(fn.Name)
+	}
+
+	for idx, arg := range args {
+		if paramsNumber == 0 {
+			break
+		}
+
+		paramType := fn.Params[min(idx, paramsNumber-1)]
+
+		if !acceptsType(paramType, arg.GetType()) {
+			return nil, compilerErrors.ErrNativeArgumentType.WithArgs(idx+1, fn.Name, paramType, arg.GetType())
+		}
+	}
+
+	result, err := fn.Handler(args)
+
+	if err != nil {
+		var diag *diagnostic.Diagnostic
+		if errors.As(err, &diag) {
+			return nil, err
+		}
+		return nil, compilerErrors.ErrNativeFunctionFailed.WithArgs(fn.Name, err).WithCause(err)
+	}
+
+	if result == nil {
+		return NullVal{Type: Null, Value: "null"}, nil
+	}
+
+	return result, nil
+}
+
+func acceptsType(paramType ValueType, argType ValueType) bool {
+	switch paramType {
+	case Any, argType:
+		return true
+	case Function:
+		return argType == ArrowFunction
+	}
+
+	return false
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// The host functions registered in a runtime
+type HostFunctions struct {
+	functions  map[string]*HostFunction
+	namespaces map[string]bool
+}
+
+func NewHostFunctions() *HostFunctions {
+	return &HostFunctions{
+		functions:  make(map[string]*HostFunction),
+		namespaces: make(map[string]bool),
+	}
+}
+
+/*
+ * Adds a function to the registry. The name is an identifier, optionally
+ * preceded by a namespace and a dot. A name can't be registered twice and
+ * a namespace can't have the name of a function.
+ */
+func (h *HostFunctions) Register(fn HostFunction) error {
+	parts := strings.Split(fn.Name, ".")
+
+	if len(parts) > 2 || fn.Handler == nil {
+		return compilerErrors.ErrNativeInvalidName.WithArgs(fn.Name)
+	}
+
+	for _, part := range parts {
+		if !isIdentifier(part) {
+			return compilerErrors.ErrNativeInvalidName.WithArgs(fn.Name)
+		}
+	}
+
+	if _, ok := h.functions[fn.Name]; ok || h.namespaces[fn.Name] {
+		return compilerErrors.ErrNativeAlreadyRegistered.WithArgs(fn.Name)
+	}
+
+	if len(parts) == 2 {
+		if _, ok := h.functions[parts[0]]; ok {
+			return compilerErrors.ErrNativeAlreadyRegistered.WithArgs(parts[0])
+		}
+		h.namespaces[parts[0]] = true
+	}
+
+	h.functions[fn.Name] = &fn
+
+	return nil
+}
+
+// Returns a registry with the same functions that can be changed without changing h
+func (h *HostFunctions) Copy() *HostFunctions {
+	registry := NewHostFunctions()
+
+	for name, fn := range h.functions {
+		registry.functions[name] = fn
+	}
+
+	for namespace := range h.namespaces {
+		registry.namespaces[namespace] = true
+	}
+
+	return registry
+}
+
+func (h *HostFunctions) Lookup(name string) (*HostFunction, bool) {
+	fn, ok := h.functions[name]
+	return fn, ok
+}
+
+func (h *HostFunctions) Has(name string) bool {
+	_, ok := h.functions[name]
+	return ok
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for idx, char := range name {
+		isLetter := char == '_' || char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+		isDigit := char >= '0' && char <= '9'
+
+		if !isLetter && (idx == 0 || !isDigit) {
+			return false
+		}
+	}
+
+	return true
+}
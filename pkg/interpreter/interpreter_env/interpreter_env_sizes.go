@@ -0,0 +1,37 @@
+package interpreter_env
+
+// Estimated sizes in bytes of the values created by a program, they follow
+// the layout of the Go values behind them
+const (
+	// A value stored in an array or a variable
+	SlotSize   = 16
+	stringSize = 16
+	arraySize  = 32
+	objectSize = 48
+	// A map entry holds a string header and a value
+	PropertySize = 48
+)
+
+/*
+ * Returns the memory held by value itself. The elements of arrays and the
+ * properties of objects count as slots, the values in them are counted when
+ * they are created.
+ */
+func SizeOf(value RuntimeValue) int {
+	switch val := value.(type) {
+	case StringVal:
+		return stringSize + len(val.Value)
+	case *ArrayVal:
+		return arraySize + len(val.Elements)*SlotSize
+	case *ObjectVal:
+		size := objectSize
+
+		for key := range val.Properties {
+			size += PropertySize + len(key)
+		}
+
+		return size
+	}
+
+	return 0
+}
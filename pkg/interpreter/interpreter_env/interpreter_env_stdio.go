@@ -0,0 +1,47 @@
+package interpreter_env
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// The streams the console functions of a realm read from and write to
+type Stdio struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+	// Lines are read through a reader kept with the streams so the input it
+	// buffers isn't lost between reads
+	reader *bufio.Reader
+	source io.Reader
+}
+
+// Returns the streams of the process
+func DefaultStdio() *Stdio {
+	return &Stdio{In: os.Stdin, Out: os.Stdout, Err: os.Stderr}
+}
+
+// Returns streams with the same reader and writers, the input already
+// buffered by s stays with s
+func (s *Stdio) Copy() *Stdio {
+	return &Stdio{In: s.In, Out: s.Out, Err: s.Err}
+}
+
+// Reads a line of In without its line ending, the last line of the input
+// doesn't need one
+func (s *Stdio) ReadLine() (string, error) {
+	if s.reader == nil || s.source != s.In {
+		s.reader = bufio.NewReader(s.In)
+		s.source = s.In
+	}
+
+	line, err := s.reader.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
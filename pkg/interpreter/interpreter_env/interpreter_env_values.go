@@ -62,20 +62,21 @@ func (n NumberVal) GetValue() any {
 	return n.Value
 }
 
+// Objects are shared by reference, every copy of the pointer sees the changes
 type ObjectVal struct {
 	Type       ValueType
 	Properties map[string]RuntimeValue
 }
 
-func (o ObjectVal) GetType() ValueType {
+func (o *ObjectVal) GetType() ValueType {
 	return o.Type
 }
 
-func (o ObjectVal) GetValue() any {
+func (o *ObjectVal) GetValue() any {
 	return o.Properties
 }
 
-type FunctionCall = func(args []RuntimeValue, env Environment) RuntimeValue
+type FunctionCall = func(args []RuntimeValue, env *Environment) RuntimeValue
 
 type FunctionVal struct {
 	Type           ValueType
@@ -119,24 +120,32 @@ func (n NaNVal) GetValue() any {
 	return n.Value
 }
 
+// Arrays are shared by reference, every copy of the pointer sees the changes
 type ArrayVal struct {
 	Type     ValueType
 	Elements []RuntimeValue
 }
 
-func (a ArrayVal) GetType() ValueType {
+func (a *ArrayVal) GetType() ValueType {
 	return a.Type
 }
 
-func (a ArrayVal) GetValue() any {
+func (a *ArrayVal) GetValue() any {
 	return a.Elements
 }
 
-func (a ArrayVal) GetElements() []any {
-	arr := make([]any, len(a.Elements))
-	for idx, element := range a.Elements {
-		arr[idx] = element.GetValue()
+// Arrays and objects are equal only if they are the same reference,
+// the rest of values are compared by value
+func Equals(a RuntimeValue, b RuntimeValue) bool {
+	switch a.(type) {
+	case *ArrayVal, *ObjectVal:
+		return a == b
 	}
 
-	return arr
+	switch b.(type) {
+	case *ArrayVal, *ObjectVal:
+		return false
+	}
+
+	return a.GetValue() == b.GetValue()
 }
@@ -0,0 +1,45 @@
+package interpreter_eval
+
+import (
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+type CompletionType int
+
+const (
+	NormalCompletion CompletionType = iota
+	ReturnCompletion
+	BreakCompletion
+	ContinueCompletion
+)
+
+/*
+ * Completion describes how a statement finished running. Abrupt completions
+ * (return, break and continue) travel up through the enclosing blocks until
+ * they reach the loop or function that handles them, they are not errors.
+ */
+type Completion struct {
+	Type  CompletionType
+	Value interpreter_env.RuntimeValue // Last evaluated value or the returned one
+	Label string                       // Loop targeted by a labeled break or continue
+	Span  token_type.Span              // Statement that caused an abrupt completion
+}
+
+func normalCompletion(value interpreter_env.RuntimeValue) Completion {
+	if value == nil {
+		value = interpreter_makers.MkNull()
+	}
+
+	return Completion{Type: NormalCompletion, Value: value}
+}
+
+func (c Completion) IsAbrupt() bool {
+	return c.Type != NormalCompletion
+}
+
+// Returns true if the break or continue completion has to be handled by the loop with the given label
+func (c Completion) targets(label string) bool {
+	return c.Label == "" || c.Label == label
+}
@@ -1,21 +1,19 @@
 package interpreter_eval
 
 import (
-	"errors"
-	"fmt"
-	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval/internal/nativeFns"
-	"math"
+	"strings"
 
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
 	"github.com/Waxer59/PikaLang/pkg/ast"
-	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
-
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
-	"golang.org/x/exp/slices"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_ops"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 )
 
-func evalCallExpr(expr ast.CallExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+func evalCallExpr(expr ast.CallExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	args := make([]interpreter_env.RuntimeValue, len(expr.Args))
 
 	for idx, arg := range expr.Args {
@@ -26,150 +24,151 @@ func evalCallExpr(expr ast.CallExpr, env interpreter_env.Environment) (interpret
 		args[idx] = eval
 	}
 
-	fnName, err := GetFunctionName(expr, env)
+	if expr.Native != "" {
+		return callNative(expr.Native, args, env, expr.Span)
+	}
+
+	fnName := GetFunctionName(expr)
+
+	fn, err := Evaluate(expr.Caller, env)
 
 	if err != nil {
 		return nil, err
 	}
 
-	nativeFn, isNativeFn := IsNativeFunction(fnName)
+	return callValue(fn, fnName, args, expr.Span)
+}
+
+/*
+ * Calls a function value with the given arguments, like a callback given to
+ * a native function or handed to the host. It can be a function of the
+ * program or a host function.
+ */
+func CallValue(fn interpreter_env.RuntimeValue, args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	return callValue(fn, valueName(fn), args, token_type.Span{})
+}
 
-	if isNativeFn {
-		result := nativeFn(args, env)
-		return result, nil
+// Calls fn from the given position of the program, a call from outside of the program has an empty span
+func callValue(fn interpreter_env.RuntimeValue, fnName string, args []interpreter_env.RuntimeValue, callSite token_type.Span) (interpreter_env.RuntimeValue, error) {
+	switch function := fn.(type) {
+	case interpreter_env.FunctionVal:
+		return callFunction(function, fnName, args, callSite)
+	case *interpreter_env.HostFunction:
+		return function.Call(args)
 	}
 
-	fn, err := Evaluate(expr.Caller, env)
+	return nil, compilerErrors.ErrNotAFunction.WithArgs(fnName)
+}
 
-	if err != nil {
+// Returns the name used to report errors of calls to a value that has no name in the source
+func valueName(fn interpreter_env.RuntimeValue) string {
+	switch function := fn.(type) {
+	case interpreter_env.FunctionVal:
+		if function.Name != nil {
+			return *function.Name
+		}
+		return "anonymous function"
+	case *interpreter_env.HostFunction:
+		return function.Name
+	case nil:
+		return "null"
+	}
+
+	return string(fn.GetType())
+}
+
+/*
+ * Runs a function with the given arguments in a new scope whose parent is
+ * the scope where the function was declared, the name is only used to
+ * report errors.
+ */
+func CallFunction(function interpreter_env.FunctionVal, fnName string, args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	return callFunction(function, fnName, args, token_type.Span{})
+}
+
+func callFunction(function interpreter_env.FunctionVal, fnName string, args []interpreter_env.RuntimeValue, callSite token_type.Span) (interpreter_env.RuntimeValue, error) {
+	guard := function.DeclarationEnv.Realm().Guard
+
+	frame := diagnostic.Frame{Function: diagnostic.AnonymousFunction, CallSite: callSite}
+	if function.Name != nil {
+		frame.Function = *function.Name
+	}
+
+	if err := guard.EnterCall(frame); err != nil {
 		return nil, err
 	}
+	defer guard.LeaveCall()
 
-	function := fn.(interpreter_env.FunctionVal)
 	scope := interpreter_env.New(function.DeclarationEnv)
 
 	paramsNumber := len(function.Params)
 
 	if paramsNumber > len(args) {
-		return nil, errors.New(compilerErrors.ErrNotEnoughArguments + fnName)
+		return nil, compilerErrors.ErrNotEnoughArguments.WithArgs(fnName)
 	} else if paramsNumber < len(args) {
-		return nil, errors.New(compilerErrors.ErrTooManyArguments + fnName)
+		return nil, compilerErrors.ErrTooManyArguments.WithArgs(fnName)
 	}
 
 	// Create the variables for the function arguments
 	for idx, arg := range function.Params {
-		_, err := scope.DeclareVar(arg.Symbol, args[idx], false)
-		if err != nil {
-			return nil, err
-		}
+		scope.DeclareVar(arg.Symbol, args[idx], false)
 	}
 
-	// Evaluate the function body line by line
-	for _, statement := range function.Body {
-		eval, err := Evaluate(statement, scope)
-		if err.Error() == compilerErrors.ErrReturn { // Return statement
-			return eval, nil
-		}
-		if err != nil {
-			return nil, err
-		}
+	completion, err := EvaluateBodyStmt(function.Body, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if completion.Type == ReturnCompletion {
+		return completion.Value, nil
 	}
 
 	return interpreter_makers.MkNull(), nil
 }
 
-func evalMemberExpr(expr ast.MemberExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	property := expr.Property
-
-	evalObj, err := Evaluate(expr.Object, env)
+func evalMemberExpr(expr ast.MemberExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	obj, err := Evaluate(expr.Object, env)
 
 	if err != nil {
 		return nil, err
 	}
 
-	valObj := evalObj.GetValue()
-
 	if !expr.Computed {
-		valProperty := fmt.Sprint(property.(ast.Identifier).Symbol)
-		val, ok := valObj.(map[string]interpreter_env.RuntimeValue)[valProperty]
-
-		// If the property doesn't exist return null
-		if !ok {
-			return interpreter_makers.MkNull(), nil
-		}
-		return val, nil
+		return interpreter_ops.GetProperty(obj, expr.Property.(ast.Identifier).Symbol)
 	}
 
-	evalProperty, err := Evaluate(property, env)
+	evalProperty, err := Evaluate(expr.Property, env)
 
 	if err != nil {
 		return nil, err
 	}
 
-	parseIndex := func(idx int, length int) (int, error) {
-		isNegative := idx < 0
-
-		if isNegative {
-			idx = length + idx
-		}
-
-		isOutOfBounds := idx < 0 || idx > length
-
-		if isOutOfBounds {
-			return 0, errors.New(compilerErrors.ErrInvalidIndex)
-		}
-		if idx >= length {
-			return 0, errors.New(compilerErrors.ErrIndexNotFound)
-		}
-
-		return idx, nil
-	}
-
-	switch obj := valObj.(type) {
-	case []interpreter_env.RuntimeValue:
-		if evalProperty.GetType() != interpreter_env.Number {
-			return nil, errors.New(compilerErrors.ErrInvalidIndex)
-		}
-		number := evalProperty.GetValue().(float64)
-
-		idx, err := parseIndex(int(number), len(obj))
-
-		if err != nil {
-			return nil, err
-		}
+	return interpreter_ops.GetIndex(obj, evalProperty)
+}
 
-		val := obj[idx]
-		return val, nil
-	case string:
-		if evalProperty.GetType() != interpreter_env.Number {
-			return nil, errors.New(compilerErrors.ErrInvalidIndex)
-		}
-		number := evalProperty.GetValue().(float64)
+// Joins the text of the template with the embedded values, converted like string() does
+func evalTemplateLiteral(template ast.TemplateLiteral, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	var text strings.Builder
 
-		idx, err := parseIndex(int(number), len(obj))
+	for idx, quasi := range template.Quasis {
+		text.WriteString(quasi)
 
-		if err != nil {
-			return nil, err
-		}
+		if idx < len(template.Expressions) {
+			eval, err := Evaluate(template.Expressions[idx], env)
+			if err != nil {
+				return nil, err
+			}
 
-		val := obj[idx]
-		return interpreter_makers.MkString(string(val)), nil
-	case map[string]interpreter_env.RuntimeValue:
-		valProperty := fmt.Sprint(evalProperty.GetValue())
-		if _, ok := obj[valProperty]; ok {
-			return obj[valProperty], nil
+			text.WriteString(nativeFns.ToString(eval))
 		}
-		return nil, errors.New(compilerErrors.ErrPropertyNotFound)
 	}
 
-	return nil, errors.New(compilerErrors.ErrIndexNotFound)
+	return allocated(interpreter_makers.MkString(text.String()), env)
 }
 
-func evalArrayExpr(arrayExpr ast.ArrayLiteral, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	arr := interpreter_env.ArrayVal{
-		Type:     interpreter_env.Array,
-		Elements: make([]interpreter_env.RuntimeValue, len(arrayExpr.Elements)),
-	}
+func evalArrayExpr(arrayExpr ast.ArrayLiteral, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	elements := make([]interpreter_env.RuntimeValue, len(arrayExpr.Elements))
 
 	for idx, element := range arrayExpr.Elements {
 		eval, err := Evaluate(element, env)
@@ -178,185 +177,137 @@ func evalArrayExpr(arrayExpr ast.ArrayLiteral, env interpreter_env.Environment)
 			return nil, err
 		}
 
-		if element.GetKind() == ast_types.Identifier {
-			eval, err = env.LookupVar(eval.GetValue().(string))
-
-			if err != nil {
-				return nil, err
-			}
-
-		}
-
-		arr.Elements[idx] = eval
+		elements[idx] = eval
 	}
 
-	return arr, nil
+	return allocated(interpreter_makers.MkArray(elements), env)
 }
 
-func evalArrowFunctionExpr(funcExpr ast.ArrowFunctionExpr) (interpreter_env.RuntimeValue, error) {
+func evalArrowFunctionExpr(funcExpr ast.ArrowFunctionExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 
 	arrowFn := interpreter_env.FunctionVal{
 		Type:           interpreter_env.ArrowFunction,
 		Name:           nil,
 		Params:         funcExpr.Params,
-		DeclarationEnv: nil,
+		DeclarationEnv: env,
 		Body:           funcExpr.Body,
 	}
 
 	return arrowFn, nil
 }
 
-func evalObjectExpr(objectExpr ast.ObjectLiteral, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	obj := interpreter_env.ObjectVal{
-		Type:       interpreter_env.Object,
-		Properties: make(map[string]interpreter_env.RuntimeValue),
-	}
+func evalObjectExpr(objectExpr ast.ObjectLiteral, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	obj := interpreter_makers.MkObject(make(map[string]interpreter_env.RuntimeValue))
 
+	// The resolver gives every property without value the variable with its name
 	for _, property := range objectExpr.Properties {
-		key := property.Key
-		value := property.Value
-
-		var runtimeValue interpreter_env.RuntimeValue
-		var err error
-
-		if value == nil {
-			runtimeValue, err = env.LookupVar(key)
-		} else {
-			runtimeValue, err = Evaluate(value, env)
-		}
+		runtimeValue, err := Evaluate(property.Value, env)
 
 		if err != nil {
 			return nil, err
 		}
 
-		obj.Properties[key] = runtimeValue
+		obj.Properties[property.Key] = runtimeValue
 	}
 
-	return obj, nil
+	return allocated(obj, env)
 }
 
-func evalAssignment(assignment ast.AssigmentExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	assignmentVal, err := Evaluate(assignment.Value, env)
+func evalAssignment(assignment ast.AssigmentExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	switch assigne := assignment.Assigne.(type) {
+	case ast.Identifier:
+		assignmentVal, err := Evaluate(assignment.Value, env)
 
-	if err != nil {
-		return nil, err
-	}
-
-	switch assignment.Assigne.GetKind() {
-	case ast_types.Identifier:
+		if err != nil {
+			return nil, err
+		}
 
-		varName := assignment.Assigne.(ast.Identifier).Symbol
+		if operator, ok := interpreter_ops.CompoundOperator(assignment.Operator); ok {
+			current, err := evalIdentifier(assigne, env)
 
-		switch assignment.Operator {
-		case "+=":
-			assignmentVal, err = evalBinaryExpr(ast.BinaryExpr{Kind: ast_types.BinaryExpr, Operator: "+", Left: assignment.Assigne, Right: assignment.Value}, env)
-			if err != nil {
-				return nil, err
-			}
-		case "-=":
-			assignmentVal, err = evalBinaryExpr(ast.BinaryExpr{Kind: ast_types.BinaryExpr, Operator: "-", Left: assignment.Assigne, Right: assignment.Value}, env)
 			if err != nil {
 				return nil, err
 			}
-		case "*=":
-			assignmentVal, err = evalBinaryExpr(ast.BinaryExpr{Kind: ast_types.BinaryExpr, Operator: "*", Left: assignment.Assigne, Right: assignment.Value}, env)
-			if err != nil {
-				return nil, err
-			}
-		case "**=":
-			assignmentVal, err = evalBinaryExpr(ast.BinaryExpr{Kind: ast_types.BinaryExpr, Operator: "**", Left: assignment.Assigne, Right: assignment.Value}, env)
+
+			assignmentVal, err = interpreter_ops.Binary(operator, current, assignmentVal)
+
 			if err != nil {
 				return nil, err
 			}
-		case "/=":
-			assignmentVal, err = evalBinaryExpr(ast.BinaryExpr{Kind: ast_types.BinaryExpr, Operator: "/", Left: assignment.Assigne, Right: assignment.Value}, env)
-			if err != nil {
+
+			if _, err := allocated(assignmentVal, env); err != nil {
 				return nil, err
 			}
 		}
 
-		variable, err := env.AssignVar(varName, assignmentVal)
+		return assignIdentifier(assigne, assignmentVal, env)
+	case ast.MemberExpr:
+		return evalMemberAssignment(assignment, assigne, env)
+	default:
+		return nil, compilerErrors.ErrSyntaxInvalidAssignment
+	}
+}
 
-		return variable, err
-	case ast_types.MemberExpr:
-		if assignment.Operator != "=" {
-			return nil, errors.New(compilerErrors.ErrSyntaxInvalidAssignment)
-		}
+// The object is changed in place, so every reference to it sees the new value
+func evalMemberAssignment(assignment ast.AssigmentExpr, member ast.MemberExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	obj, err := Evaluate(member.Object, env)
 
-		identifier := assignment.Assigne.(ast.MemberExpr).Object.(ast.Identifier).Symbol
-		property := assignment.Assigne.(ast.MemberExpr).Property
-		isComputed := assignment.Assigne.(ast.MemberExpr).Computed
+	if err != nil {
+		return nil, err
+	}
+
+	var key interpreter_env.RuntimeValue
 
-		obj, err := Evaluate(assignment.Assigne.(ast.MemberExpr).Object, env)
+	if member.Computed {
+		key, err = Evaluate(member.Property, env)
 
 		if err != nil {
 			return nil, err
 		}
+	} else {
+		key = interpreter_makers.MkString(member.Property.(ast.Identifier).Symbol)
+	}
 
-		var propertyVal interpreter_env.RuntimeValue
-		if isComputed {
-			propertyVal, err = Evaluate(property, env)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		switch objVal := obj.(type) {
-		case interpreter_env.ObjectVal:
-			if isComputed {
-				objVal.Properties[fmt.Sprint(propertyVal.GetValue())] = assignmentVal
-			} else {
-				objVal.Properties[fmt.Sprint(property.(ast.Identifier).Symbol)] = assignmentVal
-			}
-			return env.AssignVar(identifier, objVal)
-		case interpreter_env.ArrayVal:
-			if propertyVal.GetType() != interpreter_env.Number {
-				return nil, errors.New(compilerErrors.ErrSyntaxInvalidAssignment)
-			}
-
-			number := propertyVal.GetValue().(float64)
+	assignmentVal, err := Evaluate(assignment.Value, env)
 
-			if math.Mod(number, 1) != 0 { // Check if is a float number
-				return nil, errors.New(compilerErrors.ErrSyntaxInvalidAssignment)
-			}
+	if err != nil {
+		return nil, err
+	}
 
-			idx := int(number)
+	result, err := interpreter_ops.SetMember(obj, key, assignmentVal, assignment.Operator, env.Realm().Guard)
 
-			isNegative := idx < 0
+	if err != nil {
+		return nil, err
+	}
 
-			if isNegative {
-				idx = len(objVal.Elements) + idx
-			}
+	if _, isCompound := interpreter_ops.CompoundOperator(assignment.Operator); isCompound {
+		return allocated(result, env)
+	}
 
-			isNegativeOutOfBounds := (idx < 0 || idx >= len(objVal.Elements)) && isNegative
+	return result, nil
+}
 
-			if isNegativeOutOfBounds {
-				return nil, errors.New(compilerErrors.ErrSyntaxInvalidAssignment)
-			}
+func evalIdentifier(ident ast.Identifier, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	val, ok := env.Get(ident.Depth, ident.Slot)
 
-			if idx >= len(objVal.Elements) {
-				for i := len(objVal.Elements); i <= idx; i++ {
-					objVal.Elements = append(objVal.Elements, interpreter_makers.MkNull())
-				}
-			}
+	// The resolver knows the variable but it hasn't been declared yet at this point
+	if !ok {
+		return nil, compilerErrors.ErrVariableDoesNotExist.WithArgs(ident.Symbol)
+	}
 
-			objVal.Elements[idx] = assignmentVal
+	return val, nil
+}
 
-			return env.AssignVar(identifier, objVal)
-		default:
-			return nil, errors.New(compilerErrors.ErrSyntaxInvalidAssignment)
-		}
-	default:
-		return nil, errors.New(compilerErrors.ErrSyntaxInvalidAssignment)
+// Constant assignments are rejected by the resolver before the program runs
+func assignIdentifier(ident ast.Identifier, value interpreter_env.RuntimeValue, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	if !env.Set(ident.Depth, ident.Slot, value) {
+		return nil, compilerErrors.ErrVariableDoesNotExist.WithArgs(ident.Symbol)
 	}
-}
 
-func evalIdentifier(ident ast.Identifier, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	val, err := env.LookupVar(ident.Symbol)
-	return val, err
+	return value, nil
 }
 
-func evalConditionalExpr(conditionalExpr ast.ConditionalExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+func evalConditionalExpr(conditionalExpr ast.ConditionalExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	evalCondition, err := Evaluate(conditionalExpr.Condition, env)
 
 	if err != nil {
@@ -372,57 +323,7 @@ func evalConditionalExpr(conditionalExpr ast.ConditionalExpr, env interpreter_en
 	return Evaluate(conditionalExpr.Alternate, env)
 }
 
-func evalStringBinaryExpr(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
-	result := ""
-	valLhs, okLhs := lhs.(interpreter_env.StringVal)
-	valRhs, okRhs := rhs.(interpreter_env.StringVal)
-	if !okLhs || !okRhs {
-		return nil, errors.New(compilerErrors.ErrBinaryInvalidBinaryExpr)
-	}
-	switch operator {
-	case "+":
-		result = valLhs.Value + valRhs.Value
-	}
-
-	return interpreter_makers.MkString(result), nil
-}
-
-func evaluateNumericBinaryExpr(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
-	var result float64 = 0
-
-	valLhs, okLhs := lhs.(interpreter_env.NumberVal)
-	valRhs, okRhs := rhs.(interpreter_env.NumberVal)
-
-	if !okLhs || !okRhs {
-		return nil, errors.New(compilerErrors.ErrBinaryInvalidBinaryExpr)
-	}
-
-	switch operator {
-	case "+":
-		result = valLhs.Value + valRhs.Value
-	case "-":
-		result = valLhs.Value - valRhs.Value
-	case "*":
-		result = valLhs.Value * valRhs.Value
-	case "/":
-		if valRhs.Value == 0 {
-			result = math.Inf(0)
-		}
-		result = valLhs.Value / valRhs.Value
-	case "%":
-		if valRhs.Value == 0 {
-			return nil, errors.New(compilerErrors.ErrBinaryDivisionByZero)
-		}
-		result = float64(int(valLhs.Value) % int(valRhs.Value))
-	case "**", "^":
-		result = math.Pow(valLhs.Value, valRhs.Value)
-	}
-
-	return interpreter_makers.MkNumber(result), nil
-}
-
-func evalLogicalExpr(logicalExpr ast.LogicalExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	result := false
+func evalLogicalExpr(logicalExpr ast.LogicalExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	evalLhs, err := Evaluate(logicalExpr.Left, env)
 
 	if err != nil {
@@ -435,122 +336,43 @@ func evalLogicalExpr(logicalExpr ast.LogicalExpr, env interpreter_env.Environmen
 		return nil, err
 	}
 
-	valLhs := nativeFns.EvaluateTruthyFalsyValues(evalLhs)
-	valRhs := nativeFns.EvaluateTruthyFalsyValues(evalRhs)
-
-	switch logicalExpr.Operator {
-	case "&&":
-		result = valLhs && valRhs
-	case "||":
-		result = valLhs || valRhs
-	}
-
-	return interpreter_makers.MkBoolean(result), nil
+	return interpreter_ops.Logical(logicalExpr.Operator, evalLhs, evalRhs), nil
 }
 
-func evalUnaryExpr(expr ast.UnaryExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	result := false
+func evalUnaryExpr(expr ast.UnaryExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	eval, err := Evaluate(expr.Argument, env)
 
 	if err != nil {
 		return nil, err
 	}
 
-	boolVal := nativeFns.EvaluateTruthyFalsyValues(eval)
-
-	switch expr.Operator {
-	case "!":
-		result = !boolVal
-		return interpreter_makers.MkBoolean(result), nil
-	case "+":
-		if eval.GetType() != interpreter_env.Number {
-			return nil, errors.New(compilerErrors.ErrSyntaxUnaryInvalidUnaryExpr)
-		}
-		result, ok := eval.GetValue().(float64)
-		if !ok {
-			return nil, errors.New(compilerErrors.ErrSyntaxUnaryInvalidUnaryExpr)
-		}
-		return interpreter_makers.MkNumber(result), nil
-	case "-":
-		if eval.GetType() != interpreter_env.Number {
-			return nil, errors.New(compilerErrors.ErrSyntaxUnaryInvalidUnaryExpr)
-		}
-		result, ok := eval.GetValue().(float64)
-		if !ok {
-			return nil, errors.New(compilerErrors.ErrSyntaxUnaryInvalidUnaryExpr)
-		}
-		return interpreter_makers.MkNumber(-result), nil
-	default:
-		return interpreter_makers.MkNull(), nil
-	}
+	return interpreter_ops.Unary(expr.Operator, eval)
 }
 
-func evalUpdateExpr(expr ast.UpdateExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	isPrefix := expr.Prefix
-	op := expr.Operator
-	identifier := expr.Argument.Symbol
+func evalUpdateExpr(expr ast.UpdateExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	eval, err := Evaluate(expr.Argument, env)
 	if err != nil {
 		return nil, err
 	}
 
-	if eval.GetType() != interpreter_env.Number {
-		return nil, errors.New(compilerErrors.ErrSyntaxInvalidUpdateExpr)
+	updated, err := interpreter_ops.Update(expr.Operator, eval)
+	if err != nil {
+		return nil, err
 	}
 
-	switch op {
-	case "++":
-		num, err := env.AssignVar(identifier, interpreter_makers.MkNumber(eval.GetValue().(float64)+1))
-
-		if err != nil {
-			return nil, err
-		}
-
-		if isPrefix {
-			return num, nil
-		}
-
-		return eval, nil
-	case "--":
-		num, err := env.AssignVar(identifier, interpreter_makers.MkNumber(eval.GetValue().(float64)-1))
-
-		if err != nil {
-			return nil, err
-		}
-
-		if isPrefix {
-			return num, nil
-		}
+	_, err = assignIdentifier(expr.Argument, updated, env)
+	if err != nil {
+		return nil, err
+	}
 
-		return eval, nil
-	default:
-		return nil, errors.New(compilerErrors.ErrSyntaxInvalidUpdateExpr)
+	if expr.Prefix {
+		return updated, nil
 	}
-}
 
-func evalComparisonBinaryExpr(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
-	var result = false
-	numValLhs, _ := lhs.(interpreter_env.NumberVal)
-	numValRhs, _ := rhs.(interpreter_env.NumberVal)
-
-	switch operator {
-	case "==":
-		result = lhs.GetValue() == rhs.GetValue()
-	case "!=":
-		result = lhs.GetValue() != rhs.GetValue()
-	case "<":
-		result = numValLhs.Value < numValRhs.Value
-	case ">":
-		result = numValLhs.Value > numValRhs.Value
-	case "<=":
-		result = numValLhs.Value <= numValRhs.Value
-	case ">=":
-		result = numValLhs.Value >= numValRhs.Value
-	}
-	return interpreter_makers.MkBoolean(result), nil
+	return eval, nil
 }
 
-func evalBinaryExpr(binop ast.BinaryExpr, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+func evalBinaryExpr(binop ast.BinaryExpr, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	lhs, err := Evaluate(binop.Left, env)
 	if err != nil {
 		return nil, err
@@ -561,23 +383,20 @@ func evalBinaryExpr(binop ast.BinaryExpr, env interpreter_env.Environment) (inte
 		return nil, err
 	}
 
-	// EVAL < < >= <= == !=
-	if slices.Contains(ast_types.BoolExpr, binop.Operator) {
-		eval, err := evalComparisonBinaryExpr(binop.Operator, lhs, rhs)
-		return eval, err
-	}
+	result, err := interpreter_ops.Binary(binop.Operator, lhs, rhs)
 
-	// EVAL + - * / % ** (numbers)
-	if lhs.GetType() == interpreter_env.Number && rhs.GetType() == interpreter_env.Number {
-		eval, err := evaluateNumericBinaryExpr(binop.Operator, lhs, rhs)
-		return eval, err
+	if err != nil {
+		return nil, err
 	}
 
-	// EVAL + (strings)
-	if lhs.GetType() == interpreter_env.String && rhs.GetType() == interpreter_env.String {
-		eval, err := evalStringBinaryExpr(binop.Operator, lhs, rhs)
-		return eval, err
+	return allocated(result, env)
+}
+
+// Counts the memory of a value created by the program before giving it to the program
+func allocated(value interpreter_env.RuntimeValue, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	if err := env.Realm().Guard.Allocate(interpreter_env.SizeOf(value)); err != nil {
+		return nil, err
 	}
 
-	return interpreter_makers.MkNull(), nil
+	return value, nil
 }
@@ -1,17 +1,77 @@
 package interpreter_eval
 
 import (
-	"errors"
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
 	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
-	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval/internal/nativeFns"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
-
-	"golang.org/x/exp/slices"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_ops"
+	"github.com/Waxer59/PikaLang/pkg/resolver"
 )
 
-func evalVariableDeclaration(variableDeclaration ast.VariableDeclaration, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+// Runs a statement that may complete abruptly, any other node is evaluated as usual
+func executeStmt(stmt ast.Stmt, env *interpreter_env.Environment) (Completion, error) {
+	var completion Completion
+	var err error
+
+	switch stmt.GetKind() {
+	case ast_types.IfStatement:
+		completion, err = evalIfStatement(stmt.(ast.IfStatement), env)
+	case ast_types.SwitchStatement:
+		completion, err = evalSwitchStatement(stmt.(ast.SwitchStatement), env)
+	case ast_types.WhileStatement:
+		completion, err = evalWhileStatement(stmt.(ast.WhileStatement), env)
+	case ast_types.ForStatement:
+		completion, err = evalForStatement(stmt.(ast.ForStatement), env)
+	case ast_types.ReturnStatement:
+		completion, err = evalReturnStatement(stmt.(ast.ReturnStatement), env)
+	case ast_types.TryStatement:
+		completion, err = evalTryStatement(stmt.(ast.TryStatement), env)
+	case ast_types.ThrowStatement:
+		completion, err = evalThrowStatement(stmt.(ast.ThrowStatement), env)
+	case ast_types.BreakStatement:
+		completion = Completion{Type: BreakCompletion, Label: stmt.(ast.BreakStatement).Label, Span: stmt.GetSpan()}
+	case ast_types.ContinueStatement:
+		completion = Completion{Type: ContinueCompletion, Label: stmt.(ast.ContinueStatement).Label, Span: stmt.GetSpan()}
+	default:
+		eval, err := Evaluate(stmt, env)
+		return normalCompletion(eval), err
+	}
+
+	if err != nil {
+		return completion, locate(err, stmt, env)
+	}
+
+	return completion, nil
+}
+
+// Evaluates a statement outside of any function or loop, so it can't complete abruptly
+func evalTopLevelStmt(stmt ast.Stmt, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	completion, err := executeStmt(stmt, env)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return completion.Value, checkTopLevelCompletion(completion)
+}
+
+func checkTopLevelCompletion(completion Completion) error {
+	switch completion.Type {
+	case ReturnCompletion:
+		return compilerErrors.ErrReturn.At(completion.Span)
+	case BreakCompletion:
+		return compilerErrors.ErrLoopsBreakNotInLoop.At(completion.Span)
+	case ContinueCompletion:
+		return compilerErrors.ErrLoopsContinueNotInLoop.At(completion.Span)
+	}
+
+	return nil
+}
+
+func evalVariableDeclaration(variableDeclaration ast.VariableDeclaration, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	var value interpreter_env.RuntimeValue = interpreter_makers.MkNull()
 
 	if variableDeclaration.Value != nil {
@@ -22,160 +82,208 @@ func evalVariableDeclaration(variableDeclaration ast.VariableDeclaration, env in
 		value = eval
 	}
 
-	variable, err := env.DeclareVar(variableDeclaration.Identifier, value, variableDeclaration.Constant)
-
-	return variable, err
+	return env.DeclareVar(variableDeclaration.Identifier, value, variableDeclaration.Constant), nil
 }
 
-func evalReturnStatement(declaration ast.ReturnStatement, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-
+func evalReturnStatement(declaration ast.ReturnStatement, env *interpreter_env.Environment) (Completion, error) {
 	var returnValue interpreter_env.RuntimeValue = interpreter_makers.MkNull()
 
 	if declaration.Argument != nil {
 		eval, err := Evaluate(declaration.Argument, env)
 		if err != nil {
-			return eval, err
+			return Completion{}, err
 		}
 		returnValue = eval
 	}
 
-	// Throw an error to stop the execution
-	return returnValue, errors.New(compilerErrors.ErrReturn)
+	return Completion{Type: ReturnCompletion, Value: returnValue, Span: declaration.Span}, nil
+}
+
+func evalThrowStatement(declaration ast.ThrowStatement, env *interpreter_env.Environment) (Completion, error) {
+	value, err := Evaluate(declaration.Argument, env)
+
+	if err != nil {
+		return Completion{}, err
+	}
+
+	return Completion{}, interpreter_env.Throw(value)
 }
 
-func evalForStatement(declaration ast.ForStatement, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+/*
+ * Runs the try block, and the catch block if the try block fails with an
+ * error the program can catch. The finally block runs after them whatever
+ * happened, unless the error stops the program, and if it completes abruptly
+ * or fails it replaces the completion or the error of the blocks before it.
+ */
+func evalTryStatement(declaration ast.TryStatement, env *interpreter_env.Environment) (Completion, error) {
+	completion, err := EvaluateBlockStmt(declaration.Block, env)
+
+	if err != nil && declaration.Handler != nil && interpreter_env.Catchable(err) {
+		catchEnv := interpreter_env.New(env)
+
+		if declaration.Handler.Param != "" {
+			catchEnv.DeclareVar(declaration.Handler.Param, interpreter_env.CaughtValue(err), false)
+		}
+
+		completion, err = EvaluateBodyStmt(declaration.Handler.Body, catchEnv)
+	}
+
+	if declaration.Finalizer == nil || err != nil && !interpreter_env.Catchable(err) {
+		return completion, err
+	}
+
+	finalCompletion, finalErr := EvaluateBlockStmt(declaration.Finalizer, env)
+
+	if finalErr != nil || finalCompletion.IsAbrupt() {
+		return finalCompletion, finalErr
+	}
+
+	return completion, err
+}
+
+/*
+ * Runs the body of a loop. The first return value tells if the loop has to
+ * stop, in which case the completion has to be handed to the enclosing code
+ * unless it is a break targeting this loop.
+ */
+func runLoopBody(body []ast.Stmt, label string, env *interpreter_env.Environment) (bool, Completion, error) {
+	completion, err := EvaluateBlockStmt(body, env)
+
+	if err != nil {
+		return true, completion, err
+	}
+
+	switch completion.Type {
+	case BreakCompletion:
+		if completion.targets(label) {
+			return true, normalCompletion(nil), nil
+		}
+		return true, completion, nil
+	case ContinueCompletion:
+		if completion.targets(label) {
+			return false, completion, nil
+		}
+		return true, completion, nil
+	case ReturnCompletion:
+		return true, completion, nil
+	}
+
+	return false, completion, nil
+}
+
+/*
+ * The variables declared in the init of a for loop live in their own scope,
+ * every iteration gets a fresh copy of it so closures created in the body
+ * capture the value that the loop variables had in that iteration.
+ */
+func evalForStatement(declaration ast.ForStatement, env *interpreter_env.Environment) (Completion, error) {
+	iterationEnv := interpreter_env.New(env)
+
 	if declaration.Init != nil {
-		eval, err := Evaluate(declaration.Init, env)
+		_, err := Evaluate(declaration.Init, iterationEnv)
 		if err != nil {
-			return eval, err
+			return Completion{}, err
 		}
 	}
+
+	iterationEnv = iterationEnv.Copy()
+
 	for {
 		if declaration.Test != nil {
-			eval, err := Evaluate(declaration.Test, env)
+			eval, err := Evaluate(declaration.Test, iterationEnv)
 			if err != nil {
-				return eval, err
+				return Completion{}, err
 			}
 			testVal := nativeFns.EvaluateTruthyFalsyValues(eval)
 			if !testVal {
 				break
 			}
 		}
-		eval, err := EvaluateBodyStmt(declaration.Body, env)
-		if err != nil && err.Error() == compilerErrors.ErrLoopsBreakNotInLoop {
-			break
-		} else if err != nil && err.Error() == compilerErrors.ErrLoopsContinueNotInLoop {
-			continue
-		} else if err != nil {
-			return eval, err
+
+		stop, completion, err := runLoopBody(declaration.Body, declaration.Label, iterationEnv)
+		if stop || err != nil {
+			return completion, err
 		}
 
+		iterationEnv = iterationEnv.Copy()
+
 		if declaration.Update != nil {
-			eval, err := Evaluate(declaration.Update, env)
+			_, err := Evaluate(declaration.Update, iterationEnv)
 			if err != nil {
-				return eval, err
+				return Completion{}, err
 			}
 		}
 	}
 
-	return nil, nil
-}
-
-func evalBreakStatement() (interpreter_env.RuntimeValue, error) {
-	return nil, errors.New(compilerErrors.ErrLoopsBreakNotInLoop)
-}
-
-func evalContinueStatement() (interpreter_env.RuntimeValue, error) {
-	return nil, errors.New(compilerErrors.ErrLoopsContinueNotInLoop)
+	return normalCompletion(nil), nil
 }
 
-func evalWhileStatement(declaration ast.WhileStatement, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	testEval, err := Evaluate(declaration.Test, env)
-	if err != nil {
-		return nil, err
-	}
-
-	testVal := nativeFns.EvaluateTruthyFalsyValues(testEval)
+func evalWhileStatement(declaration ast.WhileStatement, env *interpreter_env.Environment) (Completion, error) {
+	for {
+		testEval, err := Evaluate(declaration.Test, env)
+		if err != nil {
+			return Completion{}, err
+		}
 
-	for testVal {
-		eval, err := EvaluateBodyStmt(declaration.Body, env)
-		if err != nil && err.Error() == compilerErrors.ErrLoopsBreakNotInLoop {
+		if !nativeFns.EvaluateTruthyFalsyValues(testEval) {
 			break
-		} else if err != nil && err.Error() == compilerErrors.ErrLoopsContinueNotInLoop {
-			continue
-		} else if err != nil {
-			return eval, err
 		}
-		testEval, err = Evaluate(declaration.Test, env)
 
-		if err != nil {
-			return eval, err
+		stop, completion, err := runLoopBody(declaration.Body, declaration.Label, env)
+		if stop || err != nil {
+			return completion, err
 		}
-
-		testVal = nativeFns.EvaluateTruthyFalsyValues(testEval)
 	}
 
-	return interpreter_makers.MkNull(), nil
+	return normalCompletion(nil), nil
 }
 
-func evalSwitchStatement(declaration ast.SwitchStatement, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+// The discriminant is evaluated once, then the tests of the cases in order
+// until one matches
+func evalSwitchStatement(declaration ast.SwitchStatement, env *interpreter_env.Environment) (Completion, error) {
+	discriminant, err := Evaluate(declaration.Discriminant, env)
+
+	if err != nil {
+		return Completion{}, err
+	}
+
 	for _, caseStatement := range declaration.CaseStmts {
+		for _, test := range caseStatement.Test {
+			eval, err := Evaluate(test, env)
 
-		if slices.ContainsFunc(caseStatement.Test, func(expr ast.Expr) bool {
-			eval, err := Evaluate(expr, env)
-			if err != nil {
-				return false
-			}
-			evalDiscriminant, err := Evaluate(declaration.Discriminant, env)
 			if err != nil {
-				return false
+				return Completion{}, err
 			}
-			return err == nil && eval.GetValue() == evalDiscriminant.GetValue() || eval.GetValue() == true
-		}) {
-			eval, err := EvaluateBodyStmt(caseStatement.Body, env)
-			if err != nil {
-				return eval, err
+
+			if interpreter_ops.CaseMatches(eval, discriminant) {
+				return EvaluateBlockStmt(caseStatement.Body, env)
 			}
-			return nil, nil
 		}
 	}
 
 	if declaration.DefaultStmt.Body == nil {
-		return nil, nil
+		return normalCompletion(nil), nil
 	}
 
-	eval, err := EvaluateBodyStmt(declaration.DefaultStmt.Body, env)
-	if err != nil {
-		return eval, err
-	}
-
-	return nil, nil
+	return EvaluateBlockStmt(declaration.DefaultStmt.Body, env)
 }
 
-func evalIfStatement(declaration ast.IfStatement, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+func evalIfStatement(declaration ast.IfStatement, env *interpreter_env.Environment) (Completion, error) {
 	conditionRawValue, err := Evaluate(declaration.Test, env)
 
 	if err != nil {
-		return nil, err
+		return Completion{}, err
 	}
 
 	val := nativeFns.EvaluateTruthyFalsyValues(conditionRawValue)
 
-	if err != nil {
-		return nil, err
-	}
-
 	// Handle first if
 	if val {
-		eval, err := EvaluateBodyStmt(declaration.Body, env)
-		if err != nil {
-			return eval, err
-		}
-		return nil, nil
+		return EvaluateBlockStmt(declaration.Body, env)
 	}
 
 	if declaration.ElseIfStmt == nil && declaration.ElseBody == nil {
-		return nil, nil
+		return normalCompletion(nil), nil
 	}
 
 	// Handle else if
@@ -183,42 +291,49 @@ func evalIfStatement(declaration ast.IfStatement, env interpreter_env.Environmen
 		conditionRawValue, err := Evaluate(elseIfStatement.Test, env)
 
 		if err != nil {
-			return nil, err
+			return Completion{}, err
 		}
 
 		val := nativeFns.EvaluateTruthyFalsyValues(conditionRawValue)
 
 		if val {
-			eval, err := EvaluateBodyStmt(elseIfStatement.Body, env)
-			if err != nil {
-				return eval, err
-			}
-			return nil, nil
+			return EvaluateBlockStmt(elseIfStatement.Body, env)
 		}
 	}
 
 	// Handle else
-	eval, err := EvaluateBodyStmt(declaration.ElseBody, env)
-	if err != nil {
-		return eval, err
-	}
-
-	return nil, nil
+	return EvaluateBlockStmt(declaration.ElseBody, env)
 }
 
-func evalFunctionDeclaration(declaration ast.FunctionDeclaration, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+func evalFunctionDeclaration(declaration ast.FunctionDeclaration, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 
 	fn := interpreter_env.FunctionVal{
 		Type:           interpreter_env.Function,
 		Name:           &declaration.Name,
 		Params:         declaration.Params,
-		DeclarationEnv: &env,
+		DeclarationEnv: env,
 		Body:           declaration.Body,
 	}
 
-	return env.DeclareVar(declaration.Name, fn, true)
+	return env.DeclareVar(declaration.Name, fn, true), nil
 }
 
-func evalProgram(program ast.Program, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	return EvaluateBodyStmt(program.Body, env)
+// The program is resolved against the variables already declared in env
+// before it runs, so it fails early if it uses variables that don't exist
+func evalProgram(program ast.Program, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	program, err := resolver.Resolve(program, declaredGlobals(env), env.Realm().HostFunctions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reserveGlobals(program, env)
+
+	completion, err := EvaluateBodyStmt(program.Body, env)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return completion.Value, checkTopLevelCompletion(completion)
 }
@@ -1,15 +1,48 @@
 package interpreter_eval
 
 import (
-	"errors"
+	"context"
 
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 	"github.com/Waxer59/PikaLang/pkg/ast"
 	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
 )
 
-func Evaluate(astNode ast.Stmt, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+/*
+ * Runs a program like Evaluate, stopping it with an error when ctx is done or
+ * when it reaches one of the limits.
+ */
+func Run(ctx context.Context, program ast.Program, env *interpreter_env.Environment, limits interpreter_env.Limits) (interpreter_env.RuntimeValue, error) {
+	return env.Realm().Run(ctx, limits, func() (interpreter_env.RuntimeValue, error) {
+		return Evaluate(program, env)
+	})
+}
+
+func Evaluate(astNode ast.Stmt, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
+	if err := env.Realm().Guard.Step(); err != nil {
+		return nil, locate(err, astNode, env)
+	}
+
+	eval, err := evaluateNode(astNode, env)
+
+	if err != nil {
+		return eval, locate(err, astNode, env)
+	}
+
+	return eval, nil
+}
+
+// The innermost node that failed gives the location of the error, and the
+// calls being run when it failed give its stack
+func locate(err error, astNode ast.Stmt, env *interpreter_env.Environment) error {
+	err = diagnostic.WithSpan(err, astNode.GetSpan())
+	return diagnostic.WithStack(err, env.Realm().Guard.Stack)
+}
+
+func evaluateNode(astNode ast.Stmt, env *interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
 	switch astNode.GetKind() {
 
 	// LITERALS
@@ -29,6 +62,8 @@ func Evaluate(astNode ast.Stmt, env interpreter_env.Environment) (interpreter_en
 		return interpreter_makers.MkNan(), nil
 	case ast_types.ArrayLiteral:
 		return evalArrayExpr(astNode.(ast.ArrayLiteral), env)
+	case ast_types.TemplateLiteral:
+		return evalTemplateLiteral(astNode.(ast.TemplateLiteral), env)
 
 	// EXPRESSIONS
 	case ast_types.BinaryExpr:
@@ -48,7 +83,7 @@ func Evaluate(astNode ast.Stmt, env interpreter_env.Environment) (interpreter_en
 	case ast_types.UpdateExpr:
 		return evalUpdateExpr(astNode.(ast.UpdateExpr), env)
 	case ast_types.ArrowFunctionExpr:
-		return evalArrowFunctionExpr(astNode.(ast.ArrowFunctionExpr))
+		return evalArrowFunctionExpr(astNode.(ast.ArrowFunctionExpr), env)
 
 	// STATEMENTS
 	case ast_types.Program:
@@ -57,22 +92,14 @@ func Evaluate(astNode ast.Stmt, env interpreter_env.Environment) (interpreter_en
 		return evalVariableDeclaration(astNode.(ast.VariableDeclaration), env)
 	case ast_types.FunctionDeclaration:
 		return evalFunctionDeclaration(astNode.(ast.FunctionDeclaration), env)
-	case ast_types.IfStatement:
-		return evalIfStatement(astNode.(ast.IfStatement), env)
-	case ast_types.SwitchStatement:
-		return evalSwitchStatement(astNode.(ast.SwitchStatement), env)
-	case ast_types.ReturnStatement:
-		return evalReturnStatement(astNode.(ast.ReturnStatement), env)
-	case ast_types.WhileStatement:
-		return evalWhileStatement(astNode.(ast.WhileStatement), env)
-	case ast_types.BreakStatement:
-		return evalBreakStatement()
-	case ast_types.ContinueStatement:
-		return evalContinueStatement()
-	case ast_types.ForStatement:
-		return evalForStatement(astNode.(ast.ForStatement), env)
+	case ast_types.IfStatement, ast_types.SwitchStatement, ast_types.ReturnStatement, ast_types.WhileStatement,
+		ast_types.BreakStatement, ast_types.ContinueStatement, ast_types.ForStatement, ast_types.TryStatement, ast_types.ThrowStatement:
+		return evalTopLevelStmt(astNode, env)
+
+	case ast_types.ErrorNode:
+		return nil, compilerErrors.ErrParsingError
 
 	default:
-		return nil, errors.New("ERROR: Unknown node type")
+		return nil, compilerErrors.ErrUnknownNodeType
 	}
 }
@@ -0,0 +1,768 @@
+package interpreter_eval_test
+
+import (
+	"errors"
+	"testing"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
+	"github.com/Waxer59/PikaLang/pkg/parser"
+)
+
+type evalTest struct {
+	name        string
+	input       string
+	expected    any // Value of the 'result' variable
+	expectedErr error
+}
+
+func runEvalTests(t *testing.T, tests []evalTest) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			program, err := parser.New().ProduceAST(test.input)
+
+			if err == nil {
+				env := interpreter_env.New(nil)
+				_, err = interpreter_eval.Evaluate(*program, env)
+
+				if err == nil {
+					result, lookupErr := env.LookupVar("result")
+
+					if lookupErr != nil {
+						t.Fatalf("Expected a result variable, but got: %v", lookupErr)
+					}
+
+					if result.GetValue() != test.expected {
+						t.Errorf("Expected result to be %v, but got: %v", test.expected, result.GetValue())
+					}
+				}
+			}
+
+			if test.expectedErr == nil && err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if test.expectedErr != nil && !errors.Is(err, test.expectedErr) {
+				t.Errorf("Expected error: %v, but got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "return inside a loop inside a function",
+			input: `
+				fn find(arr, target) {
+					for var i = 0; i < len(arr); i++ {
+						if arr[i] == target {
+							return i
+						}
+					}
+					return -1
+				}
+				var result = find([4, 5, 6], 6) * 10 + find([1], 9)`,
+			expected: 19.0,
+		},
+		{
+			name: "function without return",
+			input: `
+				fn noop() {
+					var a = 1
+				}
+				var result = noop()`,
+			expected: "null",
+		},
+		{
+			name: "continue runs the for update",
+			input: `
+				var result = 0
+				for var i = 0; i < 5; i++ {
+					if i == 2 {
+						continue
+					}
+					result += i
+				}`,
+			expected: 8.0,
+		},
+		{
+			name: "continue re-evaluates the while test",
+			input: `
+				var result = 0
+				var i = 0
+				while i < 5 {
+					i++
+					if i == 2 {
+						continue
+					}
+					result += i
+				}`,
+			expected: 13.0,
+		},
+		{
+			name: "labeled break and continue",
+			input: `
+				var result = 0
+				var j = 0
+				outer: for var i = 0; i < 5; i++ {
+					j = 0
+					while j < 5 {
+						j++
+						if j == 4 {
+							continue outer
+						}
+						if i == 3 {
+							break outer
+						}
+						result++
+					}
+				}`,
+			expected: 9.0,
+		},
+		{
+			name: "break inside a switch exits the loop",
+			input: `
+				var result = 0
+				while true {
+					result++
+					switch result {
+						case 3:
+							break
+					}
+				}`,
+			expected: 3.0,
+		},
+		{
+			name:        "top level return",
+			input:       "return 1",
+			expectedErr: compilerErrors.ErrReturn,
+		},
+		{
+			name:        "break outside a loop",
+			input:       "if true { break }",
+			expectedErr: compilerErrors.ErrLoopsBreakNotInLoop,
+		},
+		{
+			name:        "continue can't cross functions",
+			input:       "while true { fn f() { continue } }",
+			expectedErr: compilerErrors.ErrLoopsContinueNotInLoop,
+		},
+		{
+			name:        "undefined label",
+			input:       "while true { break outer }",
+			expectedErr: compilerErrors.ErrLoopsUndefinedLabel,
+		},
+		{
+			name:        "calling a value that is not a function",
+			input:       "var a = 1\na()",
+			expectedErr: compilerErrors.ErrNotAFunction,
+		},
+	})
+}
+
+func TestExceptions(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "catch takes the thrown value",
+			input: `
+				var result = 0
+				try {
+					throw 42
+				} catch (err) {
+					result = err
+				}`,
+			expected: 42.0,
+		},
+		{
+			name: "throw goes up through calls",
+			input: `
+				fn fail() {
+					throw { message: "boom" }
+				}
+				var result = 0
+				try {
+					fail()
+				} catch (err) {
+					result = err.message
+				}`,
+			expected: "boom",
+		},
+		{
+			name: "runtime errors are caught with their code",
+			input: `
+				var result = 0
+				try {
+					var a = 1
+					a()
+				} catch (err) {
+					result = err.code
+				}`,
+			expected: compilerErrors.ErrNotAFunction.Code,
+		},
+		{
+			name: "finally runs after a return",
+			input: `
+				var result = 0
+				fn f() {
+					try {
+						return 1
+					} finally {
+						result = 10
+					}
+				}
+				result += f()`,
+			expected: 11.0,
+		},
+		{
+			name: "finally runs after break and continue",
+			input: `
+				var result = 0
+				for var i = 0; i < 5; i++ {
+					try {
+						if i == 1 {
+							continue
+						}
+						if i == 3 {
+							break
+						}
+					} finally {
+						result++
+					}
+				}`,
+			expected: 4.0,
+		},
+		{
+			name: "return in finally overrides the thrown value",
+			input: `
+				fn f() {
+					try {
+						throw "lost"
+					} finally {
+						return "kept"
+					}
+				}
+				var result = f()`,
+			expected: "kept",
+		},
+		{
+			name: "rethrow from catch",
+			input: `
+				var result = 0
+				try {
+					try {
+						throw 1
+					} catch (err) {
+						throw err + 1
+					}
+				} catch (err) {
+					result = err
+				}`,
+			expected: 2.0,
+		},
+		{
+			name: "catch without a parameter",
+			input: `
+				var result = 0
+				try {
+					throw "ignored"
+				} catch {
+					result = 1
+				}`,
+			expected: 1.0,
+		},
+		{
+			name:        "uncaught throw",
+			input:       `throw "oops"`,
+			expectedErr: compilerErrors.ErrUncaughtException,
+		},
+		{
+			name:        "finally doesn't swallow the error",
+			input:       "try {\n throw 1\n} finally {}",
+			expectedErr: compilerErrors.ErrUncaughtException,
+		},
+	})
+}
+
+func TestTemplateLiterals(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "values are converted like string()",
+			input: `
+				const name = "Pika"
+				var result = ` + "`${name} has ${[1, 2]}, ${{ a: 1 }}, ${null} and ${1 / 2}`",
+			expected: "Pika has [1, 2], object, null and 0.5",
+		},
+		{
+			name: "expressions and nested templates",
+			input: `
+				fn double(n) {
+					return n * 2
+				}
+				var result = ` + "`${double(2) + 1}${`-${true}`}`",
+			expected: "5-true",
+		},
+		{
+			name:     "templates span lines",
+			input:    "var result = `a\n  b`",
+			expected: "a\n  b",
+		},
+		{
+			name:        "errors inside substitutions",
+			input:       "var result = `${missing}`",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+	})
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name:     "and, or, xor and not",
+			input:    "var result = string([0xF0 | 0x0F, 0xFF & 0x0F, 0b1100 ^ 0b1010, ~5, ~-1])",
+			expected: "[255, 15, 6, -6, 0]",
+		},
+		{
+			name:     "shifts",
+			input:    "var result = string([1 << 10, -16 >> 2, -16 >>> 28, 1 << 32, 1 << 31 == -2147483648])",
+			expected: "[1024, -4, 15, 1, true]",
+		},
+		{
+			name:     "unsigned shifts give exact results",
+			input:    "var result = string([-1 >>> 0 == 4294967295, (-1 >>> 0) & 1, 0xFFFFFFFF | 0, 2 ** 53 + 2 >>> 0])",
+			expected: "[true, 1, -1, 2]",
+		},
+		{
+			name:     "the integer part of numbers is used",
+			input:    "var result = string([5.9 & 3.2, -5.5 | 0, 2 ** 32 + 3 | 0])",
+			expected: "[1, -5, 3]",
+		},
+		{
+			name:     "precedence",
+			input:    "var result = string([1 | 2 ^ 3 & 4, 1 + 1 << 1, 1 << 2 == 4, (6 & 3) == 2])",
+			expected: "[3, 4, true, true]",
+		},
+		{
+			name: "compound assignments",
+			input: `
+				var flags = 0o4 | 0o2
+				flags ^= 0o2
+				flags |= 0o1
+				flags &= ~0o4
+				flags <<= 4
+				flags >>= 1
+				var bits = [0xF]
+				bits[0] >>>= 2
+				var result = string([flags, bits[0]])`,
+			expected: "[8, 3]",
+		},
+		{
+			name:        "not of a value that isn't a number",
+			input:       `var result = ~"a"`,
+			expectedErr: compilerErrors.ErrSyntaxUnaryInvalidUnaryExpr,
+		},
+	})
+}
+
+func TestClosures(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "counter keeps its state between calls",
+			input: `
+				fn makeCounter() {
+					var count = 0
+					return () => {
+						count++
+						return count
+					}
+				}
+				const counter = makeCounter()
+				counter()
+				counter()
+				var result = counter()`,
+			expected: 3.0,
+		},
+		{
+			name: "counters don't share their state",
+			input: `
+				fn makeCounter() {
+					var count = 0
+					return () => {
+						count++
+						return count
+					}
+				}
+				const a = makeCounter()
+				const b = makeCounter()
+				a()
+				a()
+				var result = b()`,
+			expected: 1.0,
+		},
+		{
+			name: "functions see variables declared after them",
+			input: `
+				fn getValue() {
+					return value
+				}
+				var value = 10
+				var result = getValue()`,
+			expected: 10.0,
+		},
+		{
+			name: "mutations inside a function are visible to the caller",
+			input: `
+				var result = 0
+				fn add(n) {
+					result += n
+				}
+				add(2)
+				add(3)`,
+			expected: 5.0,
+		},
+		{
+			name: "arrow functions capture arguments of the enclosing function",
+			input: `
+				fn adder(x) {
+					return (y) => {
+						return x + y
+					}
+				}
+				const addTwo = adder(2)
+				var result = addTwo(40)`,
+			expected: 42.0,
+		},
+		{
+			name: "nested functions",
+			input: `
+				fn outer() {
+					var total = 1
+					fn inner() {
+						total *= 10
+					}
+					inner()
+					inner()
+					return total
+				}
+				var result = outer()`,
+			expected: 100.0,
+		},
+		{
+			name: "constants can't be reassigned from a closure",
+			input: `
+				const result = 1
+				fn change() {
+					result = 2
+				}
+				change()`,
+			expectedErr: compilerErrors.ErrVariableIsConstant,
+		},
+		{
+			name:        "assigning an undeclared variable",
+			input:       "result = 1",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+	})
+}
+
+func TestBlockScoping(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "declaring a variable inside of a loop body",
+			input: `
+				var result = 0
+				for var i = 0; i < 3; i++ {
+					var double = i * 2
+					result += double
+				}`,
+			expected: 6.0,
+		},
+		{
+			name: "declaring a variable inside of a while body",
+			input: `
+				var result = 0
+				while result < 3 {
+					const next = result + 1
+					result = next
+				}`,
+			expected: 3.0,
+		},
+		{
+			name: "closures capture the loop variable of their iteration",
+			input: `
+				var fns = []
+				for var i = 0; i < 3; i++ {
+					push(fns, () => {
+						return i
+					})
+				}
+				const first = fns[0]
+				const last = fns[2]
+				var result = first() + last()`,
+			expected: 2.0,
+		},
+		{
+			name: "changes to the loop variable in the body are kept",
+			input: `
+				var result = 0
+				for var i = 0; i < 10; i++ {
+					i += 4
+					result++
+				}`,
+			expected: 2.0,
+		},
+		{
+			name:        "loop variables don't leak",
+			input:       "for var i = 0; i < 3; i++ {}\nvar result = i",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name:        "block variables don't leak",
+			input:       "if true { var x = 1 }\nvar result = x",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name: "inner declarations shadow outer ones",
+			input: `
+				var x = 1
+				var result = 0
+				if true {
+					var x = 2
+					result = x
+				}
+				result += x * 10`,
+			expected: 12.0,
+		},
+		{
+			name: "assignments change the nearest declaration",
+			input: `
+				var result = 1
+				if true {
+					result = 2
+					if true {
+						var result = 3
+						result = 4
+					}
+				}`,
+			expected: 2.0,
+		},
+		{
+			name: "switch cases have their own scope",
+			input: `
+				var result = 0
+				switch 1 {
+					case 1:
+						var x = 5
+						result = x
+				}
+				var x = 1
+				result += x`,
+			expected: 6.0,
+		},
+		{
+			name:        "redeclaring in the same scope",
+			input:       "if true {\nvar x = 1\nvar x = 2\n}",
+			expectedErr: compilerErrors.ErrVariableAlreadyExists,
+		},
+		{
+			name: "a block can shadow a constant",
+			input: `
+				const result = 1
+				if true {
+					var result = 2
+					result++
+				}`,
+			expected: 1.0,
+		},
+	})
+}
+
+func TestReferenceSemantics(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "nested property assignment",
+			input: `
+				var obj = { a: { b: { c: 0 } } }
+				obj.a.b.c = 1
+				var result = obj.a.b.c`,
+			expected: 1.0,
+		},
+		{
+			name: "nested index assignment",
+			input: `
+				var matrix = [[1, 2], [3, 4]]
+				matrix[1][0] = 9
+				var result = matrix[1][0]`,
+			expected: 9.0,
+		},
+		{
+			name: "assigning to an element of an object returned by a call",
+			input: `
+				const config = { values: [] }
+				fn getConfig() {
+					return config
+				}
+				var copy = getConfig()
+				copy.values[2] = "x"
+				var result = len(config.values)`,
+			expected: 3.0,
+		},
+		{
+			name: "functions can mutate their arguments",
+			input: `
+				fn rename(person) {
+					person.name = "Pika"
+				}
+				var person = { name: "Pikachu" }
+				rename(person)
+				var result = person.name`,
+			expected: "Pika",
+		},
+		{
+			name: "variables share the same array",
+			input: `
+				var a = [1, 2]
+				var b = a
+				b[0] = 10
+				var result = a[0]`,
+			expected: 10.0,
+		},
+		{
+			name: "push changes the array in place",
+			input: `
+				var arr = []
+				push(arr, 1, 2, 3)
+				var result = len(arr)`,
+			expected: 3.0,
+		},
+		{
+			name: "pop removes and returns the last element",
+			input: `
+				var arr = [1, 2, 3]
+				var result = pop(arr) * 10 + len(arr)`,
+			expected: 32.0,
+		},
+		{
+			name: "shift removes and returns the first element",
+			input: `
+				var arr = [1, 2, 3]
+				var result = shift(arr) * 10 + arr[0]`,
+			expected: 12.0,
+		},
+		{
+			name: "constants can still be mutated",
+			input: `
+				const obj = {}
+				obj.value = 1
+				var result = obj.value`,
+			expected: 1.0,
+		},
+		{
+			name: "compound assignment to members",
+			input: `
+				var obj = { count: 1, list: [2] }
+				obj.count += 4
+				obj.list[0] *= 3
+				var result = obj.count + obj.list[0]`,
+			expected: 11.0,
+		},
+		{
+			name:     "arrays are compared by identity",
+			input:    "var a = [1]\nvar b = a\nvar result = a == b && [1] != [1]",
+			expected: true,
+		},
+		{
+			name:     "indexOf finds references",
+			input:    "var inner = {}\nvar result = indexOf([1, inner], inner)",
+			expected: 1.0,
+		},
+		{
+			name:        "assigning a property of a number",
+			input:       "var n = 1\nn.x = 2",
+			expectedErr: compilerErrors.ErrSyntaxInvalidAssignment,
+		},
+	})
+}
+
+func TestResolution(t *testing.T) {
+	runEvalTests(t, []evalTest{
+		{
+			name: "undeclared variable in a function that never runs",
+			input: `
+				fn neverCalled() {
+					return missing
+				}
+				var result = 1`,
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name:        "assignment to a constant in a branch that never runs",
+			input:       "const a = 1\nif false {\na = 2\n}\nvar result = a",
+			expectedErr: compilerErrors.ErrVariableIsConstant,
+		},
+		{
+			name: "mutually recursive functions",
+			input: `
+				fn isEven(n) {
+					if n == 0 {
+						return true
+					}
+					return isOdd(n - 1)
+				}
+				fn isOdd(n) {
+					if n == 0 {
+						return false
+					}
+					return isEven(n - 1)
+				}
+				var result = isEven(10)`,
+			expected: true,
+		},
+		{
+			name: "function called before a variable it uses is declared",
+			input: `
+				fn f() {
+					return later
+				}
+				var result = f()
+				var later = 1`,
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name:     "variables of nested functions",
+			input:    "var a = 1\nfn outer(b) {\nfn inner(c) {\nreturn a + b + c\n}\nreturn inner(3)\n}\nvar result = outer(2)",
+			expected: 6.0,
+		},
+	})
+}
+
+// The REPL evaluates every line in the same environment
+func TestResolutionKeepsGlobals(t *testing.T) {
+	env := interpreter_env.New(nil)
+
+	for _, input := range []string{"const a = 1", "var b = a + 1", "var result = a + b"} {
+		program, err := parser.New().ProduceAST(input)
+
+		if err == nil {
+			_, err = interpreter_eval.Evaluate(*program, env)
+		}
+
+		if err != nil {
+			t.Fatalf("Expected no error evaluating %q, but got: %v", input, err)
+		}
+	}
+
+	result, err := env.LookupVar("result")
+
+	if err != nil || result.GetValue() != 3.0 {
+		t.Errorf("Expected result to be 3, but got: %v", result)
+	}
+
+	program, _ := parser.New().ProduceAST("a = 2")
+	_, err = interpreter_eval.Evaluate(*program, env)
+
+	if !errors.Is(err, compilerErrors.ErrVariableIsConstant) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrVariableIsConstant, err)
+	}
+}
@@ -1,54 +1,57 @@
 package interpreter_eval
 
 import (
-	"errors"
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
 	"github.com/Waxer59/PikaLang/pkg/ast"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
-	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval/internal/nativeFns"
 	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_ops"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+	"github.com/Waxer59/PikaLang/pkg/resolver"
 )
 
-func GetFunctionName(caller ast.CallExpr, env interpreter_env.Environment) (string, error) {
-	switch caller.Caller.(type) {
+/*
+ * Returns the name used to look up native functions and to report errors. It
+ * only depends on the syntax of the call, so a computed property gives a name
+ * only when it is a string literal.
+ */
+func GetFunctionName(caller ast.CallExpr) string {
+	switch fn := caller.Caller.(type) {
 	case ast.Identifier:
-		return caller.Caller.(ast.Identifier).Symbol, nil
+		return fn.Symbol
 	case ast.MemberExpr:
-		isComputed := caller.Caller.(ast.MemberExpr).Computed
-
-		if isComputed {
-			eval, err := Evaluate(caller.Caller.(ast.MemberExpr).Property, env)
-
-			if err != nil {
-				return "", err
-			}
-
-			switch v := eval.(type) {
-			case interpreter_env.StringVal:
-				return v.Value, nil
-			default:
-				return "", errors.New(compilerErrors.ErrComputedPropertyMustBeString)
-			}
+		if !fn.Computed {
+			return fn.Property.(ast.Identifier).Symbol
 		}
 
-		return caller.Caller.(ast.MemberExpr).Property.(ast.Identifier).Symbol, nil
-	default:
-		return "", nil
+		if property, ok := fn.Property.(ast.StringLiteral); ok {
+			return property.Value
+		}
 	}
+
+	return ""
 }
 
-func EvaluateBodyStmt(body []ast.Stmt, env interpreter_env.Environment) (interpreter_env.RuntimeValue, error) {
-	var lastEvaluated interpreter_env.RuntimeValue = interpreter_makers.MkNull()
+// Runs the statements of a block, it stops at the first one completing abruptly
+func EvaluateBodyStmt(body []ast.Stmt, env *interpreter_env.Environment) (Completion, error) {
+	completion := normalCompletion(nil)
 
 	for _, statement := range body {
-		eval, err := Evaluate(statement, env)
-		if err != nil {
-			return eval, err
+		var err error
+		completion, err = executeStmt(statement, env)
+
+		if err != nil || completion.IsAbrupt() {
+			return completion, err
 		}
-		lastEvaluated = eval
 	}
 
-	return lastEvaluated, nil
+	return completion, nil
+}
+
+// Runs the statements of a block in a new scope child of env
+func EvaluateBlockStmt(body []ast.Stmt, env *interpreter_env.Environment) (Completion, error) {
+	return EvaluateBodyStmt(body, interpreter_env.New(env))
 }
 
 /*
@@ -60,3 +63,68 @@ func IsNativeFunction(name string) (nativeFns.NativeFunction, bool) {
 
 	return function, ok
 }
+
+// Calls the host function or native function of the language with the given
+// name, the callbacks run by a native function are called from callSite
+func callNative(name string, args []interpreter_env.RuntimeValue, env *interpreter_env.Environment, callSite token_type.Span) (interpreter_env.RuntimeValue, error) {
+	if hostFn, ok := env.Realm().HostFunctions.Lookup(name); ok {
+		return hostFn.Call(args)
+	}
+
+	nativeFn, ok := IsNativeFunction(name)
+
+	if !ok {
+		return nil, compilerErrors.ErrNotAFunction.WithArgs(name)
+	}
+
+	realm := env.Realm()
+
+	if err := nativeFns.CheckCapability(name, realm.Capabilities); err != nil {
+		return nil, err
+	}
+
+	grown := interpreter_ops.ArrayLengths(args)
+	call := func(fn interpreter_env.RuntimeValue, args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+		return callValue(fn, valueName(fn), args, callSite)
+	}
+
+	result, err := nativeFn(args, &nativeFns.Runtime{Call: call, Capabilities: realm.Capabilities, Stdio: realm.Stdio})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return interpreter_makers.MkNull(), nil
+	}
+
+	return result, interpreter_ops.ChargeNative(args, grown, result, realm.Guard)
+}
+
+// The slots reserved for variables of an earlier program that stopped before
+// declaring them keep their place without a name, so no identifier is bound to them
+func declaredGlobals(env *interpreter_env.Environment) []resolver.Global {
+	names := env.Names()
+	globals := make([]resolver.Global, len(names))
+
+	for slot, name := range names {
+		if env.IsDeclared(slot) {
+			globals[slot] = resolver.Global{Name: name, Constant: env.IsConstant(slot)}
+		}
+	}
+
+	return globals
+}
+
+// Reserves the slots the resolver gave to the global variables of the
+// program, in the order they are declared
+func reserveGlobals(program ast.Program, env *interpreter_env.Environment) {
+	for _, stmt := range program.Body {
+		switch declaration := stmt.(type) {
+		case ast.VariableDeclaration:
+			env.Reserve(declaration.Identifier, declaration.Constant)
+		case ast.FunctionDeclaration:
+			env.Reserve(declaration.Name, true)
+		}
+	}
+}
@@ -39,9 +39,16 @@ func MkNan() interpreter_env.NaNVal {
 	}
 }
 
-func MkArray(a []interpreter_env.RuntimeValue) interpreter_env.ArrayVal {
-	return interpreter_env.ArrayVal{
+func MkArray(a []interpreter_env.RuntimeValue) *interpreter_env.ArrayVal {
+	return &interpreter_env.ArrayVal{
 		Type:     interpreter_env.Array,
 		Elements: a,
 	}
 }
+
+func MkObject(properties map[string]interpreter_env.RuntimeValue) *interpreter_env.ObjectVal {
+	return &interpreter_env.ObjectVal{
+		Type:       interpreter_env.Object,
+		Properties: properties,
+	}
+}
@@ -0,0 +1,432 @@
+/*
+ * Package interpreter_ops holds the semantics of the operators of the
+ * language on runtime values. They are shared by the tree-walking
+ * interpreter and the virtual machine so both give the same results.
+ */
+package interpreter_ops
+
+import (
+	"fmt"
+	"math"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+	"golang.org/x/exp/slices"
+)
+
+// Maps every compound assignment operator to its binary operator
+var compoundAssignmentOperators = map[string]string{
+	"+=":   "+",
+	"-=":   "-",
+	"*=":   "*",
+	"/=":   "/",
+	"%=":   "%",
+	"**=":  "**",
+	"&=":   "&",
+	"|=":   "|",
+	"^=":   "^",
+	"<<=":  "<<",
+	">>=":  ">>",
+	">>>=": ">>>",
+}
+
+/*
+ * Returns the binary operator applied by a compound assignment operator.
+ * The second return value is false for a plain assignment.
+ */
+func CompoundOperator(assignmentOperator string) (string, bool) {
+	operator, ok := compoundAssignmentOperators[assignmentOperator]
+
+	return operator, ok
+}
+
+func Binary(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	// EVAL < < >= <= == !=
+	if slices.Contains(ast_types.BoolExpr, operator) {
+		return comparison(operator, lhs, rhs), nil
+	}
+
+	// EVAL + - * / % ** & | ^ << >> >>> (numbers)
+	if lhs.GetType() == interpreter_env.Number && rhs.GetType() == interpreter_env.Number {
+		return numeric(operator, lhs, rhs)
+	}
+
+	// EVAL + (strings)
+	if lhs.GetType() == interpreter_env.String && rhs.GetType() == interpreter_env.String {
+		return str(operator, lhs, rhs)
+	}
+
+	return interpreter_makers.MkNull(), nil
+}
+
+func str(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	result := ""
+	valLhs, okLhs := lhs.(interpreter_env.StringVal)
+	valRhs, okRhs := rhs.(interpreter_env.StringVal)
+	if !okLhs || !okRhs {
+		return nil, compilerErrors.ErrBinaryInvalidBinaryExpr
+	}
+	switch operator {
+	case "+":
+		result = valLhs.Value + valRhs.Value
+	}
+
+	return interpreter_makers.MkString(result), nil
+}
+
+func numeric(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	valLhs, okLhs := lhs.(interpreter_env.NumberVal)
+	valRhs, okRhs := rhs.(interpreter_env.NumberVal)
+
+	if !okLhs || !okRhs {
+		return nil, compilerErrors.ErrBinaryInvalidBinaryExpr
+	}
+
+	result, err := Arithmetic(operator, valLhs.Value, valRhs.Value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return interpreter_makers.MkNumber(result), nil
+}
+
+// Applies an arithmetic or bitwise operator to two numbers
+func Arithmetic(operator string, lhs float64, rhs float64) (float64, error) {
+	switch operator {
+	case "+":
+		return lhs + rhs, nil
+	case "-":
+		return lhs - rhs, nil
+	case "*":
+		return lhs * rhs, nil
+	case "/":
+		return lhs / rhs, nil
+	case "%":
+		if int(rhs) == 0 {
+			return 0, compilerErrors.ErrBinaryDivisionByZero
+		}
+		return float64(int(lhs) % int(rhs)), nil
+	case "**":
+		return math.Pow(lhs, rhs), nil
+	case "&":
+		return float64(int32Of(lhs) & int32Of(rhs)), nil
+	case "|":
+		return float64(int32Of(lhs) | int32Of(rhs)), nil
+	case "^":
+		return float64(int32Of(lhs) ^ int32Of(rhs)), nil
+	case "<<":
+		return float64(int32Of(lhs) << shiftCount(rhs)), nil
+	case ">>":
+		return float64(int32Of(lhs) >> shiftCount(rhs)), nil
+	case ">>>":
+		return float64(uint32(int32Of(lhs)) >> shiftCount(rhs)), nil
+	}
+
+	return 0, nil
+}
+
+/*
+ * Returns the integer part of a number as the 32 bits integer the bitwise
+ * operators work on, like JavaScript does. Every 32 bits integer fits in a
+ * number, so the results are exact. Numbers out of its range wrap around,
+ * NaN and the infinities are 0.
+ */
+func int32Of(number float64) int32 {
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0
+	}
+
+	wrapped := math.Mod(math.Trunc(number), 1<<32)
+
+	if wrapped < 0 {
+		wrapped += 1 << 32
+	}
+
+	return int32(uint32(wrapped))
+}
+
+// Bits shifted by a shift operator, only the lowest 5 bits of the count are used
+func shiftCount(number float64) uint32 {
+	return uint32(int32Of(number)) & 31
+}
+
+func comparison(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) interpreter_env.RuntimeValue {
+	var result = false
+	numValLhs, _ := lhs.(interpreter_env.NumberVal)
+	numValRhs, _ := rhs.(interpreter_env.NumberVal)
+
+	switch operator {
+	case "==":
+		result = interpreter_env.Equals(lhs, rhs)
+	case "!=":
+		result = !interpreter_env.Equals(lhs, rhs)
+	case "<":
+		result = numValLhs.Value < numValRhs.Value
+	case ">":
+		result = numValLhs.Value > numValRhs.Value
+	case "<=":
+		result = numValLhs.Value <= numValRhs.Value
+	case ">=":
+		result = numValLhs.Value >= numValRhs.Value
+	}
+	return interpreter_makers.MkBoolean(result)
+}
+
+// Both operands are always evaluated, the result is a boolean
+func Logical(operator string, lhs interpreter_env.RuntimeValue, rhs interpreter_env.RuntimeValue) interpreter_env.RuntimeValue {
+	result := false
+	valLhs := nativeFns.EvaluateTruthyFalsyValues(lhs)
+	valRhs := nativeFns.EvaluateTruthyFalsyValues(rhs)
+
+	switch operator {
+	case "&&":
+		result = valLhs && valRhs
+	case "||":
+		result = valLhs || valRhs
+	}
+
+	return interpreter_makers.MkBoolean(result)
+}
+
+func Unary(operator string, value interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	switch operator {
+	case "!":
+		return interpreter_makers.MkBoolean(!nativeFns.EvaluateTruthyFalsyValues(value)), nil
+	case "+", "-", "~":
+		number, ok := value.(interpreter_env.NumberVal)
+		if !ok {
+			return nil, compilerErrors.ErrSyntaxUnaryInvalidUnaryExpr
+		}
+		switch operator {
+		case "-":
+			return interpreter_makers.MkNumber(-number.Value), nil
+		case "~":
+			return interpreter_makers.MkNumber(float64(^int32Of(number.Value))), nil
+		}
+		return number, nil
+	default:
+		return interpreter_makers.MkNull(), nil
+	}
+}
+
+// Returns the value after applying the '++' or '--' operator
+func Update(operator string, value interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	number, ok := value.(interpreter_env.NumberVal)
+
+	if !ok {
+		return nil, compilerErrors.ErrSyntaxInvalidUpdateExpr
+	}
+
+	switch operator {
+	case "++":
+		return interpreter_makers.MkNumber(number.Value + 1), nil
+	case "--":
+		return interpreter_makers.MkNumber(number.Value - 1), nil
+	default:
+		return nil, compilerErrors.ErrSyntaxInvalidUpdateExpr
+	}
+}
+
+// Tells if a case of a switch statement matches its discriminant
+func CaseMatches(test interpreter_env.RuntimeValue, discriminant interpreter_env.RuntimeValue) bool {
+	return interpreter_env.Equals(test, discriminant) || test.GetValue() == true
+}
+
+// Accesses a property with the dot notation, missing properties are null
+func GetProperty(obj interpreter_env.RuntimeValue, name string) (interpreter_env.RuntimeValue, error) {
+	properties, ok := obj.GetValue().(map[string]interpreter_env.RuntimeValue)
+
+	if !ok {
+		return nil, compilerErrors.ErrPropertyNotFound
+	}
+
+	val, ok := properties[name]
+
+	// If the property doesn't exist return null
+	if !ok {
+		return interpreter_makers.MkNull(), nil
+	}
+	return val, nil
+}
+
+// Accesses an element of an array or string, or a property of an object, with the bracket notation
+func GetIndex(obj interpreter_env.RuntimeValue, key interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	switch val := obj.(type) {
+	case *interpreter_env.ArrayVal:
+		idx, err := parseIndex(key, len(val.Elements))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return val.Elements[idx], nil
+	case interpreter_env.StringVal:
+		idx, err := parseIndex(key, len(val.Value))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return interpreter_makers.MkString(string(val.Value[idx])), nil
+	case *interpreter_env.ObjectVal:
+		property, ok := val.Properties[fmt.Sprint(key.GetValue())]
+
+		if !ok {
+			return nil, compilerErrors.ErrPropertyNotFound
+		}
+		return property, nil
+	}
+
+	return nil, compilerErrors.ErrIndexNotFound
+}
+
+// Converts an index to a position of a sequence of the given length, negative indexes count from the end
+func parseIndex(index interpreter_env.RuntimeValue, length int) (int, error) {
+	if index.GetType() != interpreter_env.Number {
+		return 0, compilerErrors.ErrInvalidIndex
+	}
+
+	idx := int(index.GetValue().(float64))
+
+	if idx < 0 {
+		idx = length + idx
+	}
+
+	if idx < 0 {
+		return 0, compilerErrors.ErrInvalidIndex
+	}
+
+	if idx >= length {
+		return 0, compilerErrors.ErrIndexNotFound
+	}
+
+	return idx, nil
+}
+
+/*
+ * Assigns a property of an object or an element of an array with the given
+ * assignment operator. The object is changed in place, so every reference
+ * to it sees the new value. The memory the object grows by is counted by
+ * the guard before it grows.
+ */
+func SetMember(obj interpreter_env.RuntimeValue, key interpreter_env.RuntimeValue, value interpreter_env.RuntimeValue, assignmentOperator string, guard *interpreter_env.Guard) (interpreter_env.RuntimeValue, error) {
+	var err error
+	operator, isCompound := CompoundOperator(assignmentOperator)
+
+	switch container := obj.(type) {
+	case *interpreter_env.ObjectVal:
+		property := fmt.Sprint(key.GetValue())
+
+		current, exists := container.Properties[property]
+
+		if !exists {
+			if err := guard.Allocate(interpreter_env.PropertySize + len(property)); err != nil {
+				return nil, err
+			}
+		}
+
+		if isCompound {
+
+			if !exists {
+				current = interpreter_makers.MkNull()
+			}
+
+			value, err = Binary(operator, current, value)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		container.Properties[property] = value
+	case *interpreter_env.ArrayVal:
+		if key.GetType() != interpreter_env.Number {
+			return nil, compilerErrors.ErrSyntaxInvalidAssignment
+		}
+
+		number := key.GetValue().(float64)
+
+		if math.Mod(number, 1) != 0 { // Check if is a float number
+			return nil, compilerErrors.ErrSyntaxInvalidAssignment
+		}
+
+		idx := int(number)
+
+		if idx < 0 {
+			idx = len(container.Elements) + idx
+
+			if idx < 0 {
+				return nil, compilerErrors.ErrSyntaxInvalidAssignment
+			}
+		}
+
+		// Assigning past the end fills the gap with nulls
+		if growth := idx + 1 - len(container.Elements); growth > 0 {
+			if err := guard.Allocate(growth * interpreter_env.SlotSize); err != nil {
+				return nil, err
+			}
+		}
+
+		for len(container.Elements) <= idx {
+			container.Elements = append(container.Elements, interpreter_makers.MkNull())
+		}
+
+		if isCompound {
+			value, err = Binary(operator, container.Elements[idx], value)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		container.Elements[idx] = value
+	default:
+		return nil, compilerErrors.ErrSyntaxInvalidAssignment
+	}
+
+	return value, nil
+}
+
+// Returns the length of every array in args, taken before a native function
+// runs to give to ChargeNative, -1 for the other values
+func ArrayLengths(args []interpreter_env.RuntimeValue) []int {
+	lengths := make([]int, len(args))
+
+	for idx, arg := range args {
+		lengths[idx] = -1
+
+		if arr, ok := arg.(*interpreter_env.ArrayVal); ok {
+			lengths[idx] = len(arr.Elements)
+		}
+	}
+
+	return lengths
+}
+
+// Counts the memory of the value returned by a native function when it is a
+// new value, and of the elements it added to the arrays it was given
+func ChargeNative(args []interpreter_env.RuntimeValue, lengths []int, result interpreter_env.RuntimeValue, guard *interpreter_env.Guard) error {
+	isNew := true
+
+	for idx, arg := range args {
+		if arr, ok := arg.(*interpreter_env.ArrayVal); ok {
+			if growth := len(arr.Elements) - lengths[idx]; growth > 0 {
+				if err := guard.Allocate(growth * interpreter_env.SlotSize); err != nil {
+					return err
+				}
+			}
+
+			isNew = isNew && result != interpreter_env.RuntimeValue(arr)
+		}
+	}
+
+	if !isNew {
+		return nil
+	}
+
+	return guard.Allocate(interpreter_env.SizeOf(result))
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 	"golang.org/x/exp/slices"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -26,41 +27,6 @@ func NextChar(src *[]rune) string {
 	return string(char)
 }
 
-/*  FirstReturn: Number extracted
- * 	SecondReturn: Rest of the string
- */
-func ExtractNum(src []rune) (string, []rune) {
-	if len(src) <= 0 {
-		return "", src
-	}
-
-	num := ""
-
-	for len(src) > 0 && (IsInt(src[0]) || src[0] == '.') {
-		num += NextChar(&src)
-	}
-
-	return num, src
-}
-
-func ExtractString(src []rune) (string, []rune) {
-	if len(src) <= 0 {
-		return "", src
-	}
-
-	var str string
-	for len(src) > 0 && src[0] != '"' {
-		if src[0] == '\\' {
-			NextChar(&src) // consume '\'
-			str += NextChar(&src)
-		} else {
-			str += NextChar(&src)
-		}
-	}
-
-	return str, src
-}
-
 /*  FirstReturn: String extracted
  * 	SecondReturn: Rest of the string
  */
@@ -95,3 +61,33 @@ func IsInt(char rune) bool {
 	val, err := strconv.ParseFloat(string(char), 64)
 	return err == nil && val >= 0
 }
+
+// Returns the offset of the first rune of every line in src
+func LineStarts(src []rune) []int {
+	starts := []int{0}
+
+	for idx, char := range src {
+		if char == '\n' {
+			starts = append(starts, idx+1)
+		}
+	}
+
+	return starts
+}
+
+// Converts a rune offset into a line and column using the result of LineStarts
+func PositionAt(lineStarts []int, offset int) token_type.Position {
+	line := sort.Search(len(lineStarts), func(i int) bool {
+		return lineStarts[i] > offset
+	}) - 1
+
+	if line < 0 {
+		line = 0
+	}
+
+	return token_type.Position{
+		Offset: offset,
+		Line:   line + 1,
+		Column: offset - lineStarts[line] + 1,
+	}
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"reflect"
 	"testing"
+
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 )
 
 func TestIsSkippable(t *testing.T) {
@@ -49,30 +51,6 @@ func TestNextChar(t *testing.T) {
 	}
 }
 
-func TestExtractNum(t *testing.T) {
-	tests := []struct {
-		src         []rune
-		expectedNum string
-		expectedSrc []rune
-	}{
-		{[]rune{'1', '2', '3', 'a', '4', '5'}, "123", []rune{'a', '4', '5'}},
-		{[]rune{'-', '5', '.', '6'}, "", []rune{'-', '5', '.', '6'}},
-		{[]rune{}, "", []rune{}},
-	}
-
-	for _, test := range tests {
-		resultNum, resultSrc := ExtractNum(test.src)
-
-		if resultNum != test.expectedNum {
-			t.Errorf("Expected ExtractNum(%v) to return '%s', but got '%s'", test.src, test.expectedNum, resultNum)
-		}
-
-		if !reflect.DeepEqual(resultSrc, test.expectedSrc) {
-			t.Errorf("Expected source to be %v, but got %v", test.expectedSrc, resultSrc)
-		}
-	}
-}
-
 func TestExtractIdentifier(t *testing.T) {
 	tests := []struct {
 		src         []rune
@@ -154,3 +132,25 @@ func TestIsInt(t *testing.T) {
 		}
 	}
 }
+
+func TestPositionAt(t *testing.T) {
+	lineStarts := LineStarts([]rune("ab\ncd\n\nef"))
+
+	tests := []struct {
+		offset   int
+		expected token_type.Position
+	}{
+		{0, token_type.Position{Offset: 0, Line: 1, Column: 1}},
+		{2, token_type.Position{Offset: 2, Line: 1, Column: 3}},
+		{3, token_type.Position{Offset: 3, Line: 2, Column: 1}},
+		{7, token_type.Position{Offset: 7, Line: 4, Column: 1}},
+		{9, token_type.Position{Offset: 9, Line: 4, Column: 3}},
+	}
+
+	for _, test := range tests {
+		result := PositionAt(lineStarts, test.offset)
+		if result != test.expected {
+			t.Errorf("Expected PositionAt(%d) to be %v, but got %v", test.offset, test.expected, result)
+		}
+	}
+}
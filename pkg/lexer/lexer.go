@@ -1,15 +1,50 @@
 package lexer
 
 import (
-	"errors"
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 	"github.com/Waxer59/PikaLang/pkg/lexer/internal/utils"
 	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 )
 
+// A template literal whose substitution is being lexed
+type template struct {
+	// Offset of the backtick that opens the template
+	start int
+	// Braces opened inside the substitution and not closed yet
+	braces int
+}
+
 func Tokenize(input string) ([]token_type.Token, error) {
+	return TokenizeFile("", input)
+}
+
+// Same as Tokenize but every token span is tagged with the given file name
+func TokenizeFile(fileName string, input string) ([]token_type.Token, error) {
 	var tokens []token_type.Token
-	src := []rune(input)
+	runes := []rune(input)
+	src := runes
+	lineStarts := utils.LineStarts(runes)
+
+	offset := func() int {
+		return len(runes) - len(src)
+	}
+
+	span := func(start int, end int) token_type.Span {
+		return token_type.Span{
+			File:  fileName,
+			Start: utils.PositionAt(lineStarts, start),
+			End:   utils.PositionAt(lineStarts, end),
+		}
+	}
+
+	// Template literals whose substitution is being lexed, the innermost goes last
+	var templates []template
+
+	start := 0
+	// Every token but string and number literals spans exactly its value from the start of the iteration
+	addToken := func(tokenType token_type.TokenType, value string) {
+		tokens = append(tokens, token_type.Token{Type: tokenType, Value: value, Span: span(start, start+len([]rune(value)))})
+	}
 	subtract := func(i int) rune {
 		if len(src) <= 0 {
 			return 0
@@ -28,6 +63,7 @@ func Tokenize(input string) ([]token_type.Token, error) {
 
 	for len(src) > 0 {
 		tokenChar := src[0]
+		start = offset()
 
 		// Check if token is skippable
 		if utils.IsSkippable(tokenChar) {
@@ -37,9 +73,14 @@ func Tokenize(input string) ([]token_type.Token, error) {
 
 		// Check for number
 		if utils.IsInt(tokenChar) {
-			num, rest := utils.ExtractNum(src)
-			tokens = append(tokens, token_type.Token{Type: token_type.Number, Value: num})
-			src = rest
+			num, length, err := scanNumber(src, start, span)
+
+			if err != nil {
+				return nil, err
+			}
+
+			src = src[length:]
+			tokens = append(tokens, token_type.Token{Type: token_type.Number, Value: num, Span: span(start, offset())})
 			continue
 		}
 
@@ -52,7 +93,7 @@ func Tokenize(input string) ([]token_type.Token, error) {
 				alphaType = keyword
 			}
 
-			tokens = append(tokens, token_type.Token{Type: alphaType, Value: alpha})
+			addToken(alphaType, alpha)
 
 			src = rest
 			continue
@@ -63,42 +104,42 @@ func Tokenize(input string) ([]token_type.Token, error) {
 		case '+':
 			if nextChar() == '=' {
 				subtract(2) // consume ' += '
-				tokens = append(tokens, token_type.Token{Type: token_type.PlusEquals, Value: string(tokenChar) + "="})
+				addToken(token_type.PlusEquals, string(tokenChar)+"=")
 				continue
 			}
 
 			if nextChar() == '+' {
 				subtract(2) // consume ' ++ '
-				tokens = append(tokens, token_type.Token{Type: token_type.Increment, Value: "++"})
+				addToken(token_type.Increment, "++")
 				continue
 			}
 
-			tokens = append(tokens, token_type.Token{Type: token_type.BinaryOperator, Value: string(tokenChar)})
+			addToken(token_type.BinaryOperator, string(tokenChar))
 		case '%':
 			if nextChar() == '=' {
 				subtract(2) // consume ' %= '
-				tokens = append(tokens, token_type.Token{Type: token_type.ModuleEquals, Value: string(tokenChar) + "="})
+				addToken(token_type.ModuleEquals, string(tokenChar)+"=")
 				continue
 			}
-			tokens = append(tokens, token_type.Token{Type: token_type.BinaryOperator, Value: string(tokenChar)})
+			addToken(token_type.BinaryOperator, string(tokenChar))
 		case '-':
 			if nextChar() == '=' {
 				subtract(2) // consume ' -= '
-				tokens = append(tokens, token_type.Token{Type: token_type.MinusEquals, Value: string(tokenChar) + "="})
+				addToken(token_type.MinusEquals, string(tokenChar)+"=")
 				continue
 			}
 
 			if nextChar() == '-' {
 				subtract(2) // consume ' -- '
-				tokens = append(tokens, token_type.Token{Type: token_type.Decrement, Value: "--"})
+				addToken(token_type.Decrement, "--")
 				continue
 			}
 
-			tokens = append(tokens, token_type.Token{Type: token_type.BinaryOperator, Value: string(tokenChar)})
+			addToken(token_type.BinaryOperator, string(tokenChar))
 		case '*':
 			if nextChar() == '=' {
 				subtract(2) // consume ' *= '
-				tokens = append(tokens, token_type.Token{Type: token_type.TimesEquals, Value: string(tokenChar) + "="})
+				addToken(token_type.TimesEquals, string(tokenChar)+"=")
 				continue
 			}
 
@@ -106,14 +147,14 @@ func Tokenize(input string) ([]token_type.Token, error) {
 				subtract(2) // consume ' ** '
 				if src[0] == '=' {
 					subtract(1) // advance '='
-					tokens = append(tokens, token_type.Token{Type: token_type.PowerEquals, Value: "**="})
+					addToken(token_type.PowerEquals, "**=")
 					continue
 				}
-				tokens = append(tokens, token_type.Token{Type: token_type.BinaryOperator, Value: "**"})
+				addToken(token_type.BinaryOperator, "**")
 				continue
 			}
 
-			tokens = append(tokens, token_type.Token{Type: token_type.BinaryOperator, Value: string(tokenChar)})
+			addToken(token_type.BinaryOperator, string(tokenChar))
 		case '/':
 			switch nextChar() {
 			case '/':
@@ -129,105 +170,218 @@ func Tokenize(input string) ([]token_type.Token, error) {
 				for len(src) > 0 && src[0] != '*' && nextChar() != '/' {
 					subtract(1)
 					if len(src) <= 1 { // if the comment is not terminated
-						return nil, errors.New(compilerErrors.ErrSyntaxUnterminatedMultilineComment)
+						return nil, compilerErrors.ErrSyntaxUnterminatedMultilineComment.At(span(start, offset()))
 					}
 				}
 				subtract(2) // consume */
 			case '=':
 				subtract(2) // consume '/='
-				tokens = append(tokens, token_type.Token{Type: token_type.DivideEquals, Value: "/="})
+				addToken(token_type.DivideEquals, "/=")
 			default:
-				tokens = append(tokens, token_type.Token{Type: token_type.BinaryOperator, Value: string(tokenChar)})
+				addToken(token_type.BinaryOperator, string(tokenChar))
 			}
 		case '=':
 			switch nextChar() {
 			case '=':
 				subtract(2) // consume '=='
-				tokens = append(tokens, token_type.Token{Type: token_type.EqualEqual, Value: "=="})
+				addToken(token_type.EqualEqual, "==")
 				continue
 			case '>':
 				subtract(2) // consume '=>'
-				tokens = append(tokens, token_type.Token{Type: token_type.Arrow, Value: "=>"})
+				addToken(token_type.Arrow, "=>")
 				continue
 			default:
-				tokens = append(tokens, token_type.Token{Type: token_type.Equals, Value: string(tokenChar)})
+				addToken(token_type.Equals, string(tokenChar))
 			}
 		case '!':
 			if nextChar() == '=' {
 				subtract(2) // consume '!='
-				tokens = append(tokens, token_type.Token{Type: token_type.NotEqual, Value: "!="})
+				addToken(token_type.NotEqual, "!=")
 				continue
 			}
-			tokens = append(tokens, token_type.Token{Type: token_type.Bang, Value: string(tokenChar)})
+			addToken(token_type.Bang, string(tokenChar))
 		case '>':
 			if nextChar() == '=' {
 				subtract(2) // consume '>='
-				tokens = append(tokens, token_type.Token{Type: token_type.GreaterEqual, Value: ">="})
+				addToken(token_type.GreaterEqual, ">=")
 				continue
 			}
-			tokens = append(tokens, token_type.Token{Type: token_type.Greater, Value: string(tokenChar)})
+
+			if nextChar() == '>' { // Check for shifts
+				subtract(2) // consume '>>'
+
+				operator := ">>"
+				if len(src) > 0 && src[0] == '>' {
+					subtract(1) // advance '>'
+					operator = ">>>"
+				}
+
+				if len(src) > 0 && src[0] == '=' {
+					subtract(1) // advance '='
+
+					assignmentType := token_type.ShiftRightEquals
+					if operator == ">>>" {
+						assignmentType = token_type.UnsignedShiftRightEquals
+					}
+
+					addToken(assignmentType, operator+"=")
+					continue
+				}
+
+				addToken(token_type.BinaryOperator, operator)
+				continue
+			}
+
+			addToken(token_type.Greater, string(tokenChar))
 		case '<':
 			if nextChar() == '=' {
 				subtract(2) // consume '<='
-				tokens = append(tokens, token_type.Token{Type: token_type.LessEqual, Value: "<="})
+				addToken(token_type.LessEqual, "<=")
 				continue
 			}
-			tokens = append(tokens, token_type.Token{Type: token_type.Less, Value: string(tokenChar)})
+
+			if nextChar() == '<' {
+				subtract(2) // consume '<<'
+				if len(src) > 0 && src[0] == '=' {
+					subtract(1) // advance '='
+					addToken(token_type.ShiftLeftEquals, "<<=")
+					continue
+				}
+				addToken(token_type.BinaryOperator, "<<")
+				continue
+			}
+
+			addToken(token_type.Less, string(tokenChar))
 		case ';':
-			tokens = append(tokens, token_type.Token{Type: token_type.Semicolon, Value: string(tokenChar)})
+			addToken(token_type.Semicolon, string(tokenChar))
 		case '?':
-			tokens = append(tokens, token_type.Token{Type: token_type.QuestionMark, Value: string(tokenChar)})
+			addToken(token_type.QuestionMark, string(tokenChar))
 		case '(':
-			tokens = append(tokens, token_type.Token{Type: token_type.LeftParen, Value: string(tokenChar)})
+			addToken(token_type.LeftParen, string(tokenChar))
 		case ')':
-			tokens = append(tokens, token_type.Token{Type: token_type.RightParen, Value: string(tokenChar)})
+			addToken(token_type.RightParen, string(tokenChar))
 		case '{':
-			tokens = append(tokens, token_type.Token{Type: token_type.LeftBrace, Value: string(tokenChar)})
+			if len(templates) > 0 {
+				templates[len(templates)-1].braces++
+			}
+			addToken(token_type.LeftBrace, string(tokenChar))
 		case '}':
-			tokens = append(tokens, token_type.Token{Type: token_type.RightBrace, Value: string(tokenChar)})
+			if len(templates) > 0 {
+				innermost := &templates[len(templates)-1]
+
+				if innermost.braces == 0 { // Back to the text of the template
+					templateStart := innermost.start
+					templates = templates[:len(templates)-1]
+
+					str, length, open, err := scanTemplate(src, start, templateStart, span)
+
+					if err != nil {
+						return nil, err
+					}
+
+					tokenType := token_type.TemplateTail
+					if open {
+						tokenType = token_type.TemplateMiddle
+						templates = append(templates, template{start: templateStart})
+					}
+
+					src = src[length:]
+					tokens = append(tokens, token_type.Token{Type: tokenType, Value: str, Span: span(start, offset())})
+					continue
+				}
+
+				innermost.braces--
+			}
+			addToken(token_type.RightBrace, string(tokenChar))
 		case '[':
-			tokens = append(tokens, token_type.Token{Type: token_type.LeftBracket, Value: string(tokenChar)})
+			addToken(token_type.LeftBracket, string(tokenChar))
 		case ']':
-			tokens = append(tokens, token_type.Token{Type: token_type.RightBracket, Value: string(tokenChar)})
+			addToken(token_type.RightBracket, string(tokenChar))
 		case ',':
-			tokens = append(tokens, token_type.Token{Type: token_type.Comma, Value: string(tokenChar)})
+			addToken(token_type.Comma, string(tokenChar))
 		case ':':
-			tokens = append(tokens, token_type.Token{Type: token_type.Colon, Value: string(tokenChar)})
+			addToken(token_type.Colon, string(tokenChar))
 		case '.':
 			if utils.IsInt(nextChar()) { // Check for decimal numbers as .123 == 0.123
-				num, rest := utils.ExtractNum(src)
-				tokens = append(tokens, token_type.Token{Type: token_type.Number, Value: num})
-				src = rest
+				num, length, err := scanNumber(src, start, span)
+
+				if err != nil {
+					return nil, err
+				}
+
+				src = src[length:]
+				tokens = append(tokens, token_type.Token{Type: token_type.Number, Value: num, Span: span(start, offset())})
 				continue
 			}
-			tokens = append(tokens, token_type.Token{Type: token_type.Dot, Value: string(tokenChar)})
-		case '"':
-			tokens = append(tokens, token_type.Token{Type: token_type.DoubleQuote, Value: string(tokenChar)})
+			addToken(token_type.Dot, string(tokenChar))
+		case '`':
+			str, length, open, err := scanTemplate(src, start, start, span)
 
-			if utils.IsAlpha(nextChar()) {
-				subtract(1) // consume '"'
-				str, rest := utils.ExtractString(src)
-				tokens = append(tokens, token_type.Token{Type: token_type.StringLiteral, Value: str})
-				src = rest
-				continue
+			if err != nil {
+				return nil, err
+			}
+
+			tokenType := token_type.Template
+			if open {
+				tokenType = token_type.TemplateHead
+				templates = append(templates, template{start: start})
 			}
-		case '\'':
-			tokens = append(tokens, token_type.Token{Type: token_type.SingleQuote, Value: string(tokenChar)})
+
+			src = src[length:]
+			tokens = append(tokens, token_type.Token{Type: tokenType, Value: str, Span: span(start, offset())})
+			continue
+		case '"', '\'':
+			str, length, err := scanString(src, start, span)
+
+			if err != nil {
+				return nil, err
+			}
+
+			src = src[length:]
+			tokens = append(tokens, token_type.Token{Type: token_type.StringLiteral, Value: str, Span: span(start, offset())})
+			continue
 		case '|':
-			if nextChar() == '|' {
+			switch nextChar() {
+			case '|':
 				subtract(2) // consume '||'
-				tokens = append(tokens, token_type.Token{Type: token_type.Or, Value: "||"})
+				addToken(token_type.Or, "||")
+				continue
+			case '=':
+				subtract(2) // consume '|='
+				addToken(token_type.OrEquals, "|=")
+				continue
 			}
+			addToken(token_type.BinaryOperator, string(tokenChar))
 		case '&':
-			if nextChar() == '&' {
+			switch nextChar() {
+			case '&':
 				subtract(2) // consume '&&'
-				tokens = append(tokens, token_type.Token{Type: token_type.And, Value: "&&"})
+				addToken(token_type.And, "&&")
+				continue
+			case '=':
+				subtract(2) // consume '&='
+				addToken(token_type.AndEquals, "&=")
+				continue
 			}
+			addToken(token_type.BinaryOperator, string(tokenChar))
+		case '^':
+			if nextChar() == '=' {
+				subtract(2) // consume '^='
+				addToken(token_type.XorEquals, "^=")
+				continue
+			}
+			addToken(token_type.BinaryOperator, string(tokenChar))
+		case '~':
+			addToken(token_type.Tilde, string(tokenChar))
 		default:
-			tokens = append(tokens, token_type.Token{Type: token_type.Identifier, Value: string(tokenChar)})
+			addToken(token_type.Identifier, string(tokenChar))
 		}
 		subtract(1)
 	}
-	tokens = append(tokens, token_type.Token{Type: token_type.EOF, Value: "EndOfFile"})
+	if len(templates) > 0 {
+		return nil, unterminatedTemplate(span(templates[len(templates)-1].start, len(runes)))
+	}
+
+	tokens = append(tokens, token_type.Token{Type: token_type.EOF, Value: "EndOfFile", Span: span(len(runes), len(runes))})
 	return tokens, nil
 }
@@ -0,0 +1,177 @@
+package lexer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/lexer/internal/utils"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+// A base that numbers can be written in with a prefix like 0x
+type numberBase struct {
+	base int
+	name string
+}
+
+// Bases of the numbers written with a prefix, by the letter of the prefix
+var numberBases = map[rune]numberBase{
+	'x': {16, "hexadecimal"},
+	'o': {8, "octal"},
+	'b': {2, "binary"},
+}
+
+/*
+ * Scans the number at the start of src, which is at the given offset of the
+ * file. Numbers are decimal, with an optional fraction and exponent (1.5e-3),
+ * or integers with a base prefix (0xFF, 0o755, 0b1010), and their digits can
+ * be separated with '_'. It returns the value of the number written in a way
+ * strconv.ParseFloat reads and the number of runes of the literal.
+ */
+func scanNumber(src []rune, offset int, span func(int, int) token_type.Span) (string, int, error) {
+	invalid := func(start int, end int, help string) error {
+		return compilerErrors.ErrSyntaxInvalidNumber.
+			WithArgs(string(src[:literalLength(src)])).
+			At(span(offset+start, offset+end)).
+			WithHelp(help)
+	}
+
+	// Reads the digits from idx on, which can only be separated by single underscores
+	digits := func(idx int, isDigit func(rune) bool) (int, error) {
+		for ; idx < len(src) && (isDigit(src[idx]) || src[idx] == '_'); idx++ {
+			if src[idx] == '_' && (idx == 0 || !isDigit(src[idx-1]) || idx+1 == len(src) || !isDigit(src[idx+1])) {
+				return 0, invalid(idx, idx+1, "'_' can only separate two digits")
+			}
+		}
+
+		return idx, nil
+	}
+
+	if len(src) > 1 && src[0] == '0' {
+		if base, ok := numberBases[unicode.ToLower(src[1])]; ok {
+			prefix := string(src[:2])
+			isDigit := func(char rune) bool {
+				value, ok := digitValue(char)
+				return ok && value < base.base
+			}
+
+			end, err := digits(2, isDigit)
+
+			if err != nil {
+				return "", 0, err
+			}
+
+			if end < len(src) && (utils.IsAlpha(src[end]) || isDecimalDigit(src[end])) {
+				return "", 0, invalid(end, end+1, fmt.Sprintf("'%c' is not a %s digit", src[end], base.name))
+			}
+
+			if end == 2 {
+				return "", 0, invalid(0, 2, fmt.Sprintf("write at least one %s digit after '%s'", base.name, prefix))
+			}
+
+			if end+1 < len(src) && src[end] == '.' && isDecimalDigit(src[end+1]) {
+				return "", 0, invalid(end, end+1, "only decimal numbers can have a fraction")
+			}
+
+			value := 0.0
+			for _, char := range src[2:end] {
+				if digit, ok := digitValue(char); ok {
+					value = value*float64(base.base) + float64(digit)
+				}
+			}
+
+			if math.IsInf(value, 0) {
+				return "", 0, invalid(0, end, "the number is too large")
+			}
+
+			return strconv.FormatFloat(value, 'f', -1, 64), end, nil
+		}
+	}
+
+	end, err := digits(0, isDecimalDigit)
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	if end < len(src) && src[end] == '.' {
+		if end, err = digits(end+1, isDecimalDigit); err != nil {
+			return "", 0, err
+		}
+
+		if end+1 < len(src) && src[end] == '.' && isDecimalDigit(src[end+1]) {
+			return "", 0, invalid(end, end+1, "a number can only have one decimal point")
+		}
+	}
+
+	if end < len(src) && (src[end] == 'e' || src[end] == 'E') {
+		exponent := end
+		end++
+
+		if end < len(src) && (src[end] == '+' || src[end] == '-') {
+			end++
+		}
+
+		start := end
+		if end, err = digits(end, isDecimalDigit); err != nil {
+			return "", 0, err
+		}
+
+		if end == start {
+			return "", 0, invalid(exponent, end, "write the digits of the exponent, like 1e-9")
+		}
+	}
+
+	if end < len(src) && utils.IsAlpha(src[end]) {
+		return "", 0, invalid(end, end+1, fmt.Sprintf("'%c' can't be part of a number, put a space before it", src[end]))
+	}
+
+	number := strings.ReplaceAll(string(src[:end]), "_", "")
+
+	if value, _ := strconv.ParseFloat(number, 64); math.IsInf(value, 0) {
+		return "", 0, invalid(0, end, "the number is too large")
+	}
+
+	return number, end, nil
+}
+
+// Length of the run of characters at the start of src that looks like a
+// number, used to show the whole literal in its diagnostics
+func literalLength(src []rune) int {
+	length := 0
+
+	for length < len(src) {
+		char := src[length]
+		sign := (char == '+' || char == '-') && length > 0 && (src[length-1] == 'e' || src[length-1] == 'E')
+
+		if !utils.IsAlpha(char) && !isDecimalDigit(char) && char != '.' && !sign {
+			break
+		}
+
+		length++
+	}
+
+	return length
+}
+
+func isDecimalDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
+
+// Returns the value of a digit of a base up to 16
+func digitValue(char rune) (int, bool) {
+	switch {
+	case isDecimalDigit(char):
+		return int(char - '0'), true
+	case 'a' <= char && char <= 'f':
+		return int(char-'a') + 10, true
+	case 'A' <= char && char <= 'F':
+		return int(char-'A') + 10, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,203 @@
+package lexer
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+)
+
+// Runes written by the escape sequences made of a backslash and a single rune
+var simpleEscapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'b':  '\b',
+	'f':  '\f',
+	'v':  '\v',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+}
+
+/*
+ * Scans the string literal at the start of src, whose first rune is the
+ * opening quote and is at the given offset of the file. It returns the value
+ * of the string with its escape sequences replaced and the number of runes
+ * of the literal, quotes included. Strings can't span several lines.
+ */
+func scanString(src []rune, offset int, span func(int, int) token_type.Span) (string, int, error) {
+	quote := src[0]
+	var value strings.Builder
+
+	for idx := 1; idx < len(src); {
+		switch src[idx] {
+		case quote:
+			return value.String(), idx + 1, nil
+		case '\n':
+			return "", 0, unterminatedString(span(offset, offset+idx))
+		case '\\':
+			// The string ends with the backslash
+			if idx+1 == len(src) || src[idx+1] == '\n' {
+				idx++
+				continue
+			}
+
+			char, length, err := scanEscape(src[idx:])
+
+			if err != nil {
+				return "", 0, err.At(span(offset+idx, offset+idx+length))
+			}
+
+			value.WriteRune(char)
+			idx += length
+		default:
+			value.WriteRune(src[idx])
+			idx++
+		}
+	}
+
+	return "", 0, unterminatedString(span(offset, offset+len(src)))
+}
+
+func unterminatedString(span token_type.Span) error {
+	return compilerErrors.ErrSyntaxUnterminatedString.
+		At(span).
+		WithHelp("close the string with a matching quote, line breaks are written as \\n")
+}
+
+/*
+ * Reads the escape sequence at the start of src, whose first rune is the
+ * backslash. It returns the rune it stands for and its length, the error
+ * gets the span of the sequence from the caller.
+ */
+func scanEscape(src []rune) (rune, int, *diagnostic.Diagnostic) {
+	if char, ok := simpleEscapes[src[1]]; ok {
+		return char, 2, nil
+	}
+
+	switch src[1] {
+	case 'x':
+		// \x41
+		return hexEscape(src, 2, 2, "\\x needs two hex digits, like \\x41")
+	case 'u':
+		// \u{1F600}
+		if len(src) > 2 && src[2] == '{' {
+			end := 3
+			for end < len(src) && end < 10 && src[end] != '}' && src[end] != '\n' {
+				end++
+			}
+
+			if end == len(src) || src[end] != '}' || end == 3 {
+				return 0, end, invalidEscape(src[:end], "\\u{...} needs from one to six hex digits and a closing '}', like \\u{1F600}")
+			}
+
+			char, _, err := hexEscape(src[:end], 3, end-3, "\\u{...} can only have hex digits between the braces")
+
+			if err != nil {
+				return 0, end + 1, invalidEscape(src[:end+1], err.Help)
+			}
+
+			return char, end + 1, nil
+		}
+
+		// \u00E9
+		return hexEscape(src, 2, 4, "\\u needs four hex digits, like \\u00E9")
+	}
+
+	return 0, 2, invalidEscape(src[:2], "write \\\\ for a backslash")
+}
+
+// Reads the given number of hex digits found after the first start runes of
+// src, help tells how to write the escape when they are missing
+func hexEscape(src []rune, start int, digits int, help string) (rune, int, *diagnostic.Diagnostic) {
+	length := start
+
+	for length < len(src) && length < start+digits && isHexDigit(src[length]) {
+		length++
+	}
+
+	if length < start+digits {
+		return 0, length, invalidEscape(src[:length], help)
+	}
+
+	code, err := strconv.ParseUint(string(src[start:length]), 16, 32)
+
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, length, invalidEscape(src[:length], "the code point must be at most 10FFFF and can't be a surrogate, from D800 to DFFF")
+	}
+
+	return rune(code), length, nil
+}
+
+func isHexDigit(char rune) bool {
+	return '0' <= char && char <= '9' || 'a' <= char && char <= 'f' || 'A' <= char && char <= 'F'
+}
+
+func invalidEscape(sequence []rune, help string) *diagnostic.Diagnostic {
+	return compilerErrors.ErrSyntaxInvalidEscape.
+		WithArgs(string(sequence)).
+		WithHelp(help)
+}
+
+/*
+ * Scans a piece of text of a template literal. The first rune of src is the
+ * backtick that opens the template or the brace that closes a substitution,
+ * and it is at the given offset of the file; templateStart is the offset of
+ * the backtick. It returns the text with its escape sequences replaced, the
+ * number of runes of the piece with its delimiters, and true when the piece
+ * ends with '${' instead of the closing backtick.
+ */
+func scanTemplate(src []rune, offset int, templateStart int, span func(int, int) token_type.Span) (string, int, bool, error) {
+	var value strings.Builder
+
+	for idx := 1; idx < len(src); {
+		switch src[idx] {
+		case '`':
+			return value.String(), idx + 1, false, nil
+		case '$':
+			if idx+1 < len(src) && src[idx+1] == '{' {
+				return value.String(), idx + 2, true, nil
+			}
+
+			value.WriteRune('$')
+			idx++
+		case '\\':
+			if idx+1 == len(src) {
+				idx++
+				continue
+			}
+
+			// The delimiters of templates are only escaped inside them
+			if src[idx+1] == '`' || src[idx+1] == '$' {
+				value.WriteRune(src[idx+1])
+				idx += 2
+				continue
+			}
+
+			char, length, err := scanEscape(src[idx:])
+
+			if err != nil {
+				return "", 0, false, err.At(span(offset+idx, offset+idx+length))
+			}
+
+			value.WriteRune(char)
+			idx += length
+		default:
+			value.WriteRune(src[idx])
+			idx++
+		}
+	}
+
+	return "", 0, false, unterminatedTemplate(span(templateStart, offset+len(src)))
+}
+
+func unterminatedTemplate(span token_type.Span) error {
+	return compilerErrors.ErrSyntaxUnterminatedTemplate.
+		At(span).
+		WithHelp("close the template with a backtick and every substitution with '}'")
+}
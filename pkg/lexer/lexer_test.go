@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 )
 
@@ -53,6 +54,61 @@ func TestTokenize(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			input: "a & b | ~c ^ d << 1 >> 2 >>> 3 && e || f",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.Identifier, Value: "a"},
+				{Type: token_type.BinaryOperator, Value: "&"},
+				{Type: token_type.Identifier, Value: "b"},
+				{Type: token_type.BinaryOperator, Value: "|"},
+				{Type: token_type.Tilde, Value: "~"},
+				{Type: token_type.Identifier, Value: "c"},
+				{Type: token_type.BinaryOperator, Value: "^"},
+				{Type: token_type.Identifier, Value: "d"},
+				{Type: token_type.BinaryOperator, Value: "<<"},
+				{Type: token_type.Number, Value: "1"},
+				{Type: token_type.BinaryOperator, Value: ">>"},
+				{Type: token_type.Number, Value: "2"},
+				{Type: token_type.BinaryOperator, Value: ">>>"},
+				{Type: token_type.Number, Value: "3"},
+				{Type: token_type.And, Value: "&&"},
+				{Type: token_type.Identifier, Value: "e"},
+				{Type: token_type.Or, Value: "||"},
+				{Type: token_type.Identifier, Value: "f"},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+			expectedError: nil,
+		},
+		{
+			input: "x &= 1; x |= 2; x ^= 3; x <<= 4; x >>= 5; x >>>= 6",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.AndEquals, Value: "&="},
+				{Type: token_type.Number, Value: "1"},
+				{Type: token_type.Semicolon, Value: ";"},
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.OrEquals, Value: "|="},
+				{Type: token_type.Number, Value: "2"},
+				{Type: token_type.Semicolon, Value: ";"},
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.XorEquals, Value: "^="},
+				{Type: token_type.Number, Value: "3"},
+				{Type: token_type.Semicolon, Value: ";"},
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.ShiftLeftEquals, Value: "<<="},
+				{Type: token_type.Number, Value: "4"},
+				{Type: token_type.Semicolon, Value: ";"},
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.ShiftRightEquals, Value: ">>="},
+				{Type: token_type.Number, Value: "5"},
+				{Type: token_type.Semicolon, Value: ";"},
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.UnsignedShiftRightEquals, Value: ">>>="},
+				{Type: token_type.Number, Value: "6"},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+			expectedError: nil,
+		},
 		{
 			input: "/* This is a comment */",
 			expectedTokens: []token_type.Token{
@@ -70,7 +126,7 @@ func TestTokenize(t *testing.T) {
 		{
 			input:          "/* Unterminated comment",
 			expectedTokens: nil,
-			expectedError:  errors.New(string(compilerErrors.ErrSyntaxUnterminatedMultilineComment)),
+			expectedError:  compilerErrors.ErrSyntaxUnterminatedMultilineComment,
 		},
 	}
 
@@ -78,13 +134,300 @@ func TestTokenize(t *testing.T) {
 		tokens, err := Tokenize(test.input)
 
 		// Check error
-		if test.expectedError != nil && err.Error() != test.expectedError.Error() {
+		if test.expectedError != nil && !errors.Is(err, test.expectedError) {
 			t.Errorf("Expected error: %v, but got: %v", test.expectedError, err)
 		}
 
+		// Spans are checked in TestTokenizeSpans
+		for idx := range tokens {
+			tokens[idx].Span = token_type.Span{}
+		}
+
 		// Check tokens
 		if !reflect.DeepEqual(tokens, test.expectedTokens) {
 			t.Errorf("Expected tokens: %v, but got: %v", test.expectedTokens, tokens)
 		}
 	}
 }
+
+func TestTokenizeSpans(t *testing.T) {
+	tokens, err := TokenizeFile("main.pk", "var x = 10\nprint(\"hi\")")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	expectedSpans := []token_type.Span{
+		{File: "main.pk", Start: token_type.Position{Offset: 0, Line: 1, Column: 1}, End: token_type.Position{Offset: 3, Line: 1, Column: 4}},
+		{File: "main.pk", Start: token_type.Position{Offset: 4, Line: 1, Column: 5}, End: token_type.Position{Offset: 5, Line: 1, Column: 6}},
+		{File: "main.pk", Start: token_type.Position{Offset: 6, Line: 1, Column: 7}, End: token_type.Position{Offset: 7, Line: 1, Column: 8}},
+		{File: "main.pk", Start: token_type.Position{Offset: 8, Line: 1, Column: 9}, End: token_type.Position{Offset: 10, Line: 1, Column: 11}},
+		{File: "main.pk", Start: token_type.Position{Offset: 11, Line: 2, Column: 1}, End: token_type.Position{Offset: 16, Line: 2, Column: 6}},
+		{File: "main.pk", Start: token_type.Position{Offset: 16, Line: 2, Column: 6}, End: token_type.Position{Offset: 17, Line: 2, Column: 7}},
+		{File: "main.pk", Start: token_type.Position{Offset: 17, Line: 2, Column: 7}, End: token_type.Position{Offset: 21, Line: 2, Column: 11}},
+		{File: "main.pk", Start: token_type.Position{Offset: 21, Line: 2, Column: 11}, End: token_type.Position{Offset: 22, Line: 2, Column: 12}},
+		{File: "main.pk", Start: token_type.Position{Offset: 22, Line: 2, Column: 12}, End: token_type.Position{Offset: 22, Line: 2, Column: 12}},
+	}
+
+	if len(tokens) != len(expectedSpans) {
+		t.Fatalf("Expected %d tokens, but got: %v", len(expectedSpans), tokens)
+	}
+
+	for idx, token := range tokens {
+		if token.Span != expectedSpans[idx] {
+			t.Errorf("Expected span of %q to be %v, but got: %v", token.Value, expectedSpans[idx], token.Span)
+		}
+	}
+}
+
+func TestTokenizeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello"`, "hello"},
+		{`'hello'`, "hello"},
+		{`""`, ""},
+		{`''`, ""},
+		{`"123"`, "123"},
+		{`"it's"`, "it's"},
+		{`'say "hi"'`, `say "hi"`},
+		{`"a\nb"`, "a\nb"},
+		{`"a\tb"`, "a\tb"},
+		{`"a\rb"`, "a\rb"},
+		{`"\\"`, `\`},
+		{`"\""`, `"`},
+		{`'\''`, "'"},
+		{`"\0"`, "\x00"},
+		{`"\x41"`, "A"},
+		{`"\u00E9"`, "é"},
+		{`"\u{1F600}"`, "😀"},
+		{`"\u{41}\u{42}"`, "AB"},
+		{`"ñandú"`, "ñandú"},
+	}
+
+	for _, test := range tests {
+		tokens, err := Tokenize(test.input)
+
+		if err != nil {
+			t.Errorf("Expected %s to lex, but got: %v", test.input, err)
+			continue
+		}
+
+		if len(tokens) != 2 || tokens[0].Type != token_type.StringLiteral || tokens[0].Value != test.expected {
+			t.Errorf("Expected %s to be the string %q, but got: %v", test.input, test.expected, tokens)
+		}
+	}
+}
+
+func TestTokenizeStringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError error
+		expectedStart int
+		expectedEnd   int
+		expectedHelp  string
+	}{
+		{`"abc`, compilerErrors.ErrSyntaxUnterminatedString, 0, 4, "close the string with a matching quote, line breaks are written as \\n"},
+		{`'abc"`, compilerErrors.ErrSyntaxUnterminatedString, 0, 5, "close the string with a matching quote, line breaks are written as \\n"},
+		{"\"abc\nvar x = 1\"", compilerErrors.ErrSyntaxUnterminatedString, 0, 4, "close the string with a matching quote, line breaks are written as \\n"},
+		{`x = "abc\`, compilerErrors.ErrSyntaxUnterminatedString, 4, 9, "close the string with a matching quote, line breaks are written as \\n"},
+		{`"a\qb"`, compilerErrors.ErrSyntaxInvalidEscape, 2, 4, "write \\\\ for a backslash"},
+		{`"\x4"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 4, "\\x needs two hex digits, like \\x41"},
+		{`"\xzz"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 3, "\\x needs two hex digits, like \\x41"},
+		{`"\u12"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 5, "\\u needs four hex digits, like \\u00E9"},
+		{`"\u{}"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 4, "\\u{...} needs from one to six hex digits and a closing '}', like \\u{1F600}"},
+		{`"\u{110000}"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 11, "the code point must be at most 10FFFF and can't be a surrogate, from D800 to DFFF"},
+		{`"\u{12G4}"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 9, "\\u{...} can only have hex digits between the braces"},
+		{`"\uD800"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 7, "the code point must be at most 10FFFF and can't be a surrogate, from D800 to DFFF"},
+		{`"\u{1F600"`, compilerErrors.ErrSyntaxInvalidEscape, 1, 10, "\\u{...} needs from one to six hex digits and a closing '}', like \\u{1F600}"},
+	}
+
+	for _, test := range tests {
+		_, err := Tokenize(test.input)
+
+		var diag *diagnostic.Diagnostic
+		if !errors.As(err, &diag) || !errors.Is(err, test.expectedError) {
+			t.Errorf("Expected %s to fail with %v, but got: %v", test.input, test.expectedError, err)
+			continue
+		}
+
+		if diag.Span.Start.Offset != test.expectedStart || diag.Span.End.Offset != test.expectedEnd {
+			t.Errorf("Expected the error of %s to span %d-%d, but got: %d-%d", test.input, test.expectedStart, test.expectedEnd, diag.Span.Start.Offset, diag.Span.End.Offset)
+		}
+
+		if diag.Help != test.expectedHelp {
+			t.Errorf("Expected the help of %s to be %q, but got: %q", test.input, test.expectedHelp, diag.Help)
+		}
+	}
+}
+
+func TestTokenizeTemplates(t *testing.T) {
+	tests := []lexerTest{
+		{
+			input: "`plain\ntext`",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.Template, Value: "plain\ntext"},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+		},
+		{
+			input: "`a ${x} b ${y}`",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.TemplateHead, Value: "a "},
+				{Type: token_type.Identifier, Value: "x"},
+				{Type: token_type.TemplateMiddle, Value: " b "},
+				{Type: token_type.Identifier, Value: "y"},
+				{Type: token_type.TemplateTail, Value: ""},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+		},
+		{
+			input: "`${ { a: 1 } }`",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.TemplateHead, Value: ""},
+				{Type: token_type.LeftBrace, Value: "{"},
+				{Type: token_type.Identifier, Value: "a"},
+				{Type: token_type.Colon, Value: ":"},
+				{Type: token_type.Number, Value: "1"},
+				{Type: token_type.RightBrace, Value: "}"},
+				{Type: token_type.TemplateTail, Value: ""},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+		},
+		{
+			input: "`a ${`b ${c}`}`",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.TemplateHead, Value: "a "},
+				{Type: token_type.TemplateHead, Value: "b "},
+				{Type: token_type.Identifier, Value: "c"},
+				{Type: token_type.TemplateTail, Value: ""},
+				{Type: token_type.TemplateTail, Value: ""},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+		},
+		{
+			input: "`\\` \\${x} $ {y} \\t`",
+			expectedTokens: []token_type.Token{
+				{Type: token_type.Template, Value: "` ${x} $ {y} \t"},
+				{Type: token_type.EOF, Value: "EndOfFile"},
+			},
+		},
+		{
+			input:         "`abc",
+			expectedError: compilerErrors.ErrSyntaxUnterminatedTemplate,
+		},
+		{
+			input:         "`a ${x",
+			expectedError: compilerErrors.ErrSyntaxUnterminatedTemplate,
+		},
+		{
+			input:         "`a ${x} b",
+			expectedError: compilerErrors.ErrSyntaxUnterminatedTemplate,
+		},
+		{
+			input:         "`\\q`",
+			expectedError: compilerErrors.ErrSyntaxInvalidEscape,
+		},
+	}
+
+	for _, test := range tests {
+		tokens, err := Tokenize(test.input)
+
+		if !errors.Is(err, test.expectedError) {
+			t.Errorf("Expected error of %q: %v, but got: %v", test.input, test.expectedError, err)
+		}
+
+		for idx := range tokens {
+			tokens[idx].Span = token_type.Span{}
+		}
+
+		if !reflect.DeepEqual(tokens, test.expectedTokens) {
+			t.Errorf("Expected tokens of %q: %v, but got: %v", test.input, test.expectedTokens, tokens)
+		}
+	}
+}
+
+func TestTokenizeNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"123", "123"},
+		{"1.5", "1.5"},
+		{".5", ".5"},
+		{"1.", "1."},
+		{"1_000_000", "1000000"},
+		{"1_000.000_1", "1000.0001"},
+		{"1e-9", "1e-9"},
+		{"2.5E10", "2.5E10"},
+		{"1e+3", "1e+3"},
+		{".5e2", ".5e2"},
+		{"0xFF", "255"},
+		{"0Xff", "255"},
+		{"0xDEAD_BEEF", "3735928559"},
+		{"0o755", "493"},
+		{"0b1010", "10"},
+		{"0b1111_0000", "240"},
+		{"0755", "0755"},
+	}
+
+	for _, test := range tests {
+		tokens, err := Tokenize(test.input)
+
+		if err != nil {
+			t.Errorf("Expected %s to lex, but got: %v", test.input, err)
+			continue
+		}
+
+		if len(tokens) != 2 || tokens[0].Type != token_type.Number || tokens[0].Value != test.expected {
+			t.Errorf("Expected %s to be the number %s, but got: %v", test.input, test.expected, tokens)
+			continue
+		}
+
+		if end := tokens[0].Span.End.Offset; end != len(test.input) {
+			t.Errorf("Expected %s to span 0-%d, but got: 0-%d", test.input, len(test.input), end)
+		}
+	}
+}
+
+func TestTokenizeNumberErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedStart int
+		expectedEnd   int
+	}{
+		{"1.2.3", 3, 4},
+		{"x = 1..2", 6, 7},
+		{"0x", 0, 2},
+		{"0b", 0, 2},
+		{"0xFG", 3, 4},
+		{"0b102", 4, 5},
+		{"0o8", 2, 3},
+		{"0x1.5", 3, 4},
+		{"1__000", 1, 2},
+		{"1_", 1, 2},
+		{"1_.5", 1, 2},
+		{"1._5", 2, 3},
+		{"0x_FF", 2, 3},
+		{"1e", 1, 2},
+		{"1e+", 1, 3},
+		{"2.5Ex", 3, 4},
+		{"123abc", 3, 4},
+		{"1e400", 0, 5},
+	}
+
+	for _, test := range tests {
+		_, err := Tokenize(test.input)
+
+		var diag *diagnostic.Diagnostic
+		if !errors.As(err, &diag) || !errors.Is(err, compilerErrors.ErrSyntaxInvalidNumber) {
+			t.Errorf("Expected %s to fail with %v, but got: %v", test.input, compilerErrors.ErrSyntaxInvalidNumber, err)
+			continue
+		}
+
+		if diag.Span.Start.Offset != test.expectedStart || diag.Span.End.Offset != test.expectedEnd {
+			t.Errorf("Expected the error of %s to span %d-%d, but got: %d-%d", test.input, test.expectedStart, test.expectedEnd, diag.Span.Start.Offset, diag.Span.End.Offset)
+		}
+	}
+}
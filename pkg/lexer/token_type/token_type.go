@@ -9,6 +9,10 @@ const (
 	Null
 	BooleanLiteral
 	StringLiteral
+	Template       // `text` without substitutions
+	TemplateHead   // `text${
+	TemplateMiddle // }text${
+	TemplateTail   // }text`
 
 	// Keywords
 	Var
@@ -25,19 +29,30 @@ const (
 	Continue
 	Break
 	For
+	Try
+	Catch
+	Finally
+	Throw
 
 	// Operators
-	BinaryOperator // + - * / ** %
+	BinaryOperator // + - * / ** % & | ^ << >> >>>
+	Tilde          // ~
 
 	// Assigment operators
-	Equals       // =
-	PlusEquals   // +=
-	MinusEquals  // -=
-	TimesEquals  // *=
-	DivideEquals // /=
-	PowerEquals  // **=
-	ModuleEquals // %=
-	Arrow        // =>
+	Equals                   // =
+	PlusEquals               // +=
+	MinusEquals              // -=
+	TimesEquals              // *=
+	DivideEquals             // /=
+	PowerEquals              // **=
+	ModuleEquals             // %=
+	AndEquals                // &=
+	OrEquals                 // |=
+	XorEquals                // ^=
+	ShiftLeftEquals          // <<=
+	ShiftRightEquals         // >>=
+	UnsignedShiftRightEquals // >>>=
+	Arrow                    // =>
 
 	// Update operators
 	Increment // ++
@@ -54,8 +69,6 @@ const (
 	Semicolon    // ;
 	Comma        // ,
 	Dot          // .
-	DoubleQuote  // "
-	SingleQuote  // '
 	QuestionMark // ?
 
 	// Comparison operators
@@ -91,6 +104,10 @@ var KEYWORDS = map[string]TokenType{
 	"continue": Continue,
 	"break":    Break,
 	"for":      For,
+	"try":      Try,
+	"catch":    Catch,
+	"finally":  Finally,
+	"throw":    Throw,
 }
 
 var SkippableChars = []rune{' ', '\t', '\n', '\r'}
@@ -100,9 +117,26 @@ var AllowedIdentifierChars = []rune{'_', '0', '1', '2', '3', '4', '5', '6', '7',
 var AllowedIdentifierCharsWithFirst = []rune{'_'}
 
 // Assigment operators
-var AssigmentOperators = []TokenType{Equals, PlusEquals, MinusEquals, TimesEquals, DivideEquals, PowerEquals, ModuleEquals}
+var AssigmentOperators = []TokenType{Equals, PlusEquals, MinusEquals, TimesEquals, DivideEquals, PowerEquals, ModuleEquals, AndEquals, OrEquals, XorEquals, ShiftLeftEquals, ShiftRightEquals, UnsignedShiftRightEquals}
+
+// Position is a location in the source code. Offset is counted in runes from
+// the start of the input, Line and Column start at 1.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Span is the portion of a source file covered by a token or a node,
+// the End position is exclusive.
+type Span struct {
+	File  string
+	Start Position
+	End   Position
+}
 
 type Token struct {
 	Value string
 	Type  TokenType
+	Span  Span
 }
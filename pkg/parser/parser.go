@@ -1,41 +1,71 @@
 package parser
 
 import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 	"github.com/Waxer59/PikaLang/pkg/ast"
 	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/lexer"
 	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 )
 
 type Parser struct {
-	tokens []token_type.Token
+	tokens      []token_type.Token
+	prev        token_type.Token // Last consumed token
+	fileName    string
+	diagnostics []*diagnostic.Diagnostic
+
+	loopLabels    []string // Labels of the loops being parsed, "" for unlabeled ones
+	functionDepth int
 }
 
 func New() *Parser {
 	return &Parser{}
 }
 
+// Sets the file name attached to the spans of the produced AST
+func (p *Parser) SetFileName(fileName string) {
+	p.fileName = fileName
+}
+
 func (p *Parser) ProduceAST(input string) (*ast.Program, error) {
 	var err error
-	p.tokens, err = lexer.Tokenize(input)
+	p.diagnostics = nil
+	p.loopLabels = nil
+	p.functionDepth = 0
+	p.tokens, err = lexer.TokenizeFile(p.fileName, input)
 
 	if err != nil {
 		return nil, err
 	}
 
+	start := p.at()
+
 	program := ast.Program{
 		Kind: ast_types.Program,
 		Body: []ast.Stmt{},
 	}
 
 	for p.notEOF() {
-		stmt, err := p.ParseStmt()
-		if err != nil {
-			return nil, err
+		stmt := p.parseStmtOrRecover()
+
+		// A '}' without its '{' would stop every block, report it and move on
+		if p.at().Type == token_type.RightBrace {
+			p.report(compilerErrors.ErrSyntaxUnexpectedToken.WithArgs(p.at().Value).At(p.at().Span), p.at())
+			p.subtract()
 		}
+
 		program.Body = append(program.Body, stmt)
 	}
 
+	program.Span = p.spanFrom(start.Span)
+	program.Span.End = p.at().Span.End
+
+	// The partial AST is returned along the errors so tools still get a tree
+	if len(p.diagnostics) > 0 {
+		return &program, diagnostic.List(p.diagnostics)
+	}
+
 	return &program, nil
 }
 
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"strconv"
 
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
@@ -17,7 +16,8 @@ func (p *Parser) parseExpr() (ast.Expr, error) {
 }
 
 func (p *Parser) parsePrimaryExpr() (ast.Expr, error) {
-	tk := p.at().Type
+	start := p.at()
+	tk := start.Type
 
 	switch tk {
 	case token_type.BooleanLiteral:
@@ -27,15 +27,15 @@ func (p *Parser) parsePrimaryExpr() (ast.Expr, error) {
 			return nil, err
 		}
 
-		return ast.BooleanLiteral{Kind: ast_types.BooleanLiteral, Value: b}, nil
+		return ast.BooleanLiteral{Kind: ast_types.BooleanLiteral, Value: b, Span: start.Span}, nil
 	case token_type.Null:
 		p.subtract() // consume 'null'
-		return ast.NullLiteral{Kind: ast_types.NullLiteral, Value: nil}, nil
+		return ast.NullLiteral{Kind: ast_types.NullLiteral, Value: nil, Span: start.Span}, nil
 	case token_type.NaN:
 		p.subtract() // consume 'NaN'
-		return ast.NaNLiteral{Kind: ast_types.NaNLiteral, Value: nil}, nil
+		return ast.NaNLiteral{Kind: ast_types.NaNLiteral, Value: nil, Span: start.Span}, nil
 	case token_type.Identifier:
-		return ast.Identifier{Kind: ast_types.Identifier, Symbol: p.subtract().Value}, nil
+		return ast.Identifier{Kind: ast_types.Identifier, Symbol: p.subtract().Value, Span: start.Span}, nil
 	case token_type.Number:
 		currToken := p.subtract()
 		n, err := strconv.ParseFloat(currToken.Value, 64)
@@ -44,18 +44,13 @@ func (p *Parser) parsePrimaryExpr() (ast.Expr, error) {
 			return nil, err
 		}
 
-		return ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: n}, nil
-	case token_type.DoubleQuote:
-		p.subtract() // consume '"'
-		value := p.subtract().Value
-		_, err := p.expect(token_type.DoubleQuote, compilerErrors.ErrSyntaxExpectedDoubleQuote)
-		if err != nil {
-			return nil, err
-		}
-		return ast.StringLiteral{
-			Kind:  ast_types.StringLiteral,
-			Value: value,
-		}, nil
+		return ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: n, Span: start.Span}, nil
+	case token_type.StringLiteral:
+		return ast.StringLiteral{Kind: ast_types.StringLiteral, Value: p.subtract().Value, Span: start.Span}, nil
+	case token_type.Template:
+		return ast.TemplateLiteral{Kind: ast_types.TemplateLiteral, Quasis: []string{p.subtract().Value}, Span: start.Span}, nil
+	case token_type.TemplateHead:
+		return p.parseTemplateLiteral()
 	case token_type.LeftBracket:
 		p.subtract() // advance post open bracket
 
@@ -85,6 +80,7 @@ func (p *Parser) parsePrimaryExpr() (ast.Expr, error) {
 		return ast.ArrayLiteral{
 			Kind:     ast_types.ArrayLiteral,
 			Elements: elements,
+			Span:     p.spanFrom(start.Span),
 		}, nil
 	case token_type.LeftParen:
 		p.subtract() // consume '('
@@ -101,7 +97,16 @@ func (p *Parser) parsePrimaryExpr() (ast.Expr, error) {
 		return value, nil
 	}
 
-	return nil, errors.New(compilerErrors.ErrParsingError)
+	if tk == token_type.EOF {
+		return nil, compilerErrors.ErrSyntaxUnexpectedEOF.At(start.Span)
+	}
+
+	// The substitution of a template literal ended in the middle of an expression, like '${1 + }'
+	if tk == token_type.TemplateMiddle || tk == token_type.TemplateTail {
+		return nil, compilerErrors.ErrSyntaxIncompleteTemplateExpression.At(closingBraceSpan(start.Span))
+	}
+
+	return nil, compilerErrors.ErrSyntaxUnexpectedToken.WithArgs(start.Value).At(start.Span)
 }
 
 func (p *Parser) parseMemberExpr() (ast.Expr, error) {
@@ -125,7 +130,7 @@ func (p *Parser) parseMemberExpr() (ast.Expr, error) {
 			}
 
 			if property.GetKind() != ast_types.Identifier {
-				return nil, errors.New(compilerErrors.ErrFuncExpectedIdentifer)
+				return nil, compilerErrors.ErrFuncExpectedIdentifer.At(p.at().Span)
 			}
 		case token_type.LeftBracket:
 			computed = true
@@ -146,6 +151,7 @@ func (p *Parser) parseMemberExpr() (ast.Expr, error) {
 			Object:   obj,
 			Property: property,
 			Computed: computed,
+			Span:     p.spanFrom(obj.GetSpan()),
 		}
 	}
 
@@ -173,7 +179,7 @@ func (p *Parser) parseMultiplicativeExpr() (ast.Expr, error) {
 		return nil, err
 	}
 
-	for slices.Contains(ast_types.MultiplicativeExpr, p.at().Value) {
+	for slices.Contains(ast_types.MultiplicativeExpr, operator(p.at())) {
 		var op = p.subtract().Value
 		right, err := p.parseCallMemberExpr()
 
@@ -186,6 +192,7 @@ func (p *Parser) parseMultiplicativeExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
@@ -193,7 +200,8 @@ func (p *Parser) parseMultiplicativeExpr() (ast.Expr, error) {
 }
 
 func (p *Parser) parseSuffixUpdateExpr() (ast.Expr, error) {
-	if p.at().Type == token_type.Identifier && p.atNext().Value == "++" || p.atNext().Value == "--" {
+	if p.at().Type == token_type.Identifier && operator(p.atNext()) == "++" || operator(p.atNext()) == "--" {
+		start := p.at()
 		argument, err := p.parsePrimaryExpr()
 
 		if err != nil {
@@ -203,7 +211,7 @@ func (p *Parser) parseSuffixUpdateExpr() (ast.Expr, error) {
 		ident, ok := argument.(ast.Identifier)
 
 		if !ok {
-			return nil, errors.New(compilerErrors.ErrSyntaxExpectedIdentifier)
+			return nil, compilerErrors.ErrSyntaxExpectedIdentifier.At(p.at().Span)
 		}
 
 		op := p.subtract().Value // consume '++' or '--'
@@ -213,6 +221,7 @@ func (p *Parser) parseSuffixUpdateExpr() (ast.Expr, error) {
 			Operator: op,
 			Argument: ident,
 			Prefix:   false,
+			Span:     p.spanFrom(start.Span),
 		}, nil
 	}
 
@@ -220,8 +229,9 @@ func (p *Parser) parseSuffixUpdateExpr() (ast.Expr, error) {
 }
 
 func (p *Parser) parsePrefixUpdateExpr() (ast.Expr, error) {
-	if p.at().Value == "++" || p.at().Value == "--" {
-		op := p.subtract().Value // consume '++' or '--'
+	if operator(p.at()) == "++" || operator(p.at()) == "--" {
+		start := p.subtract() // consume '++' or '--'
+		op := start.Value
 		argument, err := p.parsePrimaryExpr()
 
 		if err != nil {
@@ -230,7 +240,7 @@ func (p *Parser) parsePrefixUpdateExpr() (ast.Expr, error) {
 
 		ident, ok := argument.(ast.Identifier)
 		if !ok {
-			return nil, errors.New(compilerErrors.ErrSyntaxExpectedIdentifier)
+			return nil, compilerErrors.ErrSyntaxExpectedIdentifier.At(p.at().Span)
 		}
 
 		return ast.UpdateExpr{
@@ -238,14 +248,16 @@ func (p *Parser) parsePrefixUpdateExpr() (ast.Expr, error) {
 			Operator: op,
 			Argument: ident,
 			Prefix:   true,
+			Span:     p.spanFrom(start.Span),
 		}, nil
 	}
 	return p.parseSuffixUpdateExpr()
 }
 
 func (p *Parser) parseNegativeAndPositiveExpr() (ast.Expr, error) {
-	if p.at().Value == "+" || p.at().Value == "-" {
-		op := p.subtract().Value // consume '-' or '+'
+	if operator(p.at()) == "+" || operator(p.at()) == "-" || p.at().Type == token_type.Tilde {
+		start := p.subtract() // consume '-', '+' or '~'
+		op := start.Value
 		argument, err := p.parseNegativeAndPositiveExpr()
 		if err != nil {
 			return nil, err
@@ -255,6 +267,7 @@ func (p *Parser) parseNegativeAndPositiveExpr() (ast.Expr, error) {
 			Operator: op,
 			Argument: argument,
 			Prefix:   true,
+			Span:     p.spanFrom(start.Span),
 		}, nil
 	}
 
@@ -270,6 +283,7 @@ func (p *Parser) parseCallExpr(caller ast.Expr) (ast.Expr, error) {
 		Kind:   ast_types.CallExpr,
 		Caller: caller,
 		Args:   args,
+		Span:   p.spanFrom(caller.GetSpan()),
 	}
 
 	if p.at().Type == token_type.LeftParen {
@@ -286,7 +300,8 @@ func (p *Parser) parseCallExpr(caller ast.Expr) (ast.Expr, error) {
 
 func (p *Parser) parseLogicalNotExpr() (ast.Expr, error) {
 	if p.at().Type == token_type.Bang {
-		op := p.subtract().Value // consume '!'
+		start := p.subtract() // consume '!'
+		op := start.Value
 		argument, err := p.parseLogicalNotExpr()
 		if err != nil {
 			return nil, err
@@ -296,6 +311,7 @@ func (p *Parser) parseLogicalNotExpr() (ast.Expr, error) {
 			Operator: op,
 			Argument: argument,
 			Prefix:   false,
+			Span:     p.spanFrom(start.Span),
 		}, nil
 	}
 
@@ -309,7 +325,7 @@ func (p *Parser) parseExponentialExpr() (ast.Expr, error) {
 		return nil, err
 	}
 
-	for p.at().Value == "**" {
+	for operator(p.at()) == "**" {
 		op := p.subtract().Value
 		right, err := p.parseLogicalNotExpr()
 
@@ -322,6 +338,7 @@ func (p *Parser) parseExponentialExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
@@ -335,7 +352,7 @@ func (p *Parser) parseAdditiveExpr() (ast.Expr, error) {
 		return nil, err
 	}
 
-	for slices.Contains(ast_types.AdditiveExpr, p.at().Value) {
+	for slices.Contains(ast_types.AdditiveExpr, operator(p.at())) {
 		var op = p.subtract().Value
 		right, err := p.parseExponentialExpr()
 
@@ -348,6 +365,7 @@ func (p *Parser) parseAdditiveExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
@@ -359,15 +377,15 @@ func (p *Parser) parseObjectExpr() (ast.Expr, error) {
 		return p.parseAdditiveExpr()
 	}
 
-	p.subtract() // advance post open brace
+	start := p.subtract() // advance post open brace
 
 	var properties []ast.Property
 
 	for p.notEOF() && p.at().Type != token_type.RightBrace {
+		keyStart := p.at()
 
 		var key string
-		if p.at().Type == token_type.DoubleQuote {
-			p.subtract()
+		if p.at().Type == token_type.StringLiteral {
 			key = p.subtract().Value
 		} else {
 			keyToken, err := p.expect(token_type.Identifier, compilerErrors.ErrSyntaxExpectedKey)
@@ -378,10 +396,6 @@ func (p *Parser) parseObjectExpr() (ast.Expr, error) {
 			key = keyToken.Value
 		}
 
-		if p.at().Type == token_type.DoubleQuote {
-			p.subtract()
-		}
-
 		// Allows shorthand syntax: { key, } && { key }
 		switch p.at().Type {
 		case token_type.Comma:
@@ -390,6 +404,7 @@ func (p *Parser) parseObjectExpr() (ast.Expr, error) {
 				Kind:  ast_types.Property,
 				Key:   key,
 				Value: nil,
+				Span:  p.spanFrom(keyStart.Span),
 			})
 			continue
 		case token_type.RightBrace:
@@ -397,6 +412,7 @@ func (p *Parser) parseObjectExpr() (ast.Expr, error) {
 				Kind:  ast_types.Property,
 				Key:   key,
 				Value: nil,
+				Span:  p.spanFrom(keyStart.Span),
 			})
 			continue
 		}
@@ -415,6 +431,7 @@ func (p *Parser) parseObjectExpr() (ast.Expr, error) {
 			Kind:  ast_types.Property,
 			Key:   key,
 			Value: value,
+			Span:  p.spanFrom(keyStart.Span),
 		})
 
 		if p.at().Type != token_type.RightBrace {
@@ -432,17 +449,18 @@ func (p *Parser) parseObjectExpr() (ast.Expr, error) {
 	return ast.ObjectLiteral{
 		Kind:       ast_types.ObjectLiteral,
 		Properties: properties,
+		Span:       p.spanFrom(start.Span),
 	}, nil
 }
 
-func (p *Parser) parseComparisonExpr() (ast.Expr, error) {
+func (p *Parser) parseShiftExpr() (ast.Expr, error) {
 	left, err := p.parseObjectExpr()
 
 	if err != nil {
 		return nil, err
 	}
 
-	for slices.Contains(ast_types.ComparisonExpr, p.at().Value) && p.notEOF() {
+	for slices.Contains(ast_types.ShiftExpr, operator(p.at())) && p.notEOF() {
 		op := p.subtract().Value // consume operator
 		right, err := p.parseObjectExpr()
 
@@ -455,6 +473,34 @@ func (p *Parser) parseComparisonExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser) parseComparisonExpr() (ast.Expr, error) {
+	left, err := p.parseShiftExpr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for slices.Contains(ast_types.ComparisonExpr, operator(p.at())) && p.notEOF() {
+		op := p.subtract().Value // consume operator
+		right, err := p.parseShiftExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = ast.BinaryExpr{
+			Kind:     ast_types.BinaryExpr,
+			Left:     left,
+			Right:    right,
+			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
@@ -467,7 +513,7 @@ func (p *Parser) parseEqualityExpr() (ast.Expr, error) {
 		return nil, err
 	}
 
-	for slices.Contains(ast_types.EqualityExpr, p.at().Value) && p.notEOF() {
+	for slices.Contains(ast_types.EqualityExpr, operator(p.at())) && p.notEOF() {
 		op := p.subtract().Value // consume operator
 		right, err := p.parseComparisonExpr()
 		if err != nil {
@@ -478,19 +524,101 @@ func (p *Parser) parseEqualityExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
 	return left, nil
 }
 
-func (p *Parser) parseLogicalAndExpr() (ast.Expr, error) {
+func (p *Parser) parseBitwiseAndExpr() (ast.Expr, error) {
 	left, err := p.parseEqualityExpr()
 
 	if err != nil {
 		return nil, err
 	}
 
+	for operator(p.at()) == "&" && p.notEOF() {
+		op := p.subtract().Value // consume operator
+		right, err := p.parseEqualityExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = ast.BinaryExpr{
+			Kind:     ast_types.BinaryExpr,
+			Left:     left,
+			Right:    right,
+			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser) parseBitwiseXorExpr() (ast.Expr, error) {
+	left, err := p.parseBitwiseAndExpr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for operator(p.at()) == "^" && p.notEOF() {
+		op := p.subtract().Value // consume operator
+		right, err := p.parseBitwiseAndExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = ast.BinaryExpr{
+			Kind:     ast_types.BinaryExpr,
+			Left:     left,
+			Right:    right,
+			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser) parseBitwiseOrExpr() (ast.Expr, error) {
+	left, err := p.parseBitwiseXorExpr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for operator(p.at()) == "|" && p.notEOF() {
+		op := p.subtract().Value // consume operator
+		right, err := p.parseBitwiseXorExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		left = ast.BinaryExpr{
+			Kind:     ast_types.BinaryExpr,
+			Left:     left,
+			Right:    right,
+			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
+		}
+	}
+
+	return left, nil
+}
+
+func (p *Parser) parseLogicalAndExpr() (ast.Expr, error) {
+	left, err := p.parseBitwiseOrExpr()
+
+	if err != nil {
+		return nil, err
+	}
+
 	for p.at().Type == token_type.And && p.notEOF() {
 		p.subtract() // consume '&&'
 		right, err := p.parseExpr()
@@ -502,6 +630,7 @@ func (p *Parser) parseLogicalAndExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: "&&",
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
@@ -526,6 +655,7 @@ func (p *Parser) parseLogicalOrExpr() (ast.Expr, error) {
 			Left:     left,
 			Right:    right,
 			Operator: "||",
+			Span:     p.spanFrom(left.GetSpan()),
 		}
 	}
 
@@ -563,6 +693,7 @@ func (p *Parser) parseTernaryExpr() (ast.Expr, error) {
 			Condition:  condition,
 			Consequent: consequent,
 			Alternate:  alternate,
+			Span:       p.spanFrom(condition.GetSpan()),
 		}, nil
 	}
 
@@ -575,6 +706,7 @@ func (p *Parser) parseArrowFunctionExpr() (ast.Expr, error) {
 	}
 
 	tokensCopy := p.tokens
+	start := p.at()
 
 	params, err := p.parseFunctionArgs()
 
@@ -589,7 +721,7 @@ func (p *Parser) parseArrowFunctionExpr() (ast.Expr, error) {
 
 	p.subtract() // consume '=>'
 
-	body, err := p.parseBlockBodyStmt()
+	body, err := p.parseFunctionBody()
 
 	if err != nil {
 		return nil, err
@@ -599,6 +731,7 @@ func (p *Parser) parseArrowFunctionExpr() (ast.Expr, error) {
 		Kind:   ast_types.ArrowFunctionExpr,
 		Params: params,
 		Body:   body,
+		Span:   p.spanFrom(start.Span),
 	}, nil
 }
 
@@ -619,8 +752,72 @@ func (p *Parser) parseAssigmentExpr() (ast.Expr, error) {
 			Assigne:  left,
 			Value:    value,
 			Operator: op,
+			Span:     p.spanFrom(left.GetSpan()),
 		}, nil
 	}
 
 	return left, nil
 }
+
+/*
+ * Parses a template literal with substitutions, the lexer gives its text as
+ * a TemplateHead, one TemplateMiddle between every two substitutions and a
+ * TemplateTail, with the tokens of the expressions between them.
+ */
+func (p *Parser) parseTemplateLiteral() (ast.Expr, error) {
+	start := p.subtract() // consume '`text${'
+
+	template := ast.TemplateLiteral{Kind: ast_types.TemplateLiteral, Quasis: []string{start.Value}}
+
+	for {
+		if tk := p.at().Type; tk == token_type.TemplateMiddle || tk == token_type.TemplateTail {
+			return nil, compilerErrors.ErrSyntaxEmptyTemplateExpression.At(emptySubstitutionSpan(p.prev.Span, p.at().Span))
+		}
+
+		expr, err := p.parseExpr()
+
+		if err != nil {
+			return nil, err
+		}
+
+		template.Expressions = append(template.Expressions, expr)
+
+		if p.at().Type == token_type.TemplateMiddle {
+			template.Quasis = append(template.Quasis, p.subtract().Value)
+			continue
+		}
+
+		end, err := p.expect(token_type.TemplateTail, compilerErrors.ErrSyntaxExpectedRightBrace)
+
+		if err != nil {
+			return nil, err
+		}
+
+		template.Quasis = append(template.Quasis, end.Value)
+		template.Span = p.spanFrom(start.Span)
+
+		return template, nil
+	}
+}
+
+// Span of the '}' that starts a piece of text of a template literal
+func closingBraceSpan(text token_type.Span) token_type.Span {
+	end := text.Start
+	end.Offset++
+	end.Column++
+
+	return token_type.Span{File: text.File, Start: text.Start, End: end}
+}
+
+// Span of '${}' from the piece of text that ends with '${' and the one that starts with '}'
+func emptySubstitutionSpan(before token_type.Span, after token_type.Span) token_type.Span {
+	start := before.End
+	start.Offset -= 2
+	start.Column -= 2
+
+	end := after.Start
+	end.Offset++
+	end.Column++
+
+	return token_type.Span{File: before.File, Start: start, End: end}
+}
@@ -1,12 +1,16 @@
 package parser_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 	"github.com/Waxer59/PikaLang/pkg/ast"
 	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/lexer"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
 	"github.com/Waxer59/PikaLang/pkg/parser"
 )
 
@@ -30,17 +34,122 @@ func testParseExpr(t *testing.T, tests []ParserTest, p *parser.Parser) {
 		t.Log(test)
 
 		// Check error
-		if test.expectedErr != nil && err.Error() != test.expectedErr.Error() {
+		if test.expectedErr != nil && !errors.Is(err, test.expectedErr) {
 			t.Errorf("Expected error: %v, but got: %v", test.expectedErr, err)
 		}
 
-		// Check tokens
-		if !reflect.DeepEqual(stmt, test.expectedExpr[0]) {
+		// Check tokens, spans are checked in TestParseSpans
+		if !reflect.DeepEqual(stripSpans(stmt), test.expectedExpr[0]) {
 			t.Errorf("Expected expr: %v, but got: %v", test.expectedExpr, stmt)
 		}
 	}
 }
 
+// Returns a copy of the node with every span zeroed
+func stripSpans(node any) any {
+	if node == nil {
+		return nil
+	}
+	return stripValueSpans(reflect.ValueOf(node)).Interface()
+}
+
+func stripValueSpans(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(stripValueSpans(v.Elem()))
+		return out
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(token_type.Span{}) {
+			return reflect.Zero(v.Type())
+		}
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			out.Field(i).Set(stripValueSpans(v.Field(i)))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(stripValueSpans(v.Index(i)))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	p := parser.New()
+	p.SetFileName("main.pk")
+
+	program, err := p.ProduceAST("var x = 1\nx = (x + 20) * 3")
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	position := func(offset, line, column int) token_type.Position {
+		return token_type.Position{Offset: offset, Line: line, Column: column}
+	}
+
+	tests := []struct {
+		node     ast.Stmt
+		expected token_type.Span
+	}{
+		{program, token_type.Span{File: "main.pk", Start: position(0, 1, 1), End: position(26, 2, 17)}},
+		{program.Body[0], token_type.Span{File: "main.pk", Start: position(0, 1, 1), End: position(9, 1, 10)}},
+		{program.Body[1], token_type.Span{File: "main.pk", Start: position(10, 2, 1), End: position(26, 2, 17)}},
+		{program.Body[1].(ast.AssigmentExpr).Value, token_type.Span{File: "main.pk", Start: position(15, 2, 6), End: position(26, 2, 17)}},
+		{program.Body[1].(ast.AssigmentExpr).Value.(ast.BinaryExpr).Left, token_type.Span{File: "main.pk", Start: position(15, 2, 6), End: position(21, 2, 12)}},
+	}
+
+	for _, test := range tests {
+		if test.node.GetSpan() != test.expected {
+			t.Errorf("Expected span of %v to be %v, but got: %v", test.node.GetKind(), test.expected, test.node.GetSpan())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedErr  error
+		expectedLine int
+		expectedCol  int
+	}{
+		{"fn (a) {}", compilerErrors.ErrFuncExpectedIdentifer, 1, 4},
+		{"var x = 1\nprint(x 2", compilerErrors.ErrSyntaxExpectedRightParen, 2, 9},
+		{"const x", compilerErrors.ErrVariableConstantMustBeInitialized, 1, 7},
+		{"try {\n}\nvar x = 1", compilerErrors.ErrSyntaxExpectedCatchOrFinally, 3, 1},
+		{"try {} catch (1) {}", compilerErrors.ErrSyntaxExpectedCatchParameter, 1, 15},
+		{"print(`a ${} b`)", compilerErrors.ErrSyntaxEmptyTemplateExpression, 1, 10},
+		{"`a ${x y}`", compilerErrors.ErrSyntaxExpectedRightBrace, 1, 8},
+		{"`a ${1 + }`", compilerErrors.ErrSyntaxIncompleteTemplateExpression, 1, 10},
+		{"`a ${1 + } b ${2}`", compilerErrors.ErrSyntaxIncompleteTemplateExpression, 1, 10},
+	}
+
+	for _, test := range tests {
+		_, err := parser.New().ProduceAST(test.input)
+
+		var diag *diagnostic.Diagnostic
+		if !errors.As(err, &diag) || !errors.Is(err, test.expectedErr) {
+			t.Errorf("Expected error: %v, but got: %v", test.expectedErr, err)
+			continue
+		}
+
+		if diag.Span.Start.Line != test.expectedLine || diag.Span.Start.Column != test.expectedCol {
+			t.Errorf("Expected %q to fail at %d:%d, but got: %v", test.input, test.expectedLine, test.expectedCol, diag.Location())
+		}
+	}
+}
+
 func TestParseAssigmentExpr(t *testing.T) {
 	p := parser.New()
 
@@ -307,6 +416,18 @@ func TestParseAdditiveExpr(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			input: `"-" + "*"`,
+			expectedExpr: []ast.Expr{
+				ast.BinaryExpr{
+					Kind:     ast_types.BinaryExpr,
+					Left:     ast.StringLiteral{Kind: ast_types.StringLiteral, Value: "-"},
+					Right:    ast.StringLiteral{Kind: ast_types.StringLiteral, Value: "*"},
+					Operator: "+",
+				},
+			},
+			expectedErr: nil,
+		},
 	}
 
 	testParseExpr(t, tests, p)
@@ -411,6 +532,114 @@ func TestParseComparisonExpr(t *testing.T) {
 	testParseExpr(t, tests, p)
 }
 
+func TestParseShiftExpr(t *testing.T) {
+	p := parser.New()
+
+	tests := []ParserTest{
+		{
+			input: "1 + 1 << 2 < 10",
+			expectedExpr: []ast.Expr{
+				ast.BinaryExpr{
+					Kind: ast_types.BinaryExpr,
+					Left: ast.BinaryExpr{
+						Kind: ast_types.BinaryExpr,
+						Left: ast.BinaryExpr{
+							Kind:     ast_types.BinaryExpr,
+							Left:     ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+							Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+							Operator: "+",
+						},
+						Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 2},
+						Operator: "<<",
+					},
+					Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 10},
+					Operator: "<",
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			input: "-16 >>> 2 >> 1",
+			expectedExpr: []ast.Expr{
+				ast.BinaryExpr{
+					Kind: ast_types.BinaryExpr,
+					Left: ast.BinaryExpr{
+						Kind:     ast_types.BinaryExpr,
+						Left:     ast.UnaryExpr{Kind: ast_types.UnaryExpr, Operator: "-", Argument: ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 16}, Prefix: true},
+						Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 2},
+						Operator: ">>>",
+					},
+					Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+					Operator: ">>",
+				},
+			},
+			expectedErr: nil,
+		},
+	}
+
+	testParseExpr(t, tests, p)
+}
+
+func TestParseBitwiseExpr(t *testing.T) {
+	p := parser.New()
+
+	tests := []ParserTest{
+		{
+			input: "1 | 2 ^ 3 & 4",
+			expectedExpr: []ast.Expr{
+				ast.BinaryExpr{
+					Kind: ast_types.BinaryExpr,
+					Left: ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+					Right: ast.BinaryExpr{
+						Kind: ast_types.BinaryExpr,
+						Left: ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 2},
+						Right: ast.BinaryExpr{
+							Kind:     ast_types.BinaryExpr,
+							Left:     ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 3},
+							Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 4},
+							Operator: "&",
+						},
+						Operator: "^",
+					},
+					Operator: "|",
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			input: "x & 1 == 1",
+			expectedExpr: []ast.Expr{
+				ast.BinaryExpr{
+					Kind: ast_types.BinaryExpr,
+					Left: ast.Identifier{Kind: ast_types.Identifier, Symbol: "x"},
+					Right: ast.BinaryExpr{
+						Kind:     ast_types.BinaryExpr,
+						Left:     ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+						Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+						Operator: "==",
+					},
+					Operator: "&",
+				},
+			},
+			expectedErr: nil,
+		},
+		{
+			input: "~x",
+			expectedExpr: []ast.Expr{
+				ast.UnaryExpr{
+					Kind:     ast_types.UnaryExpr,
+					Operator: "~",
+					Argument: ast.Identifier{Kind: ast_types.Identifier, Symbol: "x"},
+					Prefix:   true,
+				},
+			},
+			expectedErr: nil,
+		},
+	}
+
+	testParseExpr(t, tests, p)
+}
+
 func TestParseEqualityExpr(t *testing.T) {
 	p := parser.New()
 
@@ -560,3 +789,38 @@ func TestParseTernaryExpr(t *testing.T) {
 
 	testParseExpr(t, tests, p)
 }
+
+func TestParseTemplateLiteral(t *testing.T) {
+	p := parser.New()
+
+	tests := []ParserTest{
+		{
+			input: "`plain`",
+			expectedExpr: []ast.Expr{
+				ast.TemplateLiteral{Kind: ast_types.TemplateLiteral, Quasis: []string{"plain"}},
+			},
+			expectedErr: nil,
+		},
+		{
+			input: "`a ${x} b ${1 + 2}`",
+			expectedExpr: []ast.Expr{
+				ast.TemplateLiteral{
+					Kind:   ast_types.TemplateLiteral,
+					Quasis: []string{"a ", " b ", ""},
+					Expressions: []ast.Expr{
+						ast.Identifier{Kind: ast_types.Identifier, Symbol: "x"},
+						ast.BinaryExpr{
+							Kind:     ast_types.BinaryExpr,
+							Left:     ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 1},
+							Right:    ast.NumericLiteral{Kind: ast_types.NumericLiteral, Value: 2},
+							Operator: "+",
+						},
+					},
+				},
+			},
+			expectedErr: nil,
+		},
+	}
+
+	testParseExpr(t, tests, p)
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"errors"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+	"golang.org/x/exp/slices"
+)
+
+// Maximum number of syntax errors reported before the parser gives up
+const MaxErrors = 20
+
+// Tokens that can only appear at the start of a statement
+var syncTokens = []token_type.TokenType{
+	token_type.Var,
+	token_type.Const,
+	token_type.Fn,
+	token_type.If,
+	token_type.Switch,
+	token_type.Case,
+	token_type.Default,
+	token_type.Return,
+	token_type.While,
+	token_type.For,
+	token_type.Break,
+	token_type.Continue,
+	token_type.Try,
+	token_type.Throw,
+}
+
+/*
+ * Parses a statement, if it fails the error is recorded and the parser skips
+ * to the start of the next statement. The statement is then replaced with an
+ * ErrorNode so the caller still gets a tree to work with.
+ */
+func (p *Parser) parseStmtOrRecover() ast.Stmt {
+	start := p.at()
+	remaining := len(p.tokens)
+
+	stmt, err := p.parseStmt()
+
+	if err == nil {
+		return stmt
+	}
+
+	p.report(err, start)
+	p.synchronize(start, remaining)
+
+	return ast.ErrorNode{
+		Kind: ast_types.ErrorNode,
+		Span: p.spanFrom(start.Span),
+	}
+}
+
+func (p *Parser) report(err error, start token_type.Token) {
+	if len(p.diagnostics) >= MaxErrors {
+		return
+	}
+
+	var diag *diagnostic.Diagnostic
+	if !errors.As(err, &diag) {
+		diag = compilerErrors.ErrParsingError.WithNote(err.Error()).At(start.Span)
+	}
+
+	// A speculative parse may report the same error twice
+	for _, reported := range p.diagnostics {
+		if reported.Span.Start == diag.Span.Start {
+			return
+		}
+	}
+
+	if len(p.diagnostics) == MaxErrors-1 {
+		diag = diag.WithNote("too many errors, the rest of the file was not checked")
+		p.tokens = p.tokens[len(p.tokens)-1:] // Jump to EOF
+	}
+
+	p.diagnostics = append(p.diagnostics, diag)
+}
+
+/*
+ * Skips tokens until a statement boundary: a ';', a statement keyword or the
+ * first token of a new line. Blocks opened while skipping are skipped as a
+ * whole and a '}' closing the enclosing block is left for its owner.
+ */
+func (p *Parser) synchronize(start token_type.Token, remaining int) {
+	// Always make progress
+	if len(p.tokens) == remaining && p.notEOF() {
+		p.subtract()
+	}
+
+	depth := 0
+
+	for p.notEOF() {
+		tk := p.at()
+
+		switch tk.Type {
+		case token_type.Semicolon:
+			if depth == 0 {
+				p.subtract()
+				return
+			}
+		case token_type.LeftBrace:
+			depth++
+		case token_type.RightBrace:
+			if depth == 0 {
+				return
+			}
+
+			depth--
+			p.subtract()
+
+			if depth == 0 {
+				return
+			}
+
+			continue
+		}
+
+		if depth == 0 && (slices.Contains(syncTokens, tk.Type) || tk.Span.Start.Line > p.prev.Span.End.Line) {
+			return
+		}
+
+		p.subtract()
+	}
+}
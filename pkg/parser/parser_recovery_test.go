@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/parser"
+)
+
+func TestParseRecovery(t *testing.T) {
+	input := strings.Join([]string{
+		"var a = (1",
+		"fn add(x, y) {",
+		"  var = 2",
+		"  return x + y",
+		"}",
+		"print(add(1, 2)",
+		"var ok = true",
+	}, "\n")
+
+	program, err := parser.New().ProduceAST(input)
+
+	var list diagnostic.List
+	if !errors.As(err, &list) {
+		t.Fatalf("Expected a list of diagnostics, but got: %v", err)
+	}
+
+	expected := []struct {
+		err  error
+		line int
+	}{
+		{compilerErrors.ErrSyntaxExpectedRightParen, 1},
+		{compilerErrors.ErrVariableExpectedIdentifierNameFollowingConstOrVar, 3},
+		{compilerErrors.ErrSyntaxExpectedRightParen, 6},
+	}
+
+	if len(list) != len(expected) {
+		t.Fatalf("Expected %d errors, but got: %v", len(expected), list)
+	}
+
+	for idx, diag := range list {
+		if !errors.Is(diag, expected[idx].err) || diag.Span.Start.Line != expected[idx].line {
+			t.Errorf("Expected %v at line %d, but got: %v", expected[idx].err, expected[idx].line, diag)
+		}
+	}
+
+	if program == nil {
+		t.Fatalf("Expected a partial AST")
+	}
+
+	kinds := []ast_types.NodeType{}
+	for _, stmt := range program.Body {
+		kinds = append(kinds, stmt.GetKind())
+	}
+
+	expectedKinds := []ast_types.NodeType{
+		ast_types.ErrorNode,
+		ast_types.FunctionDeclaration,
+		ast_types.ErrorNode,
+		ast_types.VariableDeclaration,
+	}
+
+	if strings.Join(toStrings(kinds), ",") != strings.Join(toStrings(expectedKinds), ",") {
+		t.Fatalf("Expected statements %v, but got: %v", expectedKinds, kinds)
+	}
+
+	fn := program.Body[1].(ast.FunctionDeclaration)
+	if len(fn.Body) != 2 || fn.Body[0].GetKind() != ast_types.ErrorNode || fn.Body[1].GetKind() != ast_types.ReturnStatement {
+		t.Errorf("Expected the function body to be recovered, but got: %v", fn.Body)
+	}
+}
+
+func TestParseRecoveryStrayBrace(t *testing.T) {
+	program, err := parser.New().ProduceAST("var a = 1\n}\nvar b = 2")
+
+	if !errors.Is(err, compilerErrors.ErrSyntaxUnexpectedToken) {
+		t.Fatalf("Expected an unexpected token error, but got: %v", err)
+	}
+
+	if len(program.Body) != 2 {
+		t.Errorf("Expected both declarations to be parsed, but got: %v", program.Body)
+	}
+}
+
+func TestParseRecoveryLimit(t *testing.T) {
+	input := strings.Repeat("var = 1\n", parser.MaxErrors*5)
+
+	_, err := parser.New().ProduceAST(input)
+
+	var list diagnostic.List
+	if !errors.As(err, &list) {
+		t.Fatalf("Expected a list of diagnostics, but got: %v", err)
+	}
+
+	if len(list) != parser.MaxErrors {
+		t.Errorf("Expected the errors to be capped at %d, but got %d", parser.MaxErrors, len(list))
+	}
+}
+
+func toStrings(kinds []ast_types.NodeType) []string {
+	result := make([]string, len(kinds))
+	for idx, kind := range kinds {
+		result[idx] = string(kind)
+	}
+	return result
+}
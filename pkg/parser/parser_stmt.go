@@ -1,14 +1,28 @@
 package parser
 
 import (
-	"errors"
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 	"github.com/Waxer59/PikaLang/pkg/ast"
 	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+	"golang.org/x/exp/slices"
 )
 
+// Parses a single statement, errors recovered inside its blocks are returned too
 func (p *Parser) ParseStmt() (ast.Stmt, error) {
+	p.diagnostics = nil
+
+	stmt, err := p.parseStmt()
+
+	if err == nil && len(p.diagnostics) > 0 {
+		return stmt, diagnostic.List(p.diagnostics)
+	}
+
+	return stmt, err
+}
+
+func (p *Parser) parseStmt() (ast.Stmt, error) {
 	switch p.at().Type {
 	case token_type.Const, token_type.Var:
 		return p.parseVarConstDeclaration()
@@ -21,20 +35,64 @@ func (p *Parser) ParseStmt() (ast.Stmt, error) {
 	case token_type.Return:
 		return p.parseReturnStatement()
 	case token_type.While:
-		return p.parseWhileStatement()
+		return p.parseWhileStatement("")
 	case token_type.Break:
 		return p.parseBreakStatement()
 	case token_type.Continue:
 		return p.parseContinueStatement()
 	case token_type.For:
-		return p.parseForStatement()
+		return p.parseForStatement("")
+	case token_type.Try:
+		return p.parseTryStatement()
+	case token_type.Throw:
+		return p.parseThrowStatement()
+	case token_type.Identifier:
+		if p.atNext().Type == token_type.Colon && (p.peek(2).Type == token_type.While || p.peek(2).Type == token_type.For) {
+			return p.parseLabeledStatement()
+		}
+		return p.parseExpr()
 	default:
 		return p.parseExpr()
 	}
 }
 
-func (p *Parser) parseForStatement() (ast.Stmt, error) {
-	p.subtract() // consume 'for'
+// Parses loops preceded by a label as in `outer: for ... { break outer }`
+func (p *Parser) parseLabeledStatement() (ast.Stmt, error) {
+	label := p.subtract() // consume label
+	p.subtract()          // consume ':'
+
+	if slices.Contains(p.loopLabels, label.Value) {
+		return nil, compilerErrors.ErrLoopsDuplicatedLabel.WithArgs(label.Value).At(label.Span)
+	}
+
+	var stmt ast.Stmt
+	var err error
+
+	if p.at().Type == token_type.While {
+		stmt, err = p.parseWhileStatement(label.Value)
+	} else {
+		stmt, err = p.parseForStatement(label.Value)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The label is part of the statement
+	switch loop := stmt.(type) {
+	case ast.WhileStatement:
+		loop.Span.Start = label.Span.Start
+		return loop, nil
+	case ast.ForStatement:
+		loop.Span.Start = label.Span.Start
+		return loop, nil
+	}
+
+	return stmt, nil
+}
+
+func (p *Parser) parseForStatement(label string) (ast.Stmt, error) {
+	start := p.subtract() // consume 'for'
 
 	if p.at().Type == token_type.LeftParen { // Optional parenthesis
 		p.subtract()
@@ -84,7 +142,7 @@ func (p *Parser) parseForStatement() (ast.Stmt, error) {
 		p.subtract()
 	}
 
-	body, err := p.parseBlockBodyStmt()
+	body, err := p.parseLoopBody(label)
 
 	if err != nil {
 		return nil, err
@@ -96,27 +154,45 @@ func (p *Parser) parseForStatement() (ast.Stmt, error) {
 		Test:   test,
 		Update: update,
 		Body:   body,
+		Label:  label,
+		Span:   p.spanFrom(start.Span),
 	}, nil
 }
 
 func (p *Parser) parseContinueStatement() (ast.Stmt, error) {
-	p.subtract() // consume 'continue'
+	start := p.subtract() // consume 'continue'
+
+	label, err := p.parseJumpLabel(start, compilerErrors.ErrLoopsContinueNotInLoop)
+
+	if err != nil {
+		return nil, err
+	}
 
 	return ast.ContinueStatement{
-		Kind: ast_types.ContinueStatement,
+		Kind:  ast_types.ContinueStatement,
+		Label: label,
+		Span:  p.spanFrom(start.Span),
 	}, nil
 }
 
 func (p *Parser) parseBreakStatement() (ast.Stmt, error) {
-	p.subtract() // consume 'break'
+	start := p.subtract() // consume 'break'
+
+	label, err := p.parseJumpLabel(start, compilerErrors.ErrLoopsBreakNotInLoop)
+
+	if err != nil {
+		return nil, err
+	}
 
 	return ast.BreakStatement{
-		Kind: ast_types.BreakStatement,
+		Kind:  ast_types.BreakStatement,
+		Label: label,
+		Span:  p.spanFrom(start.Span),
 	}, nil
 }
 
-func (p *Parser) parseWhileStatement() (ast.Stmt, error) {
-	p.subtract() // consume 'while'
+func (p *Parser) parseWhileStatement(label string) (ast.Stmt, error) {
+	start := p.subtract() // consume 'while'
 
 	if p.at().Type == token_type.LeftParen { // Optional parenthesis
 		p.subtract()
@@ -132,21 +208,116 @@ func (p *Parser) parseWhileStatement() (ast.Stmt, error) {
 		p.subtract()
 	}
 
-	body, err := p.parseBlockBodyStmt()
+	body, err := p.parseLoopBody(label)
 
 	if err != nil {
 		return nil, err
 	}
 
 	return ast.WhileStatement{
-		Kind: ast_types.WhileStatement,
-		Test: test,
-		Body: body,
+		Kind:  ast_types.WhileStatement,
+		Test:  test,
+		Body:  body,
+		Label: label,
+		Span:  p.spanFrom(start.Span),
+	}, nil
+}
+
+// Parses `try { } catch (e) { } finally { }`, the catch parameter is optional
+// and one of catch and finally can be left out
+func (p *Parser) parseTryStatement() (ast.Stmt, error) {
+	start := p.subtract() // consume 'try'
+
+	block, err := p.parseBlockBodyStmt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var handler *ast.CatchClause
+	var finalizer []ast.Stmt
+
+	if p.at().Type == token_type.Catch {
+		catchStart := p.subtract() // consume 'catch'
+		param := ""
+
+		if p.at().Type == token_type.LeftParen {
+			p.subtract() // consume '('
+
+			name, err := p.expect(token_type.Identifier, compilerErrors.ErrSyntaxExpectedCatchParameter)
+			if err != nil {
+				return nil, err
+			}
+			param = name.Value
+
+			_, err = p.expect(token_type.RightParen, compilerErrors.ErrSyntaxExpectedRightParen)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		body, err := p.parseBlockBodyStmt()
+
+		if err != nil {
+			return nil, err
+		}
+
+		handler = &ast.CatchClause{
+			Param: param,
+			Body:  body,
+			Span:  p.spanFrom(catchStart.Span),
+		}
+	}
+
+	if p.at().Type == token_type.Finally {
+		p.subtract() // consume 'finally'
+
+		finalizer, err = p.parseBlockBodyStmt()
+
+		if err != nil {
+			return nil, err
+		}
+
+		// An empty finally block is still a finally block
+		if finalizer == nil {
+			finalizer = []ast.Stmt{}
+		}
+	}
+
+	if handler == nil && finalizer == nil {
+		return nil, compilerErrors.ErrSyntaxExpectedCatchOrFinally.At(p.at().Span)
+	}
+
+	return ast.TryStatement{
+		Kind:      ast_types.TryStatement,
+		Block:     block,
+		Handler:   handler,
+		Finalizer: finalizer,
+		Span:      p.spanFrom(start.Span),
+	}, nil
+}
+
+func (p *Parser) parseThrowStatement() (ast.Stmt, error) {
+	start := p.subtract() // consume 'throw'
+
+	arg, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.ThrowStatement{
+		Kind:     ast_types.ThrowStatement,
+		Argument: arg,
+		Span:     p.spanFrom(start.Span),
 	}, nil
 }
 
 func (p *Parser) parseReturnStatement() (ast.Stmt, error) {
-	p.subtract() // consume 'return'
+	start := p.subtract() // consume 'return'
+
+	if p.functionDepth == 0 {
+		return nil, compilerErrors.ErrReturn.At(start.Span)
+	}
 
 	if p.at().Type == token_type.Semicolon || p.at().Type == token_type.RightBrace {
 		if p.at().Type != token_type.RightBrace {
@@ -155,6 +326,7 @@ func (p *Parser) parseReturnStatement() (ast.Stmt, error) {
 		return ast.ReturnStatement{
 			Kind:     ast_types.ReturnStatement,
 			Argument: nil,
+			Span:     p.spanFrom(start.Span),
 		}, nil
 	}
 
@@ -166,11 +338,12 @@ func (p *Parser) parseReturnStatement() (ast.Stmt, error) {
 	return ast.ReturnStatement{
 		Kind:     ast_types.ReturnStatement,
 		Argument: arg,
+		Span:     p.spanFrom(start.Span),
 	}, nil
 }
 
 func (p *Parser) parseSwitchStatement() (ast.Stmt, error) {
-	p.subtract() // consume 'switch'
+	start := p.subtract() // consume 'switch'
 
 	arg, err := p.parseConditionalArg()
 
@@ -189,7 +362,7 @@ func (p *Parser) parseSwitchStatement() (ast.Stmt, error) {
 
 	for p.at().Type != token_type.RightBrace && p.notEOF() {
 		if p.at().Type == token_type.Case {
-			p.subtract() // consume 'case'
+			caseStart := p.subtract() // consume 'case'
 
 			caseCondition, err := p.parseSwitchCaseArgs()
 
@@ -206,11 +379,12 @@ func (p *Parser) parseSwitchStatement() (ast.Stmt, error) {
 			caseStmts = append(caseStmts, ast.CaseStatement{
 				Test: caseCondition,
 				Body: body,
+				Span: p.spanFrom(caseStart.Span),
 			})
 		}
 
 		if p.at().Type == token_type.Default {
-			p.subtract() // consume 'default'
+			defaultStart := p.subtract() // consume 'default'
 			_, err := p.expect(token_type.Colon, compilerErrors.ErrSyntaxExpectedColon)
 			if err != nil {
 				return nil, err
@@ -224,6 +398,7 @@ func (p *Parser) parseSwitchStatement() (ast.Stmt, error) {
 			defaultStmt = ast.CaseStatement{
 				Test: nil,
 				Body: body,
+				Span: p.spanFrom(defaultStart.Span),
 			}
 			break
 		}
@@ -239,13 +414,14 @@ func (p *Parser) parseSwitchStatement() (ast.Stmt, error) {
 		Discriminant: condition,
 		CaseStmts:    caseStmts,
 		DefaultStmt:  defaultStmt,
+		Span:         p.spanFrom(start.Span),
 	}, nil
 }
 
 func (p *Parser) parseIfStatement() (ast.Stmt, error) {
 	var elseBody []ast.Stmt = nil
 
-	p.subtract() // consume 'if'
+	start := p.subtract() // consume 'if'
 
 	arg, err := p.parseConditionalArg()
 
@@ -283,6 +459,7 @@ func (p *Parser) parseIfStatement() (ast.Stmt, error) {
 		Body:       body,
 		ElseBody:   elseBody,
 		ElseIfStmt: elseIfStmt,
+		Span:       p.spanFrom(start.Span),
 	}, nil
 }
 
@@ -290,7 +467,7 @@ func (p *Parser) parseElseIfStatement() ([]ast.ElseIfStatement, error) {
 	var elseIfStmt []ast.ElseIfStatement = nil
 
 	for p.at().Type == token_type.Else && p.atNext().Type == token_type.If && p.notEOF() {
-		p.subtract(2) // consume 'else' & 'if'
+		start := p.subtract(2) // consume 'else' & 'if'
 		arg, err := p.parseConditionalArg()
 
 		if err != nil {
@@ -308,6 +485,7 @@ func (p *Parser) parseElseIfStatement() ([]ast.ElseIfStatement, error) {
 		elseIfStmt = append(elseIfStmt, ast.ElseIfStatement{
 			Test: condition,
 			Body: body,
+			Span: p.spanFrom(start.Span),
 		})
 	}
 
@@ -315,7 +493,7 @@ func (p *Parser) parseElseIfStatement() ([]ast.ElseIfStatement, error) {
 }
 
 func (p *Parser) parseFnDeclaration() (ast.Stmt, error) {
-	p.subtract() // consume 'fn'
+	start := p.subtract() // consume 'fn'
 
 	name, err := p.expect(token_type.Identifier, compilerErrors.ErrFuncExpectedIdentifer)
 
@@ -329,7 +507,7 @@ func (p *Parser) parseFnDeclaration() (ast.Stmt, error) {
 		return nil, err
 	}
 
-	body, err := p.parseBlockBodyStmt()
+	body, err := p.parseFunctionBody()
 
 	if err != nil {
 		return nil, err
@@ -340,11 +518,13 @@ func (p *Parser) parseFnDeclaration() (ast.Stmt, error) {
 		Name:   name.Value,
 		Params: params,
 		Body:   body,
+		Span:   p.spanFrom(start.Span),
 	}, nil
 }
 
 func (p *Parser) parseVarConstDeclaration() (ast.Stmt, error) {
-	isConstant := p.subtract().Type == token_type.Const
+	start := p.subtract() // consume 'var' or 'const'
+	isConstant := start.Type == token_type.Const
 
 	identifierToken, err := p.expect(token_type.Identifier, compilerErrors.ErrVariableExpectedIdentifierNameFollowingConstOrVar)
 
@@ -356,7 +536,7 @@ func (p *Parser) parseVarConstDeclaration() (ast.Stmt, error) {
 
 	if p.at().Type != token_type.Equals {
 		if isConstant {
-			return nil, errors.New(compilerErrors.ErrVariableIsConstant)
+			return nil, compilerErrors.ErrVariableConstantMustBeInitialized.WithArgs(identifier).At(identifierToken.Span)
 		}
 
 		return ast.VariableDeclaration{
@@ -364,6 +544,7 @@ func (p *Parser) parseVarConstDeclaration() (ast.Stmt, error) {
 			Constant:   false,
 			Identifier: identifier,
 			Value:      nil,
+			Span:       p.spanFrom(start.Span),
 		}, nil
 	}
 
@@ -383,6 +564,7 @@ func (p *Parser) parseVarConstDeclaration() (ast.Stmt, error) {
 		Constant:   isConstant,
 		Identifier: identifier,
 		Value:      expr,
+		Span:       p.spanFrom(start.Span),
 	}
 
 	return declaration, nil
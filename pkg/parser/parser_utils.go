@@ -1,10 +1,11 @@
 package parser
 
 import (
-	"errors"
 	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
 	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
 	"github.com/Waxer59/PikaLang/pkg/lexer/token_type"
+	"golang.org/x/exp/slices"
 )
 
 func (p *Parser) at() token_type.Token {
@@ -18,6 +19,13 @@ func (p *Parser) atNext() token_type.Token {
 	return token_type.Token{}
 }
 
+func (p *Parser) peek(n int) token_type.Token {
+	if len(p.tokens) > n {
+		return p.tokens[n]
+	}
+	return token_type.Token{}
+}
+
 func (p *Parser) subtract(params ...int) token_type.Token {
 	n := 1
 	if len(params) > 0 {
@@ -25,18 +33,47 @@ func (p *Parser) subtract(params ...int) token_type.Token {
 	}
 
 	prev := p.at()
+	p.prev = p.tokens[n-1]
 	p.tokens = p.tokens[n:]
 	return prev
 }
 
-func (p *Parser) expect(typeExpected token_type.TokenType, errMsg string) (token_type.Token, error) {
-	prev := p.subtract()
+// Returns a span going from start to the end of the last consumed token
+func (p *Parser) spanFrom(start token_type.Span) token_type.Span {
+	return token_type.Span{
+		File:  start.File,
+		Start: start.Start,
+		End:   p.prev.Span.End,
+	}
+}
+
+// Consumes the current token if it has the expected type, otherwise the token
+// is left in place so the parser can recover from it
+func (p *Parser) expect(typeExpected token_type.TokenType, diag *diagnostic.Diagnostic) (token_type.Token, error) {
+	found := p.at()
+
+	if found.Type != typeExpected {
+		span := found.Span
+
+		// Point right after the previous token when the missing one should have ended its line
+		if p.prev.Span.End.Line > 0 && found.Span.Start.Line > p.prev.Span.End.Line {
+			span = token_type.Span{File: span.File, Start: p.prev.Span.End, End: p.prev.Span.End}
+		}
 
-	if (prev == token_type.Token{} || prev.Type != typeExpected) {
-		return prev, errors.New(errMsg)
+		return found, diag.At(span)
 	}
 
-	return prev, nil
+	return p.subtract(), nil
+}
+
+// Returns the value of tk when it can be an operator, the text of strings and templates never is one
+func operator(tk token_type.Token) string {
+	switch tk.Type {
+	case token_type.StringLiteral, token_type.Template, token_type.TemplateHead, token_type.TemplateMiddle, token_type.TemplateTail:
+		return ""
+	}
+
+	return tk.Value
 }
 
 func (p *Parser) notEOF() bool {
@@ -50,7 +87,7 @@ func (p *Parser) parseConditionalArg() (ast.Expr, error) {
 	}
 
 	if p.at().Type == token_type.RightParen || p.at().Type == token_type.LeftBrace {
-		return nil, errors.New(compilerErrors.ErrSyntaxConditionCantBeEmpty)
+		return nil, compilerErrors.ErrSyntaxConditionCantBeEmpty.At(p.at().Span)
 	}
 
 	condition, err := p.parseExpr()
@@ -60,7 +97,7 @@ func (p *Parser) parseConditionalArg() (ast.Expr, error) {
 	}
 
 	if condition == nil {
-		return nil, errors.New(compilerErrors.ErrConditionCannotBeEmpty)
+		return nil, compilerErrors.ErrConditionCannotBeEmpty.At(p.at().Span)
 	}
 
 	if p.at().Type == token_type.RightParen { // Optional parens
@@ -136,7 +173,7 @@ func (p *Parser) parseCallExprArgs() ([]ast.Expr, error) {
 
 func (p *Parser) parseSwitchCaseArgs() ([]ast.Expr, error) {
 	if p.at().Type == token_type.Colon {
-		return nil, errors.New(compilerErrors.ErrSyntaxCaseCannotBeEmpty)
+		return nil, compilerErrors.ErrSyntaxCaseCannotBeEmpty.At(p.at().Span)
 	}
 
 	argsList, err := p.parseArgsList()
@@ -184,11 +221,7 @@ func (p *Parser) parseBlockBodyStmt() ([]ast.Stmt, error) {
 	}
 
 	for p.at().Type != token_type.RightBrace && p.notEOF() {
-		stmt, err := p.ParseStmt()
-		if err != nil {
-			return nil, err
-		}
-		body = append(body, stmt)
+		body = append(body, p.parseStmtOrRecover())
 	}
 
 	_, err = p.expect(token_type.RightBrace, compilerErrors.ErrSyntaxExpectedRightBrace)
@@ -203,11 +236,51 @@ func (p *Parser) parseSwitchBodyStmt() ([]ast.Stmt, error) {
 	var body []ast.Stmt
 
 	for p.at().Type != token_type.RightBrace && p.at().Type != token_type.Case && p.at().Type != token_type.Default && p.notEOF() {
-		stmt, err := p.ParseStmt()
-		if err != nil {
-			return nil, err
-		}
-		body = append(body, stmt)
+		body = append(body, p.parseStmtOrRecover())
 	}
 	return body, nil
 }
+
+// Parses the body of a function, loops of the enclosing code can't be targeted from it
+func (p *Parser) parseFunctionBody() ([]ast.Stmt, error) {
+	loopLabels := p.loopLabels
+	p.loopLabels = nil
+	p.functionDepth++
+
+	defer func() {
+		p.loopLabels = loopLabels
+		p.functionDepth--
+	}()
+
+	return p.parseBlockBodyStmt()
+}
+
+// Parses the body of a loop, making it a target for break and continue statements
+func (p *Parser) parseLoopBody(label string) ([]ast.Stmt, error) {
+	p.loopLabels = append(p.loopLabels, label)
+
+	defer func() {
+		p.loopLabels = p.loopLabels[:len(p.loopLabels)-1]
+	}()
+
+	return p.parseBlockBodyStmt()
+}
+
+// Parses the optional label following 'break' or 'continue', it has to be on the same line
+func (p *Parser) parseJumpLabel(keyword token_type.Token, notInLoop *diagnostic.Diagnostic) (string, error) {
+	label := ""
+
+	if p.at().Type == token_type.Identifier && p.at().Span.Start.Line == keyword.Span.End.Line {
+		label = p.subtract().Value
+	}
+
+	if len(p.loopLabels) == 0 {
+		return "", notInLoop.At(p.spanFrom(keyword.Span))
+	}
+
+	if label != "" && !slices.Contains(p.loopLabels, label) {
+		return "", compilerErrors.ErrLoopsUndefinedLabel.WithArgs(label).At(p.prev.Span)
+	}
+
+	return label, nil
+}
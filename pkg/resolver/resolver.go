@@ -0,0 +1,183 @@
+/*
+ * Package resolver runs between the parser and the interpreter. It binds
+ * every identifier to the scope that declares it, so the interpreter can
+ * find variables by index instead of by name, and reports undeclared
+ * variables, assignments to constants and duplicate declarations before
+ * the program runs.
+ *
+ * The scopes created by the resolver have to match the environments created
+ * by the interpreter: the program, every block, the header of a for loop and
+ * the parameters of a function each get their own scope.
+ */
+package resolver
+
+import (
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+)
+
+// Tells the resolver which names are host functions, the native functions of
+// the language are always known
+type HostFunctions interface {
+	Has(name string) bool
+}
+
+// A variable that is already declared in the global scope, in the order of declaration
+type Global struct {
+	Name     string
+	Constant bool
+}
+
+type scope struct {
+	names     []string
+	constants []bool
+	// Functions defined in the scope, resolved when the scope ends
+	functions []function
+}
+
+/*
+ * The body of a function is resolved when the scope defining it ends, so a
+ * function can use the variables and functions declared after it in that
+ * scope. The resolved statements are written in body, which is shared with
+ * the rewritten node.
+ */
+type function struct {
+	params []ast.Identifier
+	source []ast.Stmt
+	body   []ast.Stmt
+}
+
+type resolver struct {
+	scopes        []*scope
+	hostFunctions HostFunctions
+	diagnostics   []*diagnostic.Diagnostic
+}
+
+// Returns a copy of the program with every identifier bound to its declaration
+// and every call to a native function marked, hostFunctions can be nil
+func Resolve(program ast.Program, globals []Global, hostFunctions HostFunctions) (ast.Program, error) {
+	r := &resolver{hostFunctions: hostFunctions}
+	r.beginScope()
+
+	for _, global := range globals {
+		r.declare(global.Name, global.Constant)
+	}
+
+	program.Body = r.resolveStmts(program.Body)
+	r.endScope()
+
+	if len(r.diagnostics) > 0 {
+		return program, diagnostic.List(r.diagnostics)
+	}
+
+	return program, nil
+}
+
+func (r *resolver) beginScope() {
+	r.scopes = append(r.scopes, &scope{})
+}
+
+func (r *resolver) endScope() {
+	current := r.scopes[len(r.scopes)-1]
+
+	for _, fn := range current.functions {
+		r.beginScope()
+
+		for _, param := range fn.params {
+			r.declareAt(param.Symbol, false, param)
+		}
+
+		copy(fn.body, r.resolveStmts(fn.source))
+		r.endScope()
+	}
+
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *resolver) report(diag *diagnostic.Diagnostic) {
+	r.diagnostics = append(r.diagnostics, diag)
+}
+
+func (r *resolver) declare(name string, constant bool) {
+	current := r.scopes[len(r.scopes)-1]
+	current.names = append(current.names, name)
+	current.constants = append(current.constants, constant)
+}
+
+// Declares a variable reporting it if the scope already has one with the same name
+func (r *resolver) declareAt(name string, constant bool, node ast.Stmt) {
+	for _, declared := range r.scopes[len(r.scopes)-1].names {
+		if declared == name {
+			r.report(compilerErrors.ErrVariableAlreadyExists.WithArgs(name).At(node.GetSpan()))
+			return
+		}
+	}
+
+	r.declare(name, constant)
+}
+
+// Defers the resolution of a function body until the current scope ends
+func (r *resolver) defineFunction(params []ast.Identifier, body []ast.Stmt) []ast.Stmt {
+	current := r.scopes[len(r.scopes)-1]
+	resolved := make([]ast.Stmt, len(body))
+	current.functions = append(current.functions, function{params: params, source: body, body: resolved})
+
+	return resolved
+}
+
+// Returns the identifier bound to the innermost variable with its name, the
+// second return value tells if the variable is a constant
+func (r *resolver) lookup(ident ast.Identifier) (ast.Identifier, bool) {
+	for depth := 0; depth < len(r.scopes); depth++ {
+		s := r.scopes[len(r.scopes)-1-depth]
+
+		for slot, name := range s.names {
+			if name == ident.Symbol {
+				ident.Depth = depth
+				ident.Slot = slot
+				return ident, s.constants[slot]
+			}
+		}
+	}
+
+	r.report(compilerErrors.ErrVariableDoesNotExist.WithArgs(ident.Symbol).At(ident.Span))
+	return ident, false
+}
+
+func (r *resolver) resolveIdentifier(ident ast.Identifier) ast.Identifier {
+	ident, _ = r.lookup(ident)
+	return ident
+}
+
+// Resolves an identifier that is going to be assigned
+func (r *resolver) resolveTarget(ident ast.Identifier) ast.Identifier {
+	ident, constant := r.lookup(ident)
+
+	if constant {
+		r.report(compilerErrors.ErrVariableIsConstant.WithArgs(ident.Symbol).At(ident.Span))
+	}
+
+	return ident
+}
+
+func (r *resolver) isDeclared(name string) bool {
+	for _, s := range r.scopes {
+		for _, declared := range s.names {
+			if declared == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r *resolver) isNative(name string) bool {
+	if _, ok := nativeFns.NativeFunctions[name]; ok {
+		return true
+	}
+
+	return r.hostFunctions != nil && r.hostFunctions.Has(name)
+}
@@ -0,0 +1,176 @@
+package resolver
+
+import (
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+)
+
+func (r *resolver) resolveExprs(exprs []ast.Expr) []ast.Expr {
+	resolved := make([]ast.Expr, len(exprs))
+
+	for idx, expr := range exprs {
+		resolved[idx] = r.resolveExpr(expr)
+	}
+
+	return resolved
+}
+
+func (r *resolver) resolveExpr(expr ast.Expr) ast.Expr {
+	switch expr.GetKind() {
+	case ast_types.Identifier:
+		return r.resolveIdentifier(expr.(ast.Identifier))
+	case ast_types.ArrayLiteral:
+		arrayExpr := expr.(ast.ArrayLiteral)
+		arrayExpr.Elements = r.resolveExprs(arrayExpr.Elements)
+		return arrayExpr
+	case ast_types.TemplateLiteral:
+		template := expr.(ast.TemplateLiteral)
+		template.Expressions = r.resolveExprs(template.Expressions)
+		return template
+	case ast_types.ObjectLiteral:
+		return r.resolveObjectLiteral(expr.(ast.ObjectLiteral))
+	case ast_types.BinaryExpr:
+		binop := expr.(ast.BinaryExpr)
+		binop.Left = r.resolveExpr(binop.Left)
+		binop.Right = r.resolveExpr(binop.Right)
+		return binop
+	case ast_types.LogicalExpr:
+		logicalExpr := expr.(ast.LogicalExpr)
+		logicalExpr.Left = r.resolveExpr(logicalExpr.Left)
+		logicalExpr.Right = r.resolveExpr(logicalExpr.Right)
+		return logicalExpr
+	case ast_types.UnaryExpr:
+		unaryExpr := expr.(ast.UnaryExpr)
+		unaryExpr.Argument = r.resolveExpr(unaryExpr.Argument)
+		return unaryExpr
+	case ast_types.UpdateExpr:
+		updateExpr := expr.(ast.UpdateExpr)
+		updateExpr.Argument = r.resolveTarget(updateExpr.Argument)
+		return updateExpr
+	case ast_types.ConditionalExpr:
+		conditionalExpr := expr.(ast.ConditionalExpr)
+		conditionalExpr.Condition = r.resolveExpr(conditionalExpr.Condition)
+		conditionalExpr.Consequent = r.resolveExpr(conditionalExpr.Consequent)
+		conditionalExpr.Alternate = r.resolveExpr(conditionalExpr.Alternate)
+		return conditionalExpr
+	case ast_types.AssigmentExpr:
+		return r.resolveAssignment(expr.(ast.AssigmentExpr))
+	case ast_types.MemberExpr:
+		return r.resolveMemberExpr(expr.(ast.MemberExpr))
+	case ast_types.CallExpr:
+		return r.resolveCallExpr(expr.(ast.CallExpr))
+	case ast_types.ArrowFunctionExpr:
+		funcExpr := expr.(ast.ArrowFunctionExpr)
+		funcExpr.Body = r.defineFunction(funcExpr.Params, funcExpr.Body)
+		return funcExpr
+	case ast_types.VariableDeclaration:
+		return r.resolveStmt(expr)
+	default:
+		return expr
+	}
+}
+
+// A property without value takes the variable with the same name
+func (r *resolver) resolveObjectLiteral(objectExpr ast.ObjectLiteral) ast.ObjectLiteral {
+	properties := make([]ast.Property, len(objectExpr.Properties))
+
+	for idx, property := range objectExpr.Properties {
+		if property.Value == nil {
+			property.Value = r.resolveIdentifier(ast.Identifier{
+				Kind:   ast_types.Identifier,
+				Symbol: property.Key,
+				Span:   property.Span,
+			})
+		} else {
+			property.Value = r.resolveExpr(property.Value)
+		}
+
+		properties[idx] = property
+	}
+
+	objectExpr.Properties = properties
+
+	return objectExpr
+}
+
+func (r *resolver) resolveAssignment(assignment ast.AssigmentExpr) ast.AssigmentExpr {
+	assignment.Value = r.resolveExpr(assignment.Value)
+
+	if ident, ok := assignment.Assigne.(ast.Identifier); ok {
+		assignment.Assigne = r.resolveTarget(ident)
+	} else {
+		assignment.Assigne = r.resolveExpr(assignment.Assigne)
+	}
+
+	return assignment
+}
+
+// The property of a member expression is only a variable when it is computed
+func (r *resolver) resolveMemberExpr(expr ast.MemberExpr) ast.MemberExpr {
+	expr.Object = r.resolveExpr(expr.Object)
+
+	if expr.Computed {
+		expr.Property = r.resolveExpr(expr.Property)
+	}
+
+	return expr
+}
+
+// Native functions are called by name, so their names aren't variables
+func (r *resolver) resolveCallExpr(expr ast.CallExpr) ast.CallExpr {
+	expr.Args = r.resolveExprs(expr.Args)
+	expr.Native = r.nativeName(expr.Caller)
+
+	if expr.Native == "" {
+		expr.Caller = r.resolveExpr(expr.Caller)
+	}
+
+	return expr
+}
+
+/*
+ * Returns the name of the native function called by caller, or "" if it
+ * calls a value. Variables hide the native functions and namespaces with
+ * their name, so registering a function never changes what the functions
+ * declared by a script do.
+ */
+func (r *resolver) nativeName(caller ast.Expr) string {
+	switch fn := caller.(type) {
+	case ast.Identifier:
+		if !r.isDeclared(fn.Symbol) && r.isNative(fn.Symbol) {
+			return fn.Symbol
+		}
+	case ast.MemberExpr:
+		namespace, ok := fn.Object.(ast.Identifier)
+
+		if ok && !fn.Computed && !r.isDeclared(namespace.Symbol) {
+			name := namespace.Symbol + "." + fn.Property.(ast.Identifier).Symbol
+
+			if r.isNative(name) {
+				return name
+			}
+		}
+
+		// Methods named like a native function of the language call it with their arguments
+		if name := memberName(fn); name != "" {
+			if _, isNative := nativeFns.NativeFunctions[name]; isNative {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+func memberName(member ast.MemberExpr) string {
+	if !member.Computed {
+		return member.Property.(ast.Identifier).Symbol
+	}
+
+	if property, ok := member.Property.(ast.StringLiteral); ok {
+		return property.Value
+	}
+
+	return ""
+}
@@ -0,0 +1,162 @@
+package resolver
+
+import (
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/ast/ast_types"
+)
+
+func (r *resolver) resolveStmts(body []ast.Stmt) []ast.Stmt {
+	resolved := make([]ast.Stmt, len(body))
+
+	for idx, stmt := range body {
+		resolved[idx] = r.resolveStmt(stmt)
+	}
+
+	return resolved
+}
+
+func (r *resolver) resolveBlock(body []ast.Stmt) []ast.Stmt {
+	r.beginScope()
+	resolved := r.resolveStmts(body)
+	r.endScope()
+
+	return resolved
+}
+
+func (r *resolver) resolveStmt(stmt ast.Stmt) ast.Stmt {
+	switch stmt.GetKind() {
+	case ast_types.VariableDeclaration:
+		declaration := stmt.(ast.VariableDeclaration)
+
+		if declaration.Value != nil {
+			declaration.Value = r.resolveExpr(declaration.Value)
+		}
+
+		r.declareAt(declaration.Identifier, declaration.Constant, declaration)
+		return declaration
+	case ast_types.FunctionDeclaration:
+		declaration := stmt.(ast.FunctionDeclaration)
+		r.declareAt(declaration.Name, true, declaration)
+		declaration.Body = r.defineFunction(declaration.Params, declaration.Body)
+		return declaration
+	case ast_types.IfStatement:
+		return r.resolveIfStatement(stmt.(ast.IfStatement))
+	case ast_types.SwitchStatement:
+		return r.resolveSwitchStatement(stmt.(ast.SwitchStatement))
+	case ast_types.WhileStatement:
+		declaration := stmt.(ast.WhileStatement)
+		declaration.Test = r.resolveExpr(declaration.Test)
+		declaration.Body = r.resolveBlock(declaration.Body)
+		return declaration
+	case ast_types.ForStatement:
+		return r.resolveForStatement(stmt.(ast.ForStatement))
+	case ast_types.ReturnStatement:
+		declaration := stmt.(ast.ReturnStatement)
+
+		if declaration.Argument != nil {
+			declaration.Argument = r.resolveExpr(declaration.Argument)
+		}
+
+		return declaration
+	case ast_types.TryStatement:
+		return r.resolveTryStatement(stmt.(ast.TryStatement))
+	case ast_types.ThrowStatement:
+		declaration := stmt.(ast.ThrowStatement)
+		declaration.Argument = r.resolveExpr(declaration.Argument)
+		return declaration
+	case ast_types.BreakStatement, ast_types.ContinueStatement, ast_types.ErrorNode:
+		return stmt
+	default:
+		return r.resolveExpr(stmt)
+	}
+}
+
+func (r *resolver) resolveIfStatement(declaration ast.IfStatement) ast.IfStatement {
+	declaration.Test = r.resolveExpr(declaration.Test)
+	declaration.Body = r.resolveBlock(declaration.Body)
+
+	elseIfStmts := make([]ast.ElseIfStatement, len(declaration.ElseIfStmt))
+
+	for idx, elseIfStatement := range declaration.ElseIfStmt {
+		elseIfStatement.Test = r.resolveExpr(elseIfStatement.Test)
+		elseIfStatement.Body = r.resolveBlock(elseIfStatement.Body)
+		elseIfStmts[idx] = elseIfStatement
+	}
+
+	if declaration.ElseIfStmt != nil {
+		declaration.ElseIfStmt = elseIfStmts
+	}
+
+	if declaration.ElseBody != nil {
+		declaration.ElseBody = r.resolveBlock(declaration.ElseBody)
+	}
+
+	return declaration
+}
+
+func (r *resolver) resolveSwitchStatement(declaration ast.SwitchStatement) ast.SwitchStatement {
+	declaration.Discriminant = r.resolveExpr(declaration.Discriminant)
+
+	caseStmts := make([]ast.CaseStatement, len(declaration.CaseStmts))
+
+	for idx, caseStatement := range declaration.CaseStmts {
+		caseStatement.Test = r.resolveExprs(caseStatement.Test)
+		caseStatement.Body = r.resolveBlock(caseStatement.Body)
+		caseStmts[idx] = caseStatement
+	}
+
+	declaration.CaseStmts = caseStmts
+
+	if declaration.DefaultStmt.Body != nil {
+		declaration.DefaultStmt.Body = r.resolveBlock(declaration.DefaultStmt.Body)
+	}
+
+	return declaration
+}
+
+// The header of the loop has its own scope, the body is a block inside of it
+func (r *resolver) resolveForStatement(declaration ast.ForStatement) ast.ForStatement {
+	r.beginScope()
+
+	if declaration.Init != nil {
+		declaration.Init = r.resolveStmt(declaration.Init)
+	}
+
+	if declaration.Test != nil {
+		declaration.Test = r.resolveExpr(declaration.Test)
+	}
+
+	declaration.Body = r.resolveBlock(declaration.Body)
+
+	if declaration.Update != nil {
+		declaration.Update = r.resolveExpr(declaration.Update)
+	}
+
+	r.endScope()
+
+	return declaration
+}
+
+// The parameter of the catch block is declared in the scope of its body
+func (r *resolver) resolveTryStatement(declaration ast.TryStatement) ast.TryStatement {
+	declaration.Block = r.resolveBlock(declaration.Block)
+
+	if declaration.Handler != nil {
+		handler := *declaration.Handler
+
+		r.beginScope()
+		if handler.Param != "" {
+			r.declare(handler.Param, false)
+		}
+		handler.Body = r.resolveStmts(handler.Body)
+		r.endScope()
+
+		declaration.Handler = &handler
+	}
+
+	if declaration.Finalizer != nil {
+		declaration.Finalizer = r.resolveBlock(declaration.Finalizer)
+	}
+
+	return declaration
+}
@@ -0,0 +1,202 @@
+package resolver_test
+
+import (
+	"errors"
+	"testing"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/parser"
+	"github.com/Waxer59/PikaLang/pkg/resolver"
+)
+
+func resolve(t *testing.T, input string, globals []resolver.Global) (ast.Program, error) {
+	program, err := parser.New().ProduceAST(input)
+
+	if err != nil {
+		t.Fatalf("Expected no syntax errors, but got: %v", err)
+	}
+
+	return resolver.Resolve(*program, globals, nil)
+}
+
+func TestResolveSlots(t *testing.T) {
+	program, err := resolve(t, `
+		var a = 1
+		var b = 2
+		fn f(x) {
+			var y = x
+			return b + y
+		}`, nil)
+
+	if err != nil {
+		t.Fatalf("Expected no error, but got: %v", err)
+	}
+
+	fn := program.Body[2].(ast.FunctionDeclaration)
+	y := fn.Body[0].(ast.VariableDeclaration).Value.(ast.Identifier)
+	ret := fn.Body[1].(ast.ReturnStatement).Argument.(ast.BinaryExpr)
+
+	tests := []struct {
+		ident       ast.Identifier
+		depth, slot int
+	}{
+		{y, 0, 0},
+		{ret.Left.(ast.Identifier), 1, 1},
+		{ret.Right.(ast.Identifier), 0, 1},
+	}
+
+	for _, test := range tests {
+		if test.ident.Depth != test.depth || test.ident.Slot != test.slot {
+			t.Errorf("Expected %s to be at (%d, %d), but got: (%d, %d)",
+				test.ident.Symbol, test.depth, test.slot, test.ident.Depth, test.ident.Slot)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		globals     []resolver.Global
+		expectedErr error
+	}{
+		{
+			name:        "undeclared variable",
+			input:       "var a = b",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name:        "undeclared variable inside a function",
+			input:       "fn f() { return missing }",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name:        "assignment to a constant",
+			input:       "const a = 1\nfn f() { a += 1 }",
+			expectedErr: compilerErrors.ErrVariableIsConstant,
+		},
+		{
+			name:        "update of a function",
+			input:       "fn f() {}\nf++",
+			expectedErr: compilerErrors.ErrVariableIsConstant,
+		},
+		{
+			name:        "duplicate declaration",
+			input:       "var a = 1\nvar a = 2",
+			expectedErr: compilerErrors.ErrVariableAlreadyExists,
+		},
+		{
+			name:        "duplicate parameter",
+			input:       "fn f(a, a) {}",
+			expectedErr: compilerErrors.ErrVariableAlreadyExists,
+		},
+		{
+			name:        "declaration of an existing global",
+			input:       "var a = 2",
+			globals:     []resolver.Global{{Name: "a"}},
+			expectedErr: compilerErrors.ErrVariableAlreadyExists,
+		},
+		{
+			name:        "assignment to an existing constant global",
+			input:       "a = 2",
+			globals:     []resolver.Global{{Name: "a", Constant: true}},
+			expectedErr: compilerErrors.ErrVariableIsConstant,
+		},
+		{
+			name:        "variable of an inner block",
+			input:       "if true {\nvar a = 1\n}\nvar b = a",
+			expectedErr: compilerErrors.ErrVariableDoesNotExist,
+		},
+		{
+			name:  "functions declared later in the scope",
+			input: "fn isEven(n) { return n == 0 || isOdd(n - 1) }\nfn isOdd(n) { return n != 0 && isEven(n - 1) }",
+		},
+		{
+			name:  "shadowing in an inner block",
+			input: "var a = 1\nif true {\nvar a = 2\n}",
+		},
+		{
+			name:  "native functions",
+			input: "var a = len([1])\nprint(a)",
+		},
+		{
+			name:  "shorthand property",
+			input: "var a = 1\nvar obj = { a }",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := resolve(t, test.input, test.globals)
+
+			if test.expectedErr == nil && err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if test.expectedErr != nil && !errors.Is(err, test.expectedErr) {
+				t.Errorf("Expected error: %v, but got: %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestResolveReportsEveryError(t *testing.T) {
+	_, err := resolve(t, "var a = b\nconst c = 1\nc = 2\nvar a = 3", nil)
+
+	var list diagnostic.List
+
+	if !errors.As(err, &list) {
+		t.Fatalf("Expected a list of diagnostics, but got: %v", err)
+	}
+
+	if len(list) != 3 {
+		t.Fatalf("Expected 3 diagnostics, but got %d: %v", len(list), err)
+	}
+
+	for idx, line := range []int{1, 3, 4} {
+		if list[idx].Span.Start.Line != line {
+			t.Errorf("Expected diagnostic %d to be on line %d, but got: %d", idx, line, list[idx].Span.Start.Line)
+		}
+	}
+}
+
+type hostFunctions map[string]bool
+
+func (h hostFunctions) Has(name string) bool {
+	return h[name]
+}
+
+func TestResolveNativeCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"print(1)", "print"},
+		{"db.query(1)", "db.query"},
+		{"fn print(a) {}\nprint(1)", ""},
+		{"var db = {}\ndb.query(1)", ""},
+		{"fn f() {}\nf()", ""},
+	}
+
+	for _, test := range tests {
+		program, err := parser.New().ProduceAST(test.input)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolved, err := resolver.Resolve(*program, nil, hostFunctions{"db.query": true})
+
+		if err != nil {
+			t.Fatalf("Expected no error resolving %q, but got: %v", test.input, err)
+		}
+
+		call := resolved.Body[len(resolved.Body)-1].(ast.CallExpr)
+
+		if call.Native != test.expected {
+			t.Errorf("Expected %q to call the native function %q, but got: %q", test.input, test.expected, call.Native)
+		}
+	}
+}
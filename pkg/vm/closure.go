@@ -0,0 +1,85 @@
+package vm
+
+import (
+	"github.com/Waxer59/PikaLang/pkg/compiler"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+)
+
+// A compiled function together with the variables it captured
+type Closure struct {
+	Fn       *compiler.Function
+	upvalues []*upvalue
+}
+
+func (c *Closure) GetType() interpreter_env.ValueType {
+	if c.Fn.Arrow {
+		return interpreter_env.ArrowFunction
+	}
+	return interpreter_env.Function
+}
+
+func (c *Closure) GetValue() any {
+	return c
+}
+
+/*
+ * A variable captured by a closure. While the variable is still in the
+ * stack the upvalue is open and points to its slot, once the variable
+ * goes out of scope the value is moved into the upvalue.
+ */
+type upvalue struct {
+	slot   int
+	open   bool
+	closed interpreter_env.RuntimeValue
+	// Open upvalues form a list sorted by slot, from the top of the stack down
+	next *upvalue
+}
+
+func (vm *VM) captureUpvalue(slot int) *upvalue {
+	var prev *upvalue
+	current := vm.openUpvalues
+
+	for current != nil && current.slot > slot {
+		prev = current
+		current = current.next
+	}
+
+	if current != nil && current.slot == slot {
+		return current
+	}
+
+	created := &upvalue{slot: slot, open: true, next: current}
+
+	if prev == nil {
+		vm.openUpvalues = created
+	} else {
+		prev.next = created
+	}
+
+	return created
+}
+
+// Closes every open upvalue pointing to the given slot or above it
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.slot >= last {
+		current := vm.openUpvalues
+		current.closed = vm.stack[current.slot]
+		current.open = false
+		vm.openUpvalues = current.next
+	}
+}
+
+func (vm *VM) getUpvalue(current *upvalue) interpreter_env.RuntimeValue {
+	if current.open {
+		return vm.stack[current.slot]
+	}
+	return current.closed
+}
+
+func (vm *VM) setUpvalue(current *upvalue, value interpreter_env.RuntimeValue) {
+	if current.open {
+		vm.stack[current.slot] = value
+		return
+	}
+	current.closed = value
+}
@@ -0,0 +1,736 @@
+/*
+ * Package vm runs the bytecode produced by the pkg/compiler package. It is a
+ * stack based machine, every call pushes a frame whose locals live in the
+ * stack from the base of the frame.
+ */
+package vm
+
+import (
+	"context"
+	"strings"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pkg/compiler"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_makers"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_ops"
+)
+
+const initialStackSize = 1024
+
+var (
+	null  interpreter_env.RuntimeValue = interpreter_makers.MkNull()
+	nan   interpreter_env.RuntimeValue = interpreter_makers.MkNan()
+	True  interpreter_env.RuntimeValue = interpreter_makers.MkBoolean(true)
+	False interpreter_env.RuntimeValue = interpreter_makers.MkBoolean(false)
+)
+
+// Symbol of the operator applied by every binary opcode
+var binarySymbols = map[compiler.Opcode]string{}
+
+func init() {
+	for symbol, op := range compiler.BinaryOperators {
+		binarySymbols[op] = symbol
+	}
+}
+
+type frame struct {
+	closure *Closure
+	ip      int
+	// Position of the first local of the frame in the stack
+	base int
+}
+
+// Where the VM goes when the code of a try block fails
+type handler struct {
+	// Index of the frame running the try statement
+	frame int
+	sp    int
+	ip    int
+	// Finally blocks get the error itself, so they can throw it again
+	finally bool
+}
+
+// The error of a try block with a finally block, kept in a hidden local
+// while the finally block runs
+type pendingError struct {
+	err error
+}
+
+func (p *pendingError) GetType() interpreter_env.ValueType {
+	return interpreter_env.Null
+}
+
+func (p *pendingError) GetValue() any {
+	return p.err
+}
+
+type VM struct {
+	bytecode *compiler.Bytecode
+	stack    []interpreter_env.RuntimeValue
+	sp       int
+	frames   []frame
+	// A nil global hasn't been declared yet
+	globals         []interpreter_env.RuntimeValue
+	constantGlobals []bool
+	natives         []nativeFns.NativeFunction
+	openUpvalues    *upvalue
+	handlers        []handler
+	// Given to the natives, so they can call back into the program
+	runtime *nativeFns.Runtime
+	ctx     context.Context
+	limits  interpreter_env.Limits
+	// Limits of the current run, made again by every call to Run
+	guard *interpreter_env.Guard
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	natives := make([]nativeFns.NativeFunction, len(bytecode.Natives))
+
+	for idx, name := range bytecode.Natives {
+		natives[idx] = nativeFns.NativeFunctions[name]
+	}
+
+	vm := &VM{
+		bytecode:        bytecode,
+		stack:           make([]interpreter_env.RuntimeValue, initialStackSize),
+		globals:         make([]interpreter_env.RuntimeValue, len(bytecode.Globals)),
+		constantGlobals: make([]bool, len(bytecode.Globals)),
+		natives:         natives,
+		ctx:             context.Background(),
+	}
+
+	vm.guard = interpreter_env.NewGuard(vm.ctx, vm.limits)
+
+	vm.runtime = &nativeFns.Runtime{
+		Call:         vm.callValue,
+		Capabilities: interpreter_env.DefaultCapabilities(),
+		Stdio:        interpreter_env.DefaultStdio(),
+	}
+
+	return vm
+}
+
+// Sets the groups of native functions the program can call
+func (vm *VM) SetCapabilities(capabilities *interpreter_env.Capabilities) {
+	vm.runtime.Capabilities = capabilities
+}
+
+// Sets the context and the limits of the next runs of the program, they are
+// enforced like in the tree-walking interpreter
+func (vm *VM) SetLimits(ctx context.Context, limits interpreter_env.Limits) {
+	vm.ctx = ctx
+	vm.limits = limits
+}
+
+// Sets the streams the console functions of the program use
+func (vm *VM) SetStdio(stdio *interpreter_env.Stdio) {
+	vm.runtime.Stdio = stdio
+}
+
+// Returns the value of a global variable, the second return value is false if it isn't declared
+func (vm *VM) Global(name string) (interpreter_env.RuntimeValue, bool) {
+	for slot, globalName := range vm.bytecode.Globals {
+		if globalName == name && vm.globals[slot] != nil {
+			return vm.globals[slot], true
+		}
+	}
+
+	return nil, false
+}
+
+func (vm *VM) Run() error {
+	vm.sp = 0
+	vm.openUpvalues = nil
+	vm.handlers = vm.handlers[:0]
+	vm.frames = append(vm.frames[:0], frame{closure: &Closure{Fn: vm.bytecode.Main}})
+	vm.guard = interpreter_env.NewGuard(vm.ctx, vm.limits)
+
+	if err := vm.guard.Check(); err != nil {
+		return err
+	}
+
+	return vm.run(0)
+}
+
+/*
+ * Calls a closure from a native function and runs it until it returns. The
+ * frames and the stack of the caller are left as they were, also when the
+ * call fails.
+ */
+func (vm *VM) callValue(fn interpreter_env.RuntimeValue, args []interpreter_env.RuntimeValue) (interpreter_env.RuntimeValue, error) {
+	closure, ok := fn.(*Closure)
+
+	if !ok {
+		return nil, compilerErrors.ErrNotAFunction.WithArgs(fn.GetType())
+	}
+
+	fnName := closure.Fn.Name
+	if fnName == "" {
+		fnName = "anonymous function"
+	}
+
+	if err := vm.checkCall(closure, len(args), fnName); err != nil {
+		return nil, err
+	}
+
+	depth := len(vm.frames)
+	base := vm.sp
+
+	for _, arg := range args {
+		vm.push(arg)
+	}
+
+	vm.frames = append(vm.frames, frame{closure: closure, base: base})
+
+	if err := vm.run(depth); err != nil {
+		vm.closeUpvalues(base)
+		vm.frames = vm.frames[:depth]
+		vm.sp = base
+		return nil, err
+	}
+
+	return vm.pop(), nil
+}
+
+// Checks a call to closure before its frame is pushed, the first frame is the program
+func (vm *VM) checkCall(closure *Closure, argc int, fnName string) error {
+	if err := vm.guard.CheckCallDepth(len(vm.frames) - 1); err != nil {
+		return err
+	}
+
+	if closure.Fn.Arity > argc {
+		return compilerErrors.ErrNotEnoughArguments.WithArgs(fnName)
+	} else if closure.Fn.Arity < argc {
+		return compilerErrors.ErrTooManyArguments.WithArgs(fnName)
+	}
+
+	return nil
+}
+
+func (vm *VM) push(value interpreter_env.RuntimeValue) {
+	if vm.sp == len(vm.stack) {
+		vm.stack = append(vm.stack, make([]interpreter_env.RuntimeValue, len(vm.stack))...)
+	}
+
+	vm.stack[vm.sp] = value
+	vm.sp++
+}
+
+func (vm *VM) pop() interpreter_env.RuntimeValue {
+	vm.sp--
+	return vm.stack[vm.sp]
+}
+
+func (vm *VM) peek() interpreter_env.RuntimeValue {
+	return vm.stack[vm.sp-1]
+}
+
+// Runs until the frames below exitDepth return, the result of the last one is left on the stack
+func (vm *VM) run(exitDepth int) error {
+	f := &vm.frames[len(vm.frames)-1]
+	chunk := &f.closure.Fn.Chunk
+
+	readUint16 := func() int {
+		operand := int(chunk.Code[f.ip])<<8 | int(chunk.Code[f.ip+1])
+		f.ip += 2
+		return operand
+	}
+
+	readByte := func() int {
+		operand := int(chunk.Code[f.ip])
+		f.ip++
+		return operand
+	}
+
+	readName := func() string {
+		return chunk.Constants[readUint16()].(interpreter_env.StringVal).Value
+	}
+
+	for {
+		var err error
+		offset := f.ip
+		op := compiler.Opcode(chunk.Code[f.ip])
+		f.ip++
+
+		// The errors of the limits can't be caught
+		if err = vm.guard.Step(); err != nil {
+			return diagnostic.WithStack(diagnostic.WithSpan(err, chunk.SpanAt(offset)), vm.callStack)
+		}
+
+		switch op {
+		case compiler.OpConstant:
+			vm.push(chunk.Constants[readUint16()])
+		case compiler.OpNull:
+			vm.push(null)
+		case compiler.OpTrue:
+			vm.push(True)
+		case compiler.OpFalse:
+			vm.push(False)
+		case compiler.OpNaN:
+			vm.push(nan)
+		case compiler.OpPop:
+			vm.sp--
+		case compiler.OpPopN:
+			vm.sp -= readUint16()
+		case compiler.OpReserve:
+			for count := readUint16(); count > 0; count-- {
+				vm.push(nil)
+			}
+		case compiler.OpDup:
+			vm.push(vm.peek())
+		case compiler.OpSwap:
+			vm.stack[vm.sp-1], vm.stack[vm.sp-2] = vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+
+		// VARIABLES
+		case compiler.OpGetLocal:
+			vm.push(vm.stack[f.base+readUint16()])
+		case compiler.OpSetLocal:
+			vm.stack[f.base+readUint16()] = vm.peek()
+		case compiler.OpDefineLocal:
+			vm.stack[f.base+readUint16()] = vm.pop()
+		case compiler.OpGetUpvalue, compiler.OpSetUpvalue:
+			idx := readUint16()
+			current := f.closure.upvalues[idx]
+
+			// The slots of the variables are reserved before they are declared
+			if vm.getUpvalue(current) == nil {
+				err = compilerErrors.ErrVariableDoesNotExist.WithArgs(f.closure.Fn.UpvalueNames[idx])
+				break
+			}
+
+			if op == compiler.OpGetUpvalue {
+				vm.push(vm.getUpvalue(current))
+			} else {
+				vm.setUpvalue(current, vm.peek())
+			}
+		case compiler.OpGetGlobal:
+			slot := readUint16()
+			value := vm.globals[slot]
+
+			if value == nil {
+				err = compilerErrors.ErrVariableDoesNotExist.WithArgs(vm.bytecode.Globals[slot])
+				break
+			}
+
+			vm.push(value)
+		case compiler.OpSetGlobal:
+			slot := readUint16()
+
+			if vm.globals[slot] == nil {
+				err = compilerErrors.ErrVariableDoesNotExist.WithArgs(vm.bytecode.Globals[slot])
+				break
+			}
+
+			if vm.constantGlobals[slot] {
+				err = compilerErrors.ErrVariableIsConstant.WithArgs(vm.bytecode.Globals[slot])
+				break
+			}
+
+			vm.globals[slot] = vm.peek()
+		case compiler.OpDefineGlobal, compiler.OpDefineConstGlobal:
+			slot := readUint16()
+
+			if vm.globals[slot] != nil {
+				err = compilerErrors.ErrVariableAlreadyExists.WithArgs(vm.bytecode.Globals[slot])
+				break
+			}
+
+			vm.globals[slot] = vm.pop()
+			vm.constantGlobals[slot] = op == compiler.OpDefineConstGlobal
+		case compiler.OpCloseUpvalue:
+			vm.closeUpvalues(vm.sp - 1)
+			vm.sp--
+		case compiler.OpCloseUpvalues:
+			vm.closeUpvalues(f.base + readUint16())
+		case compiler.OpAssignConstant:
+			err = compilerErrors.ErrVariableIsConstant.WithArgs(readName())
+
+		// ARRAYS & OBJECTS
+		case compiler.OpArray:
+			length := readUint16()
+			elements := make([]interpreter_env.RuntimeValue, length)
+			copy(elements, vm.stack[vm.sp-length:vm.sp])
+			vm.sp -= length
+			err = vm.pushAllocated(interpreter_makers.MkArray(elements))
+		case compiler.OpObject:
+			length := readUint16()
+			properties := make(map[string]interpreter_env.RuntimeValue, length)
+
+			for idx := vm.sp - length*2; idx < vm.sp; idx += 2 {
+				properties[vm.stack[idx].(interpreter_env.StringVal).Value] = vm.stack[idx+1]
+			}
+
+			vm.sp -= length * 2
+			err = vm.pushAllocated(interpreter_makers.MkObject(properties))
+		case compiler.OpTemplate:
+			length := readUint16()
+			var text strings.Builder
+
+			for _, part := range vm.stack[vm.sp-length : vm.sp] {
+				text.WriteString(nativeFns.ToString(part))
+			}
+
+			vm.sp -= length
+			err = vm.pushAllocated(interpreter_makers.MkString(text.String()))
+		case compiler.OpGetProperty:
+			name := readName()
+			obj := vm.pop()
+
+			if object, ok := obj.(*interpreter_env.ObjectVal); ok {
+				value, ok := object.Properties[name]
+				if !ok {
+					value = null
+				}
+				vm.push(value)
+				break
+			}
+
+			var value interpreter_env.RuntimeValue
+			value, err = interpreter_ops.GetProperty(obj, name)
+			vm.push(value)
+		case compiler.OpGetIndex:
+			key := vm.pop()
+			obj := vm.pop()
+
+			if array, ok := obj.(*interpreter_env.ArrayVal); ok {
+				if number, ok := key.(interpreter_env.NumberVal); ok {
+					if idx := int(number.Value); idx >= 0 && idx < len(array.Elements) {
+						vm.push(array.Elements[idx])
+						break
+					}
+				}
+			}
+
+			var value interpreter_env.RuntimeValue
+			value, err = interpreter_ops.GetIndex(obj, key)
+			vm.push(value)
+		case compiler.OpSetProperty:
+			key := chunk.Constants[readUint16()]
+			operator := compiler.AssignmentOperators[readByte()]
+			value := vm.pop()
+			obj := vm.pop()
+
+			err = vm.setMember(obj, key, value, operator)
+		case compiler.OpSetIndex:
+			operator := compiler.AssignmentOperators[readByte()]
+			value := vm.pop()
+			key := vm.pop()
+			obj := vm.pop()
+
+			err = vm.setMember(obj, key, value, operator)
+
+		// OPERATORS
+		case compiler.OpAdd, compiler.OpSubtract, compiler.OpMultiply, compiler.OpDivide, compiler.OpModulo, compiler.OpPower,
+			compiler.OpBitwiseAnd, compiler.OpBitwiseOr, compiler.OpBitwiseXor, compiler.OpShiftLeft, compiler.OpShiftRight, compiler.OpUnsignedShiftRight:
+			rhs := vm.pop()
+			lhs := vm.pop()
+			numLhs, okLhs := lhs.(interpreter_env.NumberVal)
+			numRhs, okRhs := rhs.(interpreter_env.NumberVal)
+
+			if okLhs && okRhs {
+				var result float64
+				result, err = arithmetic(op, numLhs.Value, numRhs.Value)
+				vm.push(interpreter_makers.MkNumber(result))
+				break
+			}
+
+			var result interpreter_env.RuntimeValue
+			if result, err = interpreter_ops.Binary(binarySymbols[op], lhs, rhs); err == nil {
+				err = vm.pushAllocated(result)
+			}
+		case compiler.OpLess, compiler.OpLessEqual, compiler.OpGreater, compiler.OpGreaterEqual, compiler.OpEqual, compiler.OpNotEqual:
+			rhs := vm.pop()
+			lhs := vm.pop()
+			numLhs, okLhs := lhs.(interpreter_env.NumberVal)
+			numRhs, okRhs := rhs.(interpreter_env.NumberVal)
+
+			if okLhs && okRhs {
+				vm.push(compare(op, numLhs.Value, numRhs.Value))
+				break
+			}
+
+			var result interpreter_env.RuntimeValue
+			result, err = interpreter_ops.Binary(binarySymbols[op], lhs, rhs)
+			vm.push(result)
+		case compiler.OpAnd:
+			rhs := vm.pop()
+			vm.push(interpreter_ops.Logical("&&", vm.pop(), rhs))
+		case compiler.OpOr:
+			rhs := vm.pop()
+			vm.push(interpreter_ops.Logical("||", vm.pop(), rhs))
+		case compiler.OpNot:
+			vm.push(boolean(!nativeFns.EvaluateTruthyFalsyValues(vm.pop())))
+		case compiler.OpNegate, compiler.OpUnaryPlus, compiler.OpBitwiseNot:
+			operator := "-"
+			switch op {
+			case compiler.OpUnaryPlus:
+				operator = "+"
+			case compiler.OpBitwiseNot:
+				operator = "~"
+			}
+
+			var value interpreter_env.RuntimeValue
+			value, err = interpreter_ops.Unary(operator, vm.pop())
+			vm.push(value)
+		case compiler.OpIncrement, compiler.OpDecrement:
+			operator := "++"
+			if op == compiler.OpDecrement {
+				operator = "--"
+			}
+
+			var value interpreter_env.RuntimeValue
+			value, err = interpreter_ops.Update(operator, vm.pop())
+			vm.push(value)
+		case compiler.OpCaseMatch:
+			test := vm.pop()
+			discriminant := vm.pop()
+			vm.push(boolean(interpreter_ops.CaseMatches(test, discriminant)))
+
+		// CONTROL FLOW
+		case compiler.OpJump:
+			jump := readUint16()
+			f.ip += jump
+		case compiler.OpJumpIfFalse:
+			jump := readUint16()
+			condition := vm.pop()
+
+			if condition == False || !nativeFns.EvaluateTruthyFalsyValues(condition) {
+				f.ip += jump
+			}
+		case compiler.OpLoop:
+			jump := readUint16()
+			f.ip -= jump
+		case compiler.OpCall:
+			argc := readByte()
+			fnName := readName()
+			closure, ok := vm.pop().(*Closure)
+
+			if !ok {
+				err = compilerErrors.ErrNotAFunction.WithArgs(fnName)
+				break
+			}
+
+			if err = vm.checkCall(closure, argc, fnName); err != nil {
+				break
+			}
+
+			vm.frames = append(vm.frames, frame{closure: closure, base: vm.sp - argc})
+			f = &vm.frames[len(vm.frames)-1]
+			chunk = &f.closure.Fn.Chunk
+		case compiler.OpCallNative:
+			nativeIdx := readUint16()
+			argc := readByte()
+
+			if err = nativeFns.CheckCapability(vm.bytecode.Natives[nativeIdx], vm.runtime.Capabilities); err != nil {
+				break
+			}
+
+			native := vm.natives[nativeIdx]
+			args := make([]interpreter_env.RuntimeValue, argc)
+			copy(args, vm.stack[vm.sp-argc:vm.sp])
+			vm.sp -= argc
+			grown := interpreter_ops.ArrayLengths(args)
+
+			var result interpreter_env.RuntimeValue
+			result, err = native(args, vm.runtime)
+			if err != nil {
+				break
+			}
+
+			if result == nil {
+				result = null
+			} else if err = interpreter_ops.ChargeNative(args, grown, result, vm.guard); err != nil {
+				break
+			}
+
+			vm.push(result)
+
+			// Callbacks run by the native can grow the frames
+			f = &vm.frames[len(vm.frames)-1]
+			chunk = &f.closure.Fn.Chunk
+		case compiler.OpClosure:
+			fn := chunk.Functions[readUint16()]
+			closure := &Closure{Fn: fn, upvalues: make([]*upvalue, readUint16())}
+
+			for idx := range closure.upvalues {
+				isLocal := readByte() == 1
+				index := readUint16()
+
+				if isLocal {
+					closure.upvalues[idx] = vm.captureUpvalue(f.base + index)
+				} else {
+					closure.upvalues[idx] = f.closure.upvalues[index]
+				}
+			}
+
+			vm.push(closure)
+		case compiler.OpReturn:
+			result := vm.pop()
+			vm.closeUpvalues(f.base)
+			vm.sp = f.base
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			vm.push(result)
+
+			if len(vm.frames) == exitDepth {
+				return nil
+			}
+
+			f = &vm.frames[len(vm.frames)-1]
+			chunk = &f.closure.Fn.Chunk
+
+		// EXCEPTIONS
+		case compiler.OpTry, compiler.OpTryFinally:
+			jump := readUint16()
+			vm.handlers = append(vm.handlers, handler{
+				frame:   len(vm.frames) - 1,
+				sp:      vm.sp,
+				ip:      f.ip + jump,
+				finally: op == compiler.OpTryFinally,
+			})
+		case compiler.OpPopTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case compiler.OpThrow:
+			value := vm.pop()
+
+			if pending, ok := value.(*pendingError); ok {
+				err = pending.err
+				break
+			}
+
+			err = interpreter_env.Throw(value)
+		}
+
+		if err != nil {
+			err = diagnostic.WithSpan(err, chunk.SpanAt(offset))
+			err = diagnostic.WithStack(err, vm.callStack)
+
+			if !vm.catch(err, exitDepth) {
+				return err
+			}
+
+			f = &vm.frames[len(vm.frames)-1]
+			chunk = &f.closure.Fn.Chunk
+		}
+	}
+}
+
+// Counts the memory of a value created by the program before pushing it
+func (vm *VM) pushAllocated(value interpreter_env.RuntimeValue) error {
+	if err := vm.guard.Allocate(interpreter_env.SizeOf(value)); err != nil {
+		return err
+	}
+
+	vm.push(value)
+	return nil
+}
+
+// Assigns a property or an element and pushes the assigned value, the memory
+// the object grows by and the result of compound assignments are counted
+func (vm *VM) setMember(obj interpreter_env.RuntimeValue, key interpreter_env.RuntimeValue, value interpreter_env.RuntimeValue, operator string) error {
+	result, err := interpreter_ops.SetMember(obj, key, value, operator, vm.guard)
+
+	if err != nil {
+		return err
+	}
+
+	if _, isCompound := interpreter_ops.CompoundOperator(operator); isCompound {
+		return vm.pushAllocated(result)
+	}
+
+	vm.push(result)
+	return nil
+}
+
+/*
+ * Returns the calls of the frames, the innermost goes first and the program
+ * isn't one of them. The frame below a call is stopped right after the call
+ * instruction that made it, or the native function that called it back.
+ */
+func (vm *VM) callStack() []diagnostic.Frame {
+	stack := make([]diagnostic.Frame, 0, len(vm.frames)-1)
+
+	for idx := len(vm.frames) - 1; idx > 0; idx-- {
+		name := vm.frames[idx].closure.Fn.Name
+		if name == "" {
+			name = diagnostic.AnonymousFunction
+		}
+
+		caller := vm.frames[idx-1]
+		stack = append(stack, diagnostic.Frame{Function: name, CallSite: caller.closure.Fn.Chunk.SpanAt(caller.ip - 1)})
+	}
+
+	return stack
+}
+
+/*
+ * Unwinds the frames and the stack to the innermost try block and jumps to
+ * its catch or finally block. It returns false if the error can't be caught
+ * or if there is no try block in the frames run by the current call to run.
+ */
+func (vm *VM) catch(err error, exitDepth int) bool {
+	if len(vm.handlers) == 0 || !interpreter_env.Catchable(err) {
+		return false
+	}
+
+	h := vm.handlers[len(vm.handlers)-1]
+
+	if h.frame < exitDepth {
+		return false
+	}
+
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	vm.closeUpvalues(h.sp)
+	vm.frames = vm.frames[:h.frame+1]
+	vm.frames[h.frame].ip = h.ip
+	vm.sp = h.sp
+
+	if h.finally {
+		vm.push(&pendingError{err: err})
+	} else {
+		vm.push(interpreter_env.CaughtValue(err))
+	}
+
+	return true
+}
+
+func arithmetic(op compiler.Opcode, lhs float64, rhs float64) (float64, error) {
+	switch op {
+	case compiler.OpAdd:
+		return lhs + rhs, nil
+	case compiler.OpSubtract:
+		return lhs - rhs, nil
+	case compiler.OpMultiply:
+		return lhs * rhs, nil
+	case compiler.OpDivide:
+		return lhs / rhs, nil
+	}
+
+	return interpreter_ops.Arithmetic(binarySymbols[op], lhs, rhs)
+}
+
+func compare(op compiler.Opcode, lhs float64, rhs float64) interpreter_env.RuntimeValue {
+	switch op {
+	case compiler.OpLess:
+		return boolean(lhs < rhs)
+	case compiler.OpLessEqual:
+		return boolean(lhs <= rhs)
+	case compiler.OpGreater:
+		return boolean(lhs > rhs)
+	case compiler.OpGreaterEqual:
+		return boolean(lhs >= rhs)
+	case compiler.OpEqual:
+		return boolean(lhs == rhs)
+	default:
+		return boolean(lhs != rhs)
+	}
+}
+
+func boolean(value bool) interpreter_env.RuntimeValue {
+	if value {
+		return True
+	}
+	return False
+}
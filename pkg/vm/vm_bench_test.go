@@ -0,0 +1,68 @@
+package vm_test
+
+import (
+	"testing"
+
+	"github.com/Waxer59/PikaLang/pkg/compiler"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
+	"github.com/Waxer59/PikaLang/pkg/vm"
+)
+
+const loopSrc = `
+var result = 0
+for var i = 0; i < 10000; i++ {
+  result += i % 7
+}`
+
+const callSrc = `
+fn add(a, b) {
+  return a + b
+}
+
+var result = 0
+for var i = 0; i < 2000; i++ {
+  result = add(result, i)
+}`
+
+const propertySrc = `
+const point = { x: 1, y: 2 }
+var result = 0
+for var i = 0; i < 5000; i++ {
+  result = result + point.x + point.y
+  point.x = point.y
+}`
+
+func benchmarkTreeWalker(b *testing.B, src string) {
+	program := parse(b, src)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := interpreter_eval.Evaluate(*program, interpreter_env.New(nil)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkVM(b *testing.B, src string) {
+	bytecode, err := compiler.Compile(*parse(b, src))
+
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := vm.New(bytecode).Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoopTreeWalker(b *testing.B)     { benchmarkTreeWalker(b, loopSrc) }
+func BenchmarkLoopVM(b *testing.B)             { benchmarkVM(b, loopSrc) }
+func BenchmarkCallTreeWalker(b *testing.B)     { benchmarkTreeWalker(b, callSrc) }
+func BenchmarkCallVM(b *testing.B)             { benchmarkVM(b, callSrc) }
+func BenchmarkPropertyTreeWalker(b *testing.B) { benchmarkTreeWalker(b, propertySrc) }
+func BenchmarkPropertyVM(b *testing.B)         { benchmarkVM(b, propertySrc) }
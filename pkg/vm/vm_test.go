@@ -0,0 +1,248 @@
+package vm_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	compilerErrors "github.com/Waxer59/PikaLang/internal/errors"
+	"github.com/Waxer59/PikaLang/internal/nativeFns"
+	"github.com/Waxer59/PikaLang/pkg/ast"
+	"github.com/Waxer59/PikaLang/pkg/compiler"
+	"github.com/Waxer59/PikaLang/pkg/diagnostic"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_env"
+	"github.com/Waxer59/PikaLang/pkg/interpreter/interpreter_eval"
+	"github.com/Waxer59/PikaLang/pkg/parser"
+	"github.com/Waxer59/PikaLang/pkg/vm"
+)
+
+func parse(t testing.TB, src string) *ast.Program {
+	program, err := parser.New().ProduceAST(src)
+
+	if err != nil {
+		t.Fatalf("Expected no syntax errors, but got: %v", err)
+	}
+
+	return program
+}
+
+func runTreeWalker(program *ast.Program) (interpreter_env.RuntimeValue, error) {
+	env := interpreter_env.New(nil)
+
+	if _, err := interpreter_eval.Evaluate(*program, env); err != nil {
+		return nil, err
+	}
+
+	return env.LookupVar("result")
+}
+
+func runVM(program *ast.Program) (interpreter_env.RuntimeValue, error) {
+	bytecode, err := compiler.Compile(*program)
+
+	if err != nil {
+		return nil, err
+	}
+
+	machine := vm.New(bytecode)
+
+	if err := machine.Run(); err != nil {
+		return nil, err
+	}
+
+	result, _ := machine.Global("result")
+	return result, nil
+}
+
+// Returns the value as print shows it, arrays and objects can't be compared with ==
+func printed(value interpreter_env.RuntimeValue) string {
+	var out strings.Builder
+	stdio := &interpreter_env.Stdio{In: strings.NewReader(""), Out: &out, Err: io.Discard}
+
+	nativeFns.NativeFunctions["print"]([]interpreter_env.RuntimeValue{value}, &nativeFns.Runtime{Stdio: stdio})
+
+	return out.String()
+}
+
+// Runs every program of the corpus on both the tree-walker and the VM and
+// checks that they agree on the value of 'result' or on the error raised
+func TestCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.pk"))
+
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected the corpus to have programs, but got: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			src, err := os.ReadFile(file)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			program := parse(t, string(src))
+			expected, expectedErr := runTreeWalker(program)
+			got, err := runVM(program)
+
+			if expectedErr != nil {
+				var expectedDiag, diag *diagnostic.Diagnostic
+
+				if !errors.As(expectedErr, &expectedDiag) || !errors.As(err, &diag) {
+					t.Fatalf("Expected error: %v, but got: %v", expectedErr, err)
+				}
+
+				if diag.Code != expectedDiag.Code || diag.Message != expectedDiag.Message || diag.StackTrace() != expectedDiag.StackTrace() {
+					t.Errorf("Expected error: %v, but got: %v", expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, but got: %v", err)
+			}
+
+			if got == nil {
+				t.Fatal("Expected a result variable")
+			}
+
+			if printed(got) != printed(expected) {
+				t.Errorf("Expected result to be %s, but got: %s", printed(expected), printed(got))
+			}
+		})
+	}
+}
+
+// VMs only read their bytecode, so a program compiled once can run in many
+// goroutines at the same time. Run with -race.
+func TestSharedBytecode(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.pk"))
+
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Expected the corpus to have programs, but got: %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bytecode, err := compiler.Compile(*parse(t, string(src)))
+
+		// Programs of the corpus that fail to compile have nothing to share
+		if err != nil {
+			continue
+		}
+
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+
+			go func(file string) {
+				defer wg.Done()
+
+				machine := vm.New(bytecode)
+				machine.SetStdio(&interpreter_env.Stdio{In: strings.NewReader(""), Out: io.Discard, Err: io.Discard})
+
+				if err := machine.Run(); err != nil {
+					return
+				}
+
+				if _, ok := machine.Global("result"); !ok {
+					t.Errorf("Expected %s to set result", file)
+				}
+			}(file)
+		}
+	}
+
+	wg.Wait()
+}
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		limits        interpreter_env.Limits
+		expectedError error
+	}{
+		{
+			name:          "steps",
+			input:         "while true {}",
+			limits:        interpreter_env.Limits{MaxSteps: 1000},
+			expectedError: compilerErrors.ErrStepLimitExceeded,
+		},
+		{
+			name:          "call depth",
+			input:         "fn f(n) { return f(n + 1) }\nf(0)",
+			limits:        interpreter_env.Limits{MaxCallDepth: 50},
+			expectedError: compilerErrors.ErrStackOverflow,
+		},
+		{
+			name:          "memory of arrays padded by an assignment",
+			input:         "var a = []\na[1e9] = 1",
+			limits:        interpreter_env.Limits{MaxMemory: 1 << 20},
+			expectedError: compilerErrors.ErrMemoryLimit,
+		},
+		{
+			name:          "memory of strings",
+			input:         "var s = \"ab\"\nwhile true { s += s }",
+			limits:        interpreter_env.Limits{MaxMemory: 1 << 20},
+			expectedError: compilerErrors.ErrMemoryLimit,
+		},
+		{
+			name:          "memory of native results",
+			input:         "var a = [1]\nwhile true { push(a, 1) }",
+			limits:        interpreter_env.Limits{MaxMemory: 1 << 20},
+			expectedError: compilerErrors.ErrMemoryLimit,
+		},
+		{
+			name:          "limits can't be caught",
+			input:         "try { while true {} } catch (e) {}",
+			limits:        interpreter_env.Limits{Timeout: 10 * time.Millisecond},
+			expectedError: compilerErrors.ErrTimeout,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bytecode, err := compiler.Compile(*parse(t, test.input))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			machine := vm.New(bytecode)
+			machine.SetLimits(context.Background(), test.limits)
+
+			if err := machine.Run(); !errors.Is(err, test.expectedError) {
+				t.Errorf("Expected error: %v, but got: %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	bytecode, err := compiler.Compile(*parse(t, "while true {}"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	machine := vm.New(bytecode)
+	machine.SetLimits(ctx, interpreter_env.Limits{})
+
+	if err := machine.Run(); !errors.Is(err, compilerErrors.ErrCanceled) {
+		t.Errorf("Expected error: %v, but got: %v", compilerErrors.ErrCanceled, err)
+	}
+}